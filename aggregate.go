@@ -0,0 +1,517 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Where an aggregate plan takes its items from
+const (
+	aggregateLoaded = "loaded" // The items loaded in the active tab
+	aggregateRead   = "read"   // Every page of the plan's read statement
+)
+
+// aggregateTimeout bounds a paginated read; unlike a single page it may walk
+// the whole table.
+const aggregateTimeout = 5 * time.Minute
+
+// AggregateBlock is the aggregation step of a plan with operation
+// "aggregate". It reduces the items to one row per group.
+type AggregateBlock struct {
+	Source  string            `json:"source"`
+	GroupBy []string          `json:"group_by"`
+	Metrics []AggregateMetric `json:"metrics"`
+}
+
+// AggregateMetric is one summary column. Paths are dotted ("address.city"),
+// "[n]" picks a list element and "[]" takes every element of a list.
+type AggregateMetric struct {
+	Func string `json:"func"` // count, sum, avg, min or max
+	Path string `json:"path"` // Empty or "*" with count counts items
+}
+
+func (a AggregateMetric) label() string {
+	path := a.Path
+	if path == "" {
+		path = "*"
+	}
+	return fmt.Sprintf("%s(%s)", strings.ToLower(a.Func), path)
+}
+
+// validate rejects blocks the aggregator can't run before anything is read.
+func (b *AggregateBlock) validate() error {
+	if b.Source != aggregateLoaded && b.Source != aggregateRead {
+		return fmt.Errorf("unknown aggregate source %q (want %q or %q)", b.Source, aggregateLoaded, aggregateRead)
+	}
+	if len(b.Metrics) == 0 {
+		return fmt.Errorf("the aggregate has no metrics")
+	}
+	for _, a := range b.Metrics {
+		switch strings.ToLower(a.Func) {
+		case "count":
+		case "sum", "avg", "min", "max":
+			if a.Path == "" || a.Path == "*" {
+				return fmt.Errorf("%s needs an attribute path", a.label())
+			}
+		default:
+			return fmt.Errorf("unsupported aggregate function %q", a.Func)
+		}
+	}
+	for _, p := range b.GroupBy {
+		if strings.TrimSpace(p) == "" {
+			return fmt.Errorf("empty group-by path")
+		}
+	}
+	return nil
+}
+
+// describe is the aggregation step as shown in the plan confirmation.
+func (b *AggregateBlock) describe() string {
+	labels := make([]string, len(b.Metrics))
+	for i, a := range b.Metrics {
+		labels[i] = a.label()
+	}
+	s := strings.Join(labels, ", ")
+	if len(b.GroupBy) > 0 {
+		s += " GROUP BY " + strings.Join(b.GroupBy, ", ")
+	}
+	return s
+}
+
+// pathValues resolves an attribute path in an item. A path can match several
+// values when it goes through "[]"; NULLs count as missing.
+func pathValues(item Item, path string) []interface{} {
+	values := pathLookup(item, path)
+	out := values[:0]
+	for _, v := range values {
+		if v != nil {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// pathLookup is pathValues keeping NULLs, so a NULL attribute can be told
+// apart from a missing one.
+func pathLookup(item Item, path string) []interface{} {
+	values := []interface{}{map[string]interface{}(item)}
+	for _, part := range strings.Split(path, ".") {
+		name, rest, _ := strings.Cut(part, "[")
+		if name != "" {
+			values = fanOut(values, func(v interface{}) []interface{} {
+				if m, ok := v.(map[string]interface{}); ok {
+					if child, ok := m[name]; ok {
+						return []interface{}{child}
+					}
+				}
+				return nil
+			})
+		}
+		for rest != "" {
+			idx, after, ok := strings.Cut(rest, "]")
+			if !ok {
+				return nil
+			}
+			rest = strings.TrimPrefix(after, "[")
+			values = fanOut(values, func(v interface{}) []interface{} {
+				list, ok := v.([]interface{})
+				if !ok {
+					return nil
+				}
+				if idx == "" {
+					return list
+				}
+				n, err := strconv.Atoi(idx)
+				if err != nil || n < 0 || n >= len(list) {
+					return nil
+				}
+				return []interface{}{list[n]}
+			})
+		}
+	}
+	return values
+}
+
+func fanOut(values []interface{}, step func(interface{}) []interface{}) []interface{} {
+	var out []interface{}
+	for _, v := range values {
+		out = append(out, step(v)...)
+	}
+	return out
+}
+
+// metricState accumulates one metric of one group.
+type metricState struct {
+	count    int
+	sum      float64
+	numbers  int
+	min, max interface{}
+}
+
+type aggregateGroup struct {
+	key     []interface{} // One value per group-by path; nil when missing
+	metrics []metricState
+}
+
+// aggregator folds items into groups one page at a time, so a paginated read
+// never has to hold the whole result.
+type aggregator struct {
+	spec    AggregateBlock
+	groups  map[string]*aggregateGroup
+	items   int
+	skipped int // Non-numeric values sum and avg had to ignore
+}
+
+func newAggregator(spec AggregateBlock) *aggregator {
+	return &aggregator{spec: spec, groups: make(map[string]*aggregateGroup)}
+}
+
+func (g *aggregator) add(item Item) {
+	g.items++
+	key := make([]interface{}, len(g.spec.GroupBy))
+	var id strings.Builder
+	for i, path := range g.spec.GroupBy {
+		switch values := pathValues(item, path); len(values) {
+		case 0:
+		case 1:
+			key[i] = values[0]
+		default:
+			key[i] = values
+		}
+		fmt.Fprintf(&id, "%T:%s\x00", key[i], profileValue(key[i]))
+	}
+	group, ok := g.groups[id.String()]
+	if !ok {
+		group = &aggregateGroup{key: key, metrics: make([]metricState, len(g.spec.Metrics))}
+		g.groups[id.String()] = group
+	}
+
+	for i, a := range g.spec.Metrics {
+		st := &group.metrics[i]
+		if a.Path == "" || a.Path == "*" {
+			st.count++
+			continue
+		}
+		for _, v := range pathValues(item, a.Path) {
+			st.count++
+			if sortRank(v) == rankNumber {
+				st.sum += toFloat(v)
+				st.numbers++
+			} else if fn := strings.ToLower(a.Func); fn == "sum" || fn == "avg" {
+				g.skipped++
+			}
+			if st.min == nil || compareValues(v, st.min) < 0 {
+				st.min = v
+			}
+			if st.max == nil || compareValues(v, st.max) > 0 {
+				st.max = v
+			}
+		}
+	}
+}
+
+// AggregateResult is the summary table an aggregate plan produces.
+type AggregateResult struct {
+	Source  string // What was aggregated, for the view header
+	Columns []string
+	Rows    [][]string
+	Items   int
+	Skipped int
+}
+
+// result renders the groups ordered by their group-by values. Without a
+// group-by there is always exactly one row, even over no items.
+func (g *aggregator) result(source string) *AggregateResult {
+	if len(g.spec.GroupBy) == 0 && len(g.groups) == 0 {
+		g.groups[""] = &aggregateGroup{metrics: make([]metricState, len(g.spec.Metrics))}
+	}
+	groups := make([]*aggregateGroup, 0, len(g.groups))
+	for _, group := range g.groups {
+		groups = append(groups, group)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		for k := range groups[i].key {
+			a, b := groups[i].key[k], groups[j].key[k]
+			switch {
+			case a == nil && b == nil:
+				continue
+			case a == nil:
+				return false // Missing sorts last
+			case b == nil:
+				return true
+			}
+			if c := compareValues(a, b); c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+
+	res := &AggregateResult{Source: source, Items: g.items, Skipped: g.skipped}
+	res.Columns = append(res.Columns, g.spec.GroupBy...)
+	for _, a := range g.spec.Metrics {
+		res.Columns = append(res.Columns, a.label())
+	}
+	for _, group := range groups {
+		row := make([]string, 0, len(res.Columns))
+		for _, v := range group.key {
+			if v == nil {
+				row = append(row, "(missing)")
+			} else {
+				row = append(row, cellText(v))
+			}
+		}
+		for i, a := range g.spec.Metrics {
+			row = append(row, group.metrics[i].text(strings.ToLower(a.Func)))
+		}
+		res.Rows = append(res.Rows, row)
+	}
+	return res
+}
+
+func (st metricState) text(fn string) string {
+	switch fn {
+	case "count":
+		return strconv.Itoa(st.count)
+	case "sum":
+		return formatAggregate(st.sum)
+	case "avg":
+		if st.numbers == 0 {
+			return "-"
+		}
+		return formatAggregate(st.sum / float64(st.numbers))
+	case "min":
+		if st.min == nil {
+			return "-"
+		}
+		return cellText(st.min)
+	case "max":
+		if st.max == nil {
+			return "-"
+		}
+		return cellText(st.max)
+	}
+	return ""
+}
+
+// formatAggregate prints a computed number without float noise.
+func formatAggregate(f float64) string {
+	return strconv.FormatFloat(math.Round(f*1e6)/1e6, 'f', -1, 64)
+}
+
+// aggregateItems runs an aggregation over items already in memory.
+func aggregateItems(items []Item, spec AggregateBlock, source string) *AggregateResult {
+	g := newAggregator(spec)
+	for _, item := range items {
+		g.add(item)
+	}
+	return g.result(source)
+}
+
+// shownItems returns the items of the active tab the user can see: the ones
+// passing the item filter, leaving out rows a refresh marked as removed.
+func (m *model) shownItems() []Item {
+	visible := m.visibleItems()
+	items := make([]Item, 0, len(visible))
+	for _, i := range visible {
+		if m.changes[i] != changeRemoved {
+			items = append(items, m.items[i])
+		}
+	}
+	return items
+}
+
+// loadedScope describes the items a "loaded" plan works on, noting a filter.
+func (m *model) loadedScope(n int) string {
+	scope := fmt.Sprintf("%d loaded items of %s", n, m.table)
+	if m.isCustomQuery {
+		scope = fmt.Sprintf("%d loaded query results from %s", n, m.table)
+	}
+	if f := m.itemFilter.Value(); f != "" {
+		scope += fmt.Sprintf(" matching the filter %q", f)
+	}
+	return scope
+}
+
+// aggregateLoadedItems aggregates the items shown in the active tab.
+func (m *model) aggregateLoadedItems(spec AggregateBlock) *AggregateResult {
+	items := m.shownItems()
+	return aggregateItems(items, spec, m.loadedScope(len(items)))
+}
+
+// loadedCount is how many items of table the active tab shows; see
+// shownItems.
+func (m *model) loadedCount(table string) int {
+	if m.table != table {
+		return 0
+	}
+	return len(m.shownItems())
+}
+
+// promptContext gathers what the model is told about t besides its keys.
+func (m *model) promptContext(t Table) PromptContext {
+	pc := PromptContext{
+		Profile:    m.profileFor(t.Name),
+		Loaded:     m.loadedCount(t.Name),
+		LoadedFrom: "a table scan",
+		History:    m.conversationFor(t.Name),
+	}
+	switch {
+	case m.isCustomQuery && len(m.query) > 0:
+		pc.LoadedFrom = strings.Join(m.query, "; ")
+	case m.isCustomQuery:
+		pc.LoadedFrom = "an earlier query"
+	}
+	return pc
+}
+
+// checkAggregate makes sure an aggregate plan can run before it is shown for
+// confirmation.
+func (m *model) checkAggregate(p *PlanBlock) error {
+	if p.Aggregate == nil {
+		return fmt.Errorf("The aggregate plan has no aggregate step.")
+	}
+	if err := p.Aggregate.validate(); err != nil {
+		return err
+	}
+	if p.Write != nil {
+		return fmt.Errorf("An aggregate plan can't write.")
+	}
+	if p.Aggregate.Source == aggregateLoaded {
+		table := m.tables[m.tableCursor].Name
+		if m.loadedCount(table) == 0 {
+			return fmt.Errorf("No items of %s are loaded to aggregate.", table)
+		}
+		return nil
+	}
+	if strings.TrimSpace(p.Read.Partiql) == "" || isMutationStatement(p.Read.Partiql) {
+		return fmt.Errorf("The aggregate plan needs a SELECT to read its items.")
+	}
+	return nil
+}
+
+// aggregateReadCmd reads every page of statement and aggregates it page by
+// page.
+func aggregateReadCmd(api *AWS, statement string, spec AggregateBlock, audit AuditEntry) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), aggregateTimeout)
+		defer cancel()
+
+		g := newAggregator(spec)
+		used, err := api.SqlQueryPages(ctx, Operation{expression: statement}, func(page []map[string]interface{}) {
+			for _, item := range page {
+				g.add(Item(item))
+			}
+		})
+		audit.finish(used, err)
+		if err != nil {
+			return aggregatedMsg{capacity: used, err: err}
+		}
+		return aggregatedMsg{result: g.result(statement), capacity: used}
+	}
+}
+
+// executeAggregate runs a confirmed aggregate plan: the loaded items are
+// aggregated right away, a read is paged through in the background.
+func (m *model) executeAggregate() (tea.Model, tea.Cmd) {
+	p := m.llmResult.Plan
+	if p.Aggregate.Source == aggregateLoaded {
+		m.loading = false
+		res := m.aggregateLoadedItems(*p.Aggregate)
+		m.settleTurn(aggregateOutcome(res), true)
+		m.showAggregate(res)
+		return m, nil
+	}
+	m.statusMessage = "Reading every page to aggregate..."
+	audit := m.newAuditEntry(auditPlanRead, p.Read.Partiql)
+	return m, aggregateReadCmd(m.aws, p.Read.Partiql, *p.Aggregate, audit)
+}
+
+// showAggregate opens the summary table of a finished aggregation.
+func (m *model) showAggregate(res *AggregateResult) {
+	m.aggregate = res
+	m.aggregateCursor = 0
+	m.view = viewAggregate
+}
+
+func (m *model) updateAggregate(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	last := max(len(m.aggregate.Rows)-1, 0)
+	switch msg.String() {
+	case "esc", "q":
+		m.view = m.previousView
+		m.aggregate = nil
+	case "up", "k":
+		m.aggregateCursor = max(m.aggregateCursor-1, 0)
+	case "down", "j":
+		m.aggregateCursor = min(m.aggregateCursor+1, last)
+	case "ctrl+d":
+		m.aggregateCursor = min(m.aggregateCursor+10, last)
+	case "ctrl+u":
+		m.aggregateCursor = max(m.aggregateCursor-10, 0)
+	case "y":
+		return m, copyCmd(m.aggregate.tsv(), "summary table as TSV")
+	}
+	return m, nil
+}
+
+// tsv is the summary table with a header row, ready to paste into a sheet.
+func (r *AggregateResult) tsv() string {
+	lines := []string{strings.Join(r.Columns, "\t")}
+	for _, row := range r.Rows {
+		lines = append(lines, strings.Join(row, "\t"))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderAggregate draws the summary table of the last aggregation.
+func (m model) renderAggregate() string {
+	r := m.aggregate
+	header := m.renderHeader("Aggregate: " + m.tables[m.tableCursor].Name)
+	dim := lipgloss.NewStyle().Foreground(textDim)
+
+	summary := fmt.Sprintf("%d items aggregated into %d rows · %s", r.Items, len(r.Rows), r.Source)
+	if r.Skipped > 0 {
+		summary += fmt.Sprintf(" · %d non-numeric values left out of sum/avg", r.Skipped)
+	}
+
+	widths := make([]int, len(r.Columns))
+	for i, c := range r.Columns {
+		widths[i] = lipgloss.Width(c)
+		for _, row := range r.Rows {
+			widths[i] = max(widths[i], lipgloss.Width(row[i]))
+		}
+		widths[i] = min(widths[i], 40)
+	}
+	line := func(cells []string) string {
+		parts := make([]string, len(cells))
+		for i, c := range cells {
+			parts[i] = fmt.Sprintf("%-*s", widths[i], truncateText(c, widths[i]))
+		}
+		return strings.Join(parts, "  ")
+	}
+
+	lines := []string{
+		dim.Render(truncateText(summary, m.width-4)),
+		"",
+		listHeaderStyle.Width(m.width - 4).Render(line(r.Columns)),
+	}
+	start, end := windowRange(len(r.Rows), m.aggregateCursor, max(m.height-12, 1))
+	for i := start; i < end; i++ {
+		if i == m.aggregateCursor {
+			lines = append(lines, listSelectedStyle.Width(m.width-4).Render(line(r.Rows[i])))
+		} else {
+			lines = append(lines, listItemStyle.Render(line(r.Rows[i])))
+		}
+	}
+	lines = append(lines, "", dim.Render("y copy as TSV · esc back"))
+
+	return lipgloss.JoinVertical(lipgloss.Left, header, m.renderTabs(), lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAggregateItems(t *testing.T) {
+	items := []Item{
+		{"id": "a", "status": "open", "price": 10.0, "lines": []interface{}{map[string]interface{}{"qty": 2.0}, map[string]interface{}{"qty": 3.0}}},
+		{"id": "b", "status": "open", "price": 5.5},
+		{"id": "c", "status": "closed", "price": "n/a"},
+		{"id": "d", "price": 1.0},
+	}
+	spec := AggregateBlock{
+		Source:  aggregateLoaded,
+		GroupBy: []string{"status"},
+		Metrics: []AggregateMetric{
+			{Func: "count", Path: "*"},
+			{Func: "sum", Path: "price"},
+			{Func: "avg", Path: "price"},
+			{Func: "max", Path: "price"},
+			{Func: "sum", Path: "lines[].qty"},
+		},
+	}
+	if err := spec.validate(); err != nil {
+		t.Fatal(err)
+	}
+	res := aggregateItems(items, spec, "test")
+
+	wantCols := []string{"status", "count(*)", "sum(price)", "avg(price)", "max(price)", "sum(lines[].qty)"}
+	if !reflect.DeepEqual(res.Columns, wantCols) {
+		t.Errorf("columns = %v, want %v", res.Columns, wantCols)
+	}
+	// Groups sort by value with missing last; strings sort after numbers
+	want := [][]string{
+		{"closed", "1", "0", "-", "n/a", "0"},
+		{"open", "2", "15.5", "7.75", "10", "5"},
+		{"(missing)", "1", "1", "1", "1", "0"},
+	}
+	if !reflect.DeepEqual(res.Rows, want) {
+		t.Errorf("rows = %v, want %v", res.Rows, want)
+	}
+	if res.Items != 4 || res.Skipped != 2 {
+		t.Errorf("items %d, skipped %d; want 4, 2", res.Items, res.Skipped)
+	}
+
+	// Without a group-by there is one row, even over nothing
+	total := aggregateItems(nil, AggregateBlock{Source: aggregateRead, Metrics: []AggregateMetric{{Func: "count"}, {Func: "min", Path: "price"}}}, "empty")
+	if !reflect.DeepEqual(total.Rows, [][]string{{"0", "-"}}) {
+		t.Errorf("empty rows = %v", total.Rows)
+	}
+}
+
+func TestPathValues(t *testing.T) {
+	item := Item{
+		"address": map[string]interface{}{"city": "Oslo"},
+		"tags":    []interface{}{"x", "y"},
+		"gone":    nil,
+	}
+	cases := []struct {
+		path string
+		want []interface{}
+	}{
+		{"address.city", []interface{}{"Oslo"}},
+		{"tags[1]", []interface{}{"y"}},
+		{"tags[]", []interface{}{"x", "y"}},
+		{"tags[5]", []interface{}{}},
+		{"gone", []interface{}{}},
+		{"address.zip", []interface{}{}},
+	}
+	for _, c := range cases {
+		got := pathValues(item, c.path)
+		if len(got) != len(c.want) || (len(got) > 0 && !reflect.DeepEqual(got, c.want)) {
+			t.Errorf("%s = %v, want %v", c.path, got, c.want)
+		}
+	}
+}
+
+func TestAggregateValidate(t *testing.T) {
+	bad := []AggregateBlock{
+		{Source: "table", Metrics: []AggregateMetric{{Func: "count"}}},
+		{Source: aggregateRead},
+		{Source: aggregateRead, Metrics: []AggregateMetric{{Func: "median", Path: "x"}}},
+		{Source: aggregateRead, Metrics: []AggregateMetric{{Func: "sum", Path: "*"}}},
+	}
+	for _, b := range bad {
+		if err := b.validate(); err == nil {
+			t.Errorf("%+v: no error", b)
+		}
+	}
+}
+
+func TestAggregateLoadedItemsFollowsTheFilter(t *testing.T) {
+	s := newSession()
+	s.table = "orders"
+	s.items = []Item{{"id": "a", "status": "open"}, {"id": "b", "status": "closed"}, {"id": "c", "status": "open"}, {"id": "d", "status": "open"}}
+	s.changes[2] = changeRemoved
+	s.itemFilter.SetValue("open")
+	s.filterAllAttrs = true
+	m := model{tables: []Table{{Name: "orders", PK: "id"}}, sessions: []*session{s}, session: s}
+
+	// b is filtered out and c was removed by a refresh
+	res := m.aggregateLoadedItems(AggregateBlock{Source: aggregateLoaded, Metrics: []AggregateMetric{{Func: "count", Path: "*"}}})
+	if res.Items != 2 || m.loadedCount("orders") != 2 {
+		t.Errorf("aggregated %d items, loadedCount %d; want 2", res.Items, m.loadedCount("orders"))
+	}
+	if want := `2 loaded items of orders matching the filter "open"`; res.Source != want {
+		t.Errorf("source = %q, want %q", res.Source, want)
+	}
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AuditEntry is one line of the append-only audit log. Every statement DynoTUI
+// executes on the user's behalf and every item write ends up here.
+type AuditEntry struct {
+	Timestamp   time.Time                `json:"timestamp"`
+	Account     string                   `json:"account"`
+	Region      string                   `json:"region"`
+	Table       string                   `json:"table"`
+	Action      string                   `json:"action"`
+	Question    string                   `json:"question,omitempty"`
+	PartiQL     []string                 `json:"partiql,omitempty"`
+	Keys        []map[string]interface{} `json:"keys,omitempty"`
+	Attributes  []string                 `json:"attributes,omitempty"`   // Attributes set by an inline update
+	Index       string                   `json:"index,omitempty"`        // Index created or removed
+	Settings    []string                 `json:"settings,omitempty"`     // Table settings changed
+	Backup      string                   `json:"backup,omitempty"`       // Name of a backup created
+	Source      string                   `json:"source,omitempty"`       // Backup ARN or table a restore came from
+	RestoreTime string                   `json:"restore_time,omitempty"` // Point-in-time restores: RFC 3339 or "latest"
+	Edited      bool                     `json:"edited,omitempty"`       // Generated statements were edited by hand before running
+	Capacity    Capacity                 `json:"consumed_capacity"`
+	Outcome     string                   `json:"outcome"`
+	Error       string                   `json:"error,omitempty"`
+}
+
+// Audit actions
+const (
+	auditPutItem        = "put_item"
+	auditDeleteItem     = "delete_item"
+	auditUpdateItem     = "update_item"
+	auditBatchDelete    = "batch_delete_items"
+	auditBatchUpdate    = "batch_update_items"
+	auditStatement      = "execute_statement"
+	auditBatch          = "batch_execute_statement"
+	auditPlanRead       = "plan_read"
+	auditPlanBulkWrite  = "plan_bulk_write"
+	auditCreateTable    = "create_table"
+	auditDeleteTable    = "delete_table"
+	auditCreateIndex    = "create_index"
+	auditDeleteIndex    = "delete_index"
+	auditUpdateSettings = "update_table_settings"
+	auditCreateBackup   = "create_backup"
+	auditRestoreTable   = "restore_table"
+)
+
+var auditMu sync.Mutex
+
+func auditLogPath() (string, error) {
+	configDir, err := getConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "audit.jsonl"), nil
+}
+
+// newAuditEntry stamps an entry with the identity and table the model is
+// currently looking at. The result is filled in later by finish.
+func (m *model) newAuditEntry(action string, partiql ...string) AuditEntry {
+	e := AuditEntry{
+		Account: m.AccountId,
+		Region:  m.Region,
+		Action:  action,
+		PartiQL: partiql,
+	}
+	switch action {
+	case auditPutItem, auditDeleteItem, auditUpdateItem, auditBatchDelete, auditBatchUpdate,
+		auditCreateTable, auditDeleteTable, auditCreateIndex, auditDeleteIndex, auditUpdateSettings,
+		auditCreateBackup, auditRestoreTable:
+		// Manual edits are not tied to a natural-language question
+	default:
+		e.Question = m.lastQuestion
+		e.Edited = m.llmEdited
+	}
+	if len(m.tables) > 0 && m.tableCursor < len(m.tables) {
+		e.Table = m.tables[m.tableCursor].Name
+	}
+	return e
+}
+
+// finish records the outcome of the audited call and appends it to the log.
+func (e AuditEntry) finish(used Capacity, err error) {
+	e.Timestamp = time.Now()
+	e.Capacity = used
+	e.Outcome = "success"
+	if err != nil {
+		e.Outcome = "error"
+		e.Error = err.Error()
+	}
+	if werr := appendAudit(e); werr != nil {
+		log.Printf("audit log write failed: %v", werr)
+	}
+}
+
+func appendAudit(e AuditEntry) error {
+	path, err := auditLogPath()
+	if err != nil {
+		return err
+	}
+
+	auditMu.Lock()
+	defer auditMu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+// LoadAuditLog reads every entry from the log, newest first. Lines that fail
+// to parse are skipped so one bad write never hides the rest of the history.
+func LoadAuditLog() ([]AuditEntry, error) {
+	path, err := auditLogPath()
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []AuditEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var e AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	return entries, nil
+}
+
+// matches reports whether every whitespace separated term in filter appears
+// somewhere in the entry (case-insensitive).
+func (e AuditEntry) matches(filter string) bool {
+	terms := strings.Fields(strings.ToLower(filter))
+	if len(terms) == 0 {
+		return true
+	}
+	haystack := strings.ToLower(strings.Join([]string{
+		e.Account, e.Region, e.Table, e.Action, e.Question,
+		strings.Join(e.PartiQL, " "), e.Outcome, e.Error,
+		fmt.Sprintf("%v", e.Keys),
+	}, " "))
+	for _, t := range terms {
+		if !strings.Contains(haystack, t) {
+			return false
+		}
+	}
+	return true
+}
+
+// itemKey extracts the primary key attributes of item for the given table.
+func itemKey(item Item, t Table) map[string]interface{} {
+	key := make(map[string]interface{})
+	if val, ok := item[t.PK]; ok {
+		key[t.PK] = val
+	}
+	if t.SK != "" {
+		if val, ok := item[t.SK]; ok {
+			key[t.SK] = val
+		}
+	}
+	return key
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestAuditLog(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	if entries, err := LoadAuditLog(); err != nil || len(entries) != 0 {
+		t.Fatalf("missing log: %d entries, %v", len(entries), err)
+	}
+
+	AuditEntry{Table: "orders", Action: auditStatement}.finish(Capacity{ReadUnits: 1}, nil)
+	path, err := auditLogPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0600 {
+		t.Errorf("log mode = %o, want 600", mode)
+	}
+
+	// A torn or hand-edited line doesn't hide the entries around it
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("{\"table\": \"half\n")
+	f.Close()
+	AuditEntry{Table: "users", Action: auditDeleteItem}.finish(Capacity{}, errors.New("throttled"))
+
+	entries, err := LoadAuditLog()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Table != "users" || entries[1].Table != "orders" {
+		t.Fatalf("entries = %+v, want users then orders", entries)
+	}
+	if entries[0].Outcome != "error" || entries[0].Error != "throttled" || entries[1].Outcome != "success" {
+		t.Errorf("outcomes = %q (%q), %q", entries[0].Outcome, entries[0].Error, entries[1].Outcome)
+	}
+	if entries[0].Timestamp.IsZero() || entries[1].Capacity.ReadUnits != 1 {
+		t.Errorf("finish did not stamp the entry: %+v", entries)
+	}
+}
+
+func TestAuditEntryMatches(t *testing.T) {
+	e := AuditEntry{
+		Region:   "eu-west-1",
+		Table:    "Orders",
+		Action:   auditStatement,
+		Question: "orders over $100",
+		PartiQL:  []string{`SELECT * FROM "Orders" WHERE total > 100`},
+		Keys:     []map[string]interface{}{{"id": "o-42"}},
+		Outcome:  "error",
+		Error:    "ValidationException",
+	}
+	cases := map[string]bool{
+		"":                       true,
+		"orders":                 true,
+		"ORDERS error":           true,
+		"execute_statement o-42": true,
+		"total > 100":            true,
+		"validation eu-west-1":   true,
+		"orders users":           false,
+		"success":                false,
+	}
+	for filter, want := range cases {
+		if got := e.matches(filter); got != want {
+			t.Errorf("matches(%q) = %v, want %v", filter, got, want)
+		}
+	}
+}
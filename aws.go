@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
@@ -12,11 +14,14 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodbstreams"
+	streamtypes "github.com/aws/aws-sdk-go-v2/service/dynamodbstreams/types"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
 )
 
 type AWS struct {
 	Dynamo   *dynamodb.Client
+	Streams  *dynamodbstreams.Client
 	Bedrock  *bedrockruntime.Client
 	Region   string
 	AccountID string
@@ -39,15 +44,48 @@ func NewAWS(ctx context.Context) (*AWS, error) {
 
 	return &AWS{
 		Dynamo:    dynamodb.NewFromConfig(cfg),
+		Streams:   dynamodbstreams.NewFromConfig(cfg),
 		Bedrock:   bedrockruntime.NewFromConfig(cfg),
 		Region:    cfg.Region,
 		AccountID: accountID,
 	}, nil
 }
 
-func (a *AWS) SqlQuery(ctx context.Context, operation Operation) ([]map[string]interface{}, error) {
+// Capacity is the read/write capacity consumed by one or more DynamoDB calls.
+type Capacity struct {
+	ReadUnits  float64 `json:"read_units"`
+	WriteUnits float64 `json:"write_units"`
+}
+
+// add folds a ConsumedCapacity response into c. When DynamoDB only reports the
+// combined CapacityUnits, isWrite decides which side it is counted on.
+func (c *Capacity) add(cc *types.ConsumedCapacity, isWrite bool) {
+	if cc == nil {
+		return
+	}
+	if cc.ReadCapacityUnits != nil || cc.WriteCapacityUnits != nil {
+		if cc.ReadCapacityUnits != nil {
+			c.ReadUnits += *cc.ReadCapacityUnits
+		}
+		if cc.WriteCapacityUnits != nil {
+			c.WriteUnits += *cc.WriteCapacityUnits
+		}
+		return
+	}
+	if cc.CapacityUnits != nil {
+		if isWrite {
+			c.WriteUnits += *cc.CapacityUnits
+		} else {
+			c.ReadUnits += *cc.CapacityUnits
+		}
+	}
+}
+
+func (a *AWS) SqlQuery(ctx context.Context, operation Operation) ([]map[string]interface{}, Capacity, error) {
+	var used Capacity
 	input := &dynamodb.ExecuteStatementInput{
-		Statement: aws.String(operation.expression),
+		Statement:              aws.String(operation.expression),
+		ReturnConsumedCapacity: types.ReturnConsumedCapacityTotal,
 	}
 
 	if len(operation.params) > 0 {
@@ -56,20 +94,74 @@ func (a *AWS) SqlQuery(ctx context.Context, operation Operation) ([]map[string]i
 
 	result, err := a.Dynamo.ExecuteStatement(ctx, input)
 	if err != nil {
-		return nil, fmt.Errorf("PartiQL execution failed: %w", err)
+		return nil, used, fmt.Errorf("PartiQL execution failed: %w", err)
 	}
+	used.add(result.ConsumedCapacity, isMutationStatement(operation.expression))
 
 	var items []map[string]interface{}
 	if err := attributevalue.UnmarshalListOfMaps(result.Items, &items); err != nil {
-		return nil, fmt.Errorf("unmarshal items: %w", err)
+		return nil, used, fmt.Errorf("unmarshal items: %w", err)
 	}
 
-	return items, nil
+	return items, used, nil
 }
 
-func (a *AWS) BatchSqlQuery(ctx context.Context, statements []string) ([]map[string]interface{}, error) {
+// SqlQueryPages runs a statement to the end, following NextToken, and hands
+// each page to fn as it arrives.
+func (a *AWS) SqlQueryPages(ctx context.Context, operation Operation, fn func([]map[string]interface{})) (Capacity, error) {
+	var used Capacity
+	input := &dynamodb.ExecuteStatementInput{
+		Statement:              aws.String(operation.expression),
+		Parameters:             operation.params,
+		ReturnConsumedCapacity: types.ReturnConsumedCapacityTotal,
+	}
+	for {
+		result, err := a.Dynamo.ExecuteStatement(ctx, input)
+		if err != nil {
+			return used, fmt.Errorf("PartiQL execution failed: %w", err)
+		}
+		used.add(result.ConsumedCapacity, false)
+
+		var items []map[string]interface{}
+		if err := attributevalue.UnmarshalListOfMaps(result.Items, &items); err != nil {
+			return used, fmt.Errorf("unmarshal items: %w", err)
+		}
+		fn(items)
+
+		if result.NextToken == nil {
+			return used, nil
+		}
+		input.NextToken = result.NextToken
+	}
+}
+
+// statementError is one statement of a batch that DynamoDB rejected.
+type statementError struct {
+	index   int // Position of the statement in the batch
+	code    string
+	message string
+}
+
+func (e statementError) String() string {
+	return fmt.Sprintf("Statement %d failed: %s - %s", e.index+1, e.code, e.message)
+}
+
+// batchStatementErrors lists the statements of a batch that failed; the
+// others ran.
+type batchStatementErrors []statementError
+
+func (e batchStatementErrors) Error() string {
+	lines := make([]string, len(e))
+	for i, f := range e {
+		lines[i] = f.String()
+	}
+	return fmt.Sprintf("Batch execution encountered %d errors:\n%s", len(e), strings.Join(lines, "\n"))
+}
+
+func (a *AWS) BatchSqlQuery(ctx context.Context, statements []string) ([]map[string]interface{}, Capacity, error) {
 	var allItems []map[string]interface{}
-	var errorMsgs []string
+	var failures batchStatementErrors
+	var used Capacity
 
 	// Chunk size for BatchExecuteStatement is 25
 	chunkSize := 25
@@ -89,21 +181,31 @@ func (a *AWS) BatchSqlQuery(ctx context.Context, statements []string) ([]map[str
 		}
 
 		result, err := a.Dynamo.BatchExecuteStatement(ctx, &dynamodb.BatchExecuteStatementInput{
-			Statements: batchInputs,
+			Statements:             batchInputs,
+			ReturnConsumedCapacity: types.ReturnConsumedCapacityTotal,
 		})
 
 		if err != nil {
-			return allItems, fmt.Errorf("batch execution failed at chunk %d-%d: %w", i, end, err)
+			return allItems, used, fmt.Errorf("batch execution failed at chunk %d-%d: %w", i, end, err)
+		}
+
+		// DynamoDB reports one total per table, so a chunk with any write in
+		// it is booked as writes
+		isWrite := false
+		for _, sql := range chunk {
+			isWrite = isWrite || isMutationStatement(sql)
+		}
+		for k := range result.ConsumedCapacity {
+			used.add(&result.ConsumedCapacity[k], isWrite)
 		}
 
 		// Process responses for this chunk
 		for j, resp := range result.Responses {
 			if resp.Error != nil {
 				// Global index of the statement
-				stmtIdx := i + j + 1
-				msg := fmt.Sprintf("Statement %d failed: %s - %s", stmtIdx, resp.Error.Code, *resp.Error.Message)
-				log.Println(msg)
-				errorMsgs = append(errorMsgs, msg)
+				failure := statementError{index: i + j, code: string(resp.Error.Code), message: aws.ToString(resp.Error.Message)}
+				log.Println(failure)
+				failures = append(failures, failure)
 				continue
 			}
 			
@@ -116,11 +218,11 @@ func (a *AWS) BatchSqlQuery(ctx context.Context, statements []string) ([]map[str
 		}
 	}
 
-	if len(errorMsgs) > 0 {
-		return allItems, fmt.Errorf("Batch execution encountered %d errors:\n%s", len(errorMsgs), strings.Join(errorMsgs, "\n"))
+	if len(failures) > 0 {
+		return allItems, used, failures
 	}
 
-	return allItems, nil
+	return allItems, used, nil
 }
 
 // ListAllTables returns all DynamoDB table names in the configured account/region.
@@ -157,82 +259,118 @@ type TableDetails struct {
 	Region    string
 	ItemCount int64
 	GSIs      []string
+	GSIStatus map[string]string // Index name -> CREATING, UPDATING, DELETING or ACTIVE
 	Status    string
+	Restoring bool // Being restored from a backup or a point in time
+	BillingMode string
+	SizeBytes   int64
+	ReadCapacity int64 // Provisioned RCU, zero for on-demand tables
 }
 
 // ListTablesWithDetails fetches names and then calls DescribeTable for each to get schema info.
-func (a *AWS) ListTablesWithDetails(ctx context.Context) ([]TableDetails, string, string, error) {
+// The returned Capacity is what the per-table item count scans consumed.
+func (a *AWS) ListTablesWithDetails(ctx context.Context) ([]TableDetails, string, string, Capacity, error) {
+	var used Capacity
 	names, err := a.ListAllTables(ctx)
 	if err != nil {
-		return nil, "", "", err
+		return nil, "", "", used, err
 	}
 
 	var tables []TableDetails
 	for _, name := range names {
-		resp, err := a.Dynamo.DescribeTable(ctx, &dynamodb.DescribeTableInput{
-			TableName: aws.String(name),
-		})
+		details, err := a.DescribeTable(ctx, name)
 		if err != nil {
-			return nil, a.Region, a.AccountID, fmt.Errorf("describe table %s: %w", name, err)
-		}
-		
-		t := resp.Table
-		details := TableDetails{
-			Name:      *t.TableName,
-			Region:    a.Region,
-			ItemCount: 0,
-			Status:    string(t.TableStatus),
-		}
-		if t.ItemCount != nil {
-			details.ItemCount = *t.ItemCount
-		}
-
-		// Build map of attribute types
-		attrTypes := make(map[string]string)
-		for _, ad := range t.AttributeDefinitions {
-			attrTypes[*ad.AttributeName] = string(ad.AttributeType)
-		}
-
-		// Parse Key Schema
-		for _, k := range t.KeySchema {
-			if k.KeyType == types.KeyTypeHash {
-				details.PK = *k.AttributeName
-				if t, ok := attrTypes[details.PK]; ok {
-					details.PKType = t
-				}
-			} else if k.KeyType == types.KeyTypeRange {
-				details.SK = *k.AttributeName
-				if t, ok := attrTypes[details.SK]; ok {
-					details.SKType = t
-				}
-			}
-		}
-
-		// Parse GSIs
-		for _, gsi := range t.GlobalSecondaryIndexes {
-			details.GSIs = append(details.GSIs, *gsi.IndexName)
+			return nil, a.Region, a.AccountID, used, err
 		}
 
 		// Get Real-Time Count (Scan with Count)
 		scanOut, err := a.Dynamo.Scan(ctx, &dynamodb.ScanInput{
-			TableName: aws.String(name),
-			Select:    types.SelectCount,
+			TableName:              aws.String(name),
+			Select:                 types.SelectCount,
+			ReturnConsumedCapacity: types.ReturnConsumedCapacityTotal,
 		})
 		if err == nil {
 			details.ItemCount = int64(scanOut.Count)
+			used.add(scanOut.ConsumedCapacity, false)
 		}
 
 		tables = append(tables, details)
 	}
 
-	return tables, a.Region, a.AccountID, nil
+	return tables, a.Region, a.AccountID, used, nil
+}
+
+// DescribeTable reads the schema, status and size of one table. It does not
+// count the items; ListTablesWithDetails does that with a separate scan.
+func (a *AWS) DescribeTable(ctx context.Context, name string) (TableDetails, error) {
+	resp, err := a.Dynamo.DescribeTable(ctx, &dynamodb.DescribeTableInput{
+		TableName: aws.String(name),
+	})
+	if err != nil {
+		return TableDetails{}, fmt.Errorf("describe table %s: %w", name, err)
+	}
+
+	t := resp.Table
+	details := TableDetails{
+		Name:      *t.TableName,
+		Region:    a.Region,
+		ItemCount: 0,
+		Status:    string(t.TableStatus),
+	}
+	if t.ItemCount != nil {
+		details.ItemCount = *t.ItemCount
+	}
+	if t.TableSizeBytes != nil {
+		details.SizeBytes = *t.TableSizeBytes
+	}
+	if t.RestoreSummary != nil {
+		details.Restoring = aws.ToBool(t.RestoreSummary.RestoreInProgress)
+	}
+	// Tables created before billing modes existed omit the summary; they are provisioned
+	details.BillingMode = string(types.BillingModeProvisioned)
+	if t.BillingModeSummary != nil && t.BillingModeSummary.BillingMode != "" {
+		details.BillingMode = string(t.BillingModeSummary.BillingMode)
+	}
+	if t.ProvisionedThroughput != nil && t.ProvisionedThroughput.ReadCapacityUnits != nil {
+		details.ReadCapacity = *t.ProvisionedThroughput.ReadCapacityUnits
+	}
+
+	// Build map of attribute types
+	attrTypes := make(map[string]string)
+	for _, ad := range t.AttributeDefinitions {
+		attrTypes[*ad.AttributeName] = string(ad.AttributeType)
+	}
+
+	// Parse Key Schema
+	for _, k := range t.KeySchema {
+		if k.KeyType == types.KeyTypeHash {
+			details.PK = *k.AttributeName
+			if t, ok := attrTypes[details.PK]; ok {
+				details.PKType = t
+			}
+		} else if k.KeyType == types.KeyTypeRange {
+			details.SK = *k.AttributeName
+			if t, ok := attrTypes[details.SK]; ok {
+				details.SKType = t
+			}
+		}
+	}
+
+	// Parse GSIs
+	details.GSIStatus = make(map[string]string)
+	for _, gsi := range t.GlobalSecondaryIndexes {
+		details.GSIs = append(details.GSIs, *gsi.IndexName)
+		details.GSIStatus[*gsi.IndexName] = string(gsi.IndexStatus)
+	}
+	return details, nil
 }
 
 // ScanTable fetches items from DynamoDB. It accepts an exclusiveStartKey for pagination.
 // It returns up to 1000 items and the LastEvaluatedKey for the next page.
-func (a *AWS) ScanTable(ctx context.Context, tableName string, startKey map[string]types.AttributeValue) ([]map[string]interface{}, map[string]types.AttributeValue, error) {
+func (a *AWS) ScanTable(ctx context.Context, tableName string, startKey map[string]types.AttributeValue) ([]map[string]interface{}, map[string]types.AttributeValue, Capacity, error) {
 	var items []map[string]interface{}
 	var lastKey map[string]types.AttributeValue = startKey
+	var used Capacity
 
 	// Loop until we have 1000 items or no more pages
 	for {
@@ -240,12 +378,14 @@ func (a *AWS) ScanTable(ctx context.Context, tableName string, startKey map[stri
 			TableName:         aws.String(tableName),
 			ExclusiveStartKey: lastKey,
 			Limit:             aws.Int32(1000 - int32(len(items))), // Request only what we need to reach 1000
+			ReturnConsumedCapacity: types.ReturnConsumedCapacityTotal,
 		}
 
 		resp, err := a.Dynamo.Scan(ctx, input)
 		if err != nil {
-			return nil, nil, fmt.Errorf("scan failed: %w", err)
+			return nil, nil, used, fmt.Errorf("scan failed: %w", err)
 		}
+		used.add(resp.ConsumedCapacity, false)
 
 		for _, item := range resp.Items {
 			var unmarshalledItem map[string]interface{}
@@ -262,43 +402,787 @@ func (a *AWS) ScanTable(ctx context.Context, tableName string, startKey map[stri
 		}
 	}
 
-	return items, lastKey, nil
+	return items, lastKey, used, nil
 }
 
 // PutItem uploads an item to DynamoDB (Update/Insert)
-func (a *AWS) PutItem(ctx context.Context, tableName string, item map[string]interface{}) error {
+func (a *AWS) PutItem(ctx context.Context, tableName string, item map[string]interface{}) (Capacity, error) {
+	var used Capacity
 	// Marshal Go map to DynamoDB AttributeValue map
 	av, err := attributevalue.MarshalMap(item)
 	if err != nil {
-		return fmt.Errorf("marshal item: %w", err)
+		return used, fmt.Errorf("marshal item: %w", err)
 	}
 
-	_, err = a.Dynamo.PutItem(ctx, &dynamodb.PutItemInput{
-		TableName: aws.String(tableName),
-		Item:      av,
+	resp, err := a.Dynamo.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:              aws.String(tableName),
+		Item:                   av,
+		ReturnConsumedCapacity: types.ReturnConsumedCapacityTotal,
 	})
 	if err != nil {
-		return fmt.Errorf("put item: %w", err)
+		return used, fmt.Errorf("put item: %w", err)
 	}
+	used.add(resp.ConsumedCapacity, true)
 
-	return nil
+	return used, nil
+}
+
+// UpdateItemAttributes sets the given attributes on an existing item, leaving
+// the rest of the item untouched. It fails if the item no longer exists rather
+// than creating a partial one.
+func (a *AWS) UpdateItemAttributes(ctx context.Context, tableName string, key map[string]interface{}, set map[string]interface{}) (Capacity, error) {
+	var used Capacity
+	av, err := attributevalue.MarshalMap(key)
+	if err != nil {
+		return used, fmt.Errorf("marshal key: %w", err)
+	}
+
+	attrs := make([]string, 0, len(set))
+	for attr := range set {
+		attrs = append(attrs, attr)
+	}
+	sort.Strings(attrs)
+
+	names := make(map[string]string, len(attrs)+1)
+	values := make(map[string]types.AttributeValue, len(attrs))
+	clauses := make([]string, 0, len(attrs))
+	for i, attr := range attrs {
+		v, err := attributevalue.Marshal(set[attr])
+		if err != nil {
+			return used, fmt.Errorf("marshal %s: %w", attr, err)
+		}
+		names[fmt.Sprintf("#a%d", i)] = attr
+		values[fmt.Sprintf(":v%d", i)] = v
+		clauses = append(clauses, fmt.Sprintf("#a%d = :v%d", i, i))
+	}
+
+	// Any key attribute will do for the existence check
+	var keyName string
+	for k := range key {
+		keyName = k
+		break
+	}
+	names["#k"] = keyName
+
+	resp, err := a.Dynamo.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(tableName),
+		Key:                       av,
+		UpdateExpression:          aws.String("SET " + strings.Join(clauses, ", ")),
+		ConditionExpression:       aws.String("attribute_exists(#k)"),
+		ExpressionAttributeNames:  names,
+		ExpressionAttributeValues: values,
+		ReturnConsumedCapacity:    types.ReturnConsumedCapacityTotal,
+	})
+	if err != nil {
+		return used, fmt.Errorf("update item: %w", err)
+	}
+	used.add(resp.ConsumedCapacity, true)
+
+	return used, nil
+}
+
+// batchWriter is the part of the DynamoDB client that batch deletes use, so
+// tests can stand in for it.
+type batchWriter interface {
+	BatchWriteItem(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error)
+}
+
+// BatchDeleteItems deletes items by key with BatchWriteItem, 25 keys per
+// request. Unprocessed keys are retried with a growing delay. It returns the
+// positions in keys of the items known to be deleted, which on failure
+// includes the part of the last request that DynamoDB did process.
+func (a *AWS) BatchDeleteItems(ctx context.Context, tableName string, keys []map[string]interface{}) ([]int, Capacity, error) {
+	return batchDeleteItems(ctx, a.Dynamo, tableName, keys)
+}
+
+func batchDeleteItems(ctx context.Context, db batchWriter, tableName string, keys []map[string]interface{}) ([]int, Capacity, error) {
+	var used Capacity
+	var deleted []int
+	chunkSize := 25
+
+	for i := 0; i < len(keys); i += chunkSize {
+		end := min(i+chunkSize, len(keys))
+
+		var requests []types.WriteRequest
+		for _, key := range keys[i:end] {
+			av, err := attributevalue.MarshalMap(key)
+			if err != nil {
+				return deleted, used, fmt.Errorf("marshal key: %w", err)
+			}
+			requests = append(requests, types.WriteRequest{DeleteRequest: &types.DeleteRequest{Key: av}})
+		}
+
+		// settle adds the keys of this chunk that are no longer pending
+		settle := func(pending []types.WriteRequest) []int {
+			left := make(map[string]bool, len(pending))
+			for _, r := range pending {
+				if r.DeleteRequest != nil {
+					left[keyString(r.DeleteRequest.Key)] = true
+				}
+			}
+			for j, r := range requests {
+				if !left[keyString(r.DeleteRequest.Key)] {
+					deleted = append(deleted, i+j)
+				}
+			}
+			return deleted
+		}
+
+		pending := map[string][]types.WriteRequest{tableName: requests}
+		for attempt := 0; len(pending[tableName]) > 0; attempt++ {
+			if attempt > 0 {
+				if attempt > 5 {
+					return settle(pending[tableName]), used, fmt.Errorf("batch delete: %d items still unprocessed after retries", len(pending[tableName]))
+				}
+				select {
+				case <-ctx.Done():
+					return settle(pending[tableName]), used, ctx.Err()
+				case <-time.After(time.Duration(50<<attempt) * time.Millisecond):
+				}
+			}
+
+			resp, err := db.BatchWriteItem(ctx, &dynamodb.BatchWriteItemInput{
+				RequestItems:           pending,
+				ReturnConsumedCapacity: types.ReturnConsumedCapacityTotal,
+			})
+			if err != nil {
+				return settle(pending[tableName]), used, fmt.Errorf("batch delete at items %d-%d: %w", i, end, err)
+			}
+			for k := range resp.ConsumedCapacity {
+				used.add(&resp.ConsumedCapacity[k], true)
+			}
+			pending = resp.UnprocessedItems
+		}
+		settle(nil)
+	}
+
+	return deleted, used, nil
+}
+
+// keyString renders a DynamoDB key so that keys can be compared; Go prints
+// map keys in sorted order.
+func keyString(key map[string]types.AttributeValue) string {
+	var m map[string]interface{}
+	if err := attributevalue.UnmarshalMap(key, &m); err != nil {
+		return fmt.Sprint(key)
+	}
+	return fmt.Sprintf("%v", m)
 }
 
 // DeleteItem deletes an item from DynamoDB
-func (a *AWS) DeleteItem(ctx context.Context, tableName string, key map[string]interface{}) error {
+func (a *AWS) DeleteItem(ctx context.Context, tableName string, key map[string]interface{}) (Capacity, error) {
+	var used Capacity
 	// Marshal Go map to DynamoDB AttributeValue map for key
 	av, err := attributevalue.MarshalMap(key)
 	if err != nil {
-		return fmt.Errorf("marshal key: %w", err)
+		return used, fmt.Errorf("marshal key: %w", err)
+	}
+
+	resp, err := a.Dynamo.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:              aws.String(tableName),
+		Key:                    av,
+		ReturnConsumedCapacity: types.ReturnConsumedCapacityTotal,
+	})
+	if err != nil {
+		return used, fmt.Errorf("delete item: %w", err)
+	}
+	used.add(resp.ConsumedCapacity, true)
+
+	return used, nil
+}
+
+// IndexSpec describes a secondary index to create. Local indexes share the
+// table's partition key, so only SK is used for them.
+type IndexSpec struct {
+	Name   string
+	PK     string
+	PKType string
+	SK     string
+	SKType string
+}
+
+// TableSpec is everything the create table form collects.
+type TableSpec struct {
+	Name          string
+	PK            string
+	PKType        string
+	SK            string
+	SKType        string
+	OnDemand      bool
+	ReadCapacity  int64 // Provisioned tables only, also used for their GSIs
+	WriteCapacity int64
+	GSIs          []IndexSpec
+	LSIs          []IndexSpec
+}
+
+// attributeDefinitions collects the key attributes of a table and its indexes.
+// An attribute used by several keys must have the same type everywhere.
+func attributeDefinitions(keys [][2]string) ([]types.AttributeDefinition, error) {
+	seen := make(map[string]string)
+	var defs []types.AttributeDefinition
+	for _, k := range keys {
+		name, typ := k[0], k[1]
+		if name == "" {
+			continue
+		}
+		if prev, ok := seen[name]; ok {
+			if prev != typ {
+				return nil, fmt.Errorf("attribute %s is used as both %s and %s", name, prev, typ)
+			}
+			continue
+		}
+		seen[name] = typ
+		defs = append(defs, types.AttributeDefinition{
+			AttributeName: aws.String(name),
+			AttributeType: types.ScalarAttributeType(typ),
+		})
+	}
+	return defs, nil
+}
+
+func keySchema(pk, sk string) []types.KeySchemaElement {
+	schema := []types.KeySchemaElement{{AttributeName: aws.String(pk), KeyType: types.KeyTypeHash}}
+	if sk != "" {
+		schema = append(schema, types.KeySchemaElement{AttributeName: aws.String(sk), KeyType: types.KeyTypeRange})
+	}
+	return schema
+}
+
+// CreateTable creates a table with its indexes. The table starts out
+// CREATING; poll DescribeTable until it is ACTIVE.
+func (a *AWS) CreateTable(ctx context.Context, spec TableSpec) error {
+	keys := [][2]string{{spec.PK, spec.PKType}, {spec.SK, spec.SKType}}
+	for _, idx := range spec.GSIs {
+		keys = append(keys, [2]string{idx.PK, idx.PKType}, [2]string{idx.SK, idx.SKType})
+	}
+	for _, idx := range spec.LSIs {
+		keys = append(keys, [2]string{idx.SK, idx.SKType})
+	}
+	defs, err := attributeDefinitions(keys)
+	if err != nil {
+		return err
+	}
+
+	input := &dynamodb.CreateTableInput{
+		TableName:            aws.String(spec.Name),
+		AttributeDefinitions: defs,
+		KeySchema:            keySchema(spec.PK, spec.SK),
+		BillingMode:          types.BillingModePayPerRequest,
+	}
+	var throughput *types.ProvisionedThroughput
+	if !spec.OnDemand {
+		input.BillingMode = types.BillingModeProvisioned
+		throughput = &types.ProvisionedThroughput{
+			ReadCapacityUnits:  aws.Int64(spec.ReadCapacity),
+			WriteCapacityUnits: aws.Int64(spec.WriteCapacity),
+		}
+		input.ProvisionedThroughput = throughput
+	}
+	for _, idx := range spec.GSIs {
+		input.GlobalSecondaryIndexes = append(input.GlobalSecondaryIndexes, types.GlobalSecondaryIndex{
+			IndexName:             aws.String(idx.Name),
+			KeySchema:             keySchema(idx.PK, idx.SK),
+			Projection:            &types.Projection{ProjectionType: types.ProjectionTypeAll},
+			ProvisionedThroughput: throughput,
+		})
+	}
+	for _, idx := range spec.LSIs {
+		input.LocalSecondaryIndexes = append(input.LocalSecondaryIndexes, types.LocalSecondaryIndex{
+			IndexName:  aws.String(idx.Name),
+			KeySchema:  keySchema(spec.PK, idx.SK),
+			Projection: &types.Projection{ProjectionType: types.ProjectionTypeAll},
+		})
+	}
+
+	if _, err := a.Dynamo.CreateTable(ctx, input); err != nil {
+		return fmt.Errorf("create table: %w", err)
+	}
+	return nil
+}
+
+// DeleteTable deletes a table and all of its items.
+func (a *AWS) DeleteTable(ctx context.Context, tableName string) error {
+	_, err := a.Dynamo.DeleteTable(ctx, &dynamodb.DeleteTableInput{
+		TableName: aws.String(tableName),
+	})
+	if err != nil {
+		return fmt.Errorf("delete table: %w", err)
+	}
+	return nil
+}
+
+// CreateGSI adds a global secondary index projecting all attributes. Indexes
+// on provisioned tables get the table's read capacity for reads and writes.
+func (a *AWS) CreateGSI(ctx context.Context, t Table, idx IndexSpec) error {
+	defs, err := attributeDefinitions([][2]string{{idx.PK, idx.PKType}, {idx.SK, idx.SKType}})
+	if err != nil {
+		return err
+	}
+	create := &types.CreateGlobalSecondaryIndexAction{
+		IndexName:  aws.String(idx.Name),
+		KeySchema:  keySchema(idx.PK, idx.SK),
+		Projection: &types.Projection{ProjectionType: types.ProjectionTypeAll},
 	}
+	if t.BillingMode == string(types.BillingModeProvisioned) {
+		units := max(t.ReadCapacity, 1)
+		create.ProvisionedThroughput = &types.ProvisionedThroughput{
+			ReadCapacityUnits:  aws.Int64(units),
+			WriteCapacityUnits: aws.Int64(units),
+		}
+	}
+
+	_, err = a.Dynamo.UpdateTable(ctx, &dynamodb.UpdateTableInput{
+		TableName:                   aws.String(t.Name),
+		AttributeDefinitions:        defs,
+		GlobalSecondaryIndexUpdates: []types.GlobalSecondaryIndexUpdate{{Create: create}},
+	})
+	if err != nil {
+		return fmt.Errorf("create index: %w", err)
+	}
+	return nil
+}
 
-	_, err = a.Dynamo.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+// DeleteGSI removes a global secondary index from a table.
+func (a *AWS) DeleteGSI(ctx context.Context, tableName, index string) error {
+	_, err := a.Dynamo.UpdateTable(ctx, &dynamodb.UpdateTableInput{
 		TableName: aws.String(tableName),
-		Key:       av,
+		GlobalSecondaryIndexUpdates: []types.GlobalSecondaryIndexUpdate{{
+			Delete: &types.DeleteGlobalSecondaryIndexAction{IndexName: aws.String(index)},
+		}},
 	})
 	if err != nil {
-		return fmt.Errorf("delete item: %w", err)
+		return fmt.Errorf("delete index: %w", err)
+	}
+	return nil
+}
+
+// TableSettings are the per-table options edited in the settings panel.
+type TableSettings struct {
+	ARN                string
+	TTLAttribute       string // Empty when TTL is off
+	TTLStatus          string // ENABLED, DISABLED, ENABLING or DISABLING
+	StreamViewType     string // Empty when streams are off
+	PITR               bool
+	DeletionProtection bool
+	Tags               map[string]string
+}
+
+// DescribeTableSettings reads TTL, streams, point-in-time recovery, deletion
+// protection and tags of a table.
+func (a *AWS) DescribeTableSettings(ctx context.Context, tableName string) (TableSettings, error) {
+	var s TableSettings
+	desc, err := a.Dynamo.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(tableName)})
+	if err != nil {
+		return s, fmt.Errorf("describe table: %w", err)
+	}
+	t := desc.Table
+	s.ARN = aws.ToString(t.TableArn)
+	s.DeletionProtection = aws.ToBool(t.DeletionProtectionEnabled)
+	if t.StreamSpecification != nil && aws.ToBool(t.StreamSpecification.StreamEnabled) {
+		s.StreamViewType = string(t.StreamSpecification.StreamViewType)
+	}
+
+	ttl, err := a.Dynamo.DescribeTimeToLive(ctx, &dynamodb.DescribeTimeToLiveInput{TableName: aws.String(tableName)})
+	if err != nil {
+		return s, fmt.Errorf("describe time to live: %w", err)
+	}
+	if d := ttl.TimeToLiveDescription; d != nil {
+		s.TTLStatus = string(d.TimeToLiveStatus)
+		if d.TimeToLiveStatus != types.TimeToLiveStatusDisabled {
+			s.TTLAttribute = aws.ToString(d.AttributeName)
+		}
+	}
+
+	backups, err := a.Dynamo.DescribeContinuousBackups(ctx, &dynamodb.DescribeContinuousBackupsInput{TableName: aws.String(tableName)})
+	if err != nil {
+		return s, fmt.Errorf("describe continuous backups: %w", err)
+	}
+	if d := backups.ContinuousBackupsDescription; d != nil && d.PointInTimeRecoveryDescription != nil {
+		s.PITR = d.PointInTimeRecoveryDescription.PointInTimeRecoveryStatus == types.PointInTimeRecoveryStatusEnabled
+	}
+
+	s.Tags = make(map[string]string)
+	var next *string
+	for {
+		tags, err := a.Dynamo.ListTagsOfResource(ctx, &dynamodb.ListTagsOfResourceInput{ResourceArn: t.TableArn, NextToken: next})
+		if err != nil {
+			return s, fmt.Errorf("list tags: %w", err)
+		}
+		for _, tag := range tags.Tags {
+			s.Tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+		}
+		if tags.NextToken == nil {
+			break
+		}
+		next = tags.NextToken
+	}
+	return s, nil
+}
+
+// UpdateTableSettings applies the settings that differ between old and s.
+// Each setting is its own API call, so a failure can leave earlier ones
+// applied; the names of the applied settings are returned either way.
+func (a *AWS) UpdateTableSettings(ctx context.Context, tableName string, old, s TableSettings) ([]string, error) {
+	// DynamoDB refuses these in place; catch them before changing anything
+	if s.TTLAttribute != old.TTLAttribute && s.TTLAttribute != "" && old.TTLAttribute != "" {
+		return nil, fmt.Errorf("TTL is already enabled on %s; disable it before choosing another attribute", old.TTLAttribute)
+	}
+	if s.StreamViewType != old.StreamViewType && s.StreamViewType != "" && old.StreamViewType != "" {
+		return nil, fmt.Errorf("the stream view type can't be changed while the stream is on; turn the stream off first")
+	}
+
+	var applied []string
+	name := aws.String(tableName)
+
+	if s.DeletionProtection != old.DeletionProtection {
+		_, err := a.Dynamo.UpdateTable(ctx, &dynamodb.UpdateTableInput{
+			TableName:                 name,
+			DeletionProtectionEnabled: aws.Bool(s.DeletionProtection),
+		})
+		if err != nil {
+			return applied, fmt.Errorf("update deletion protection: %w", err)
+		}
+		applied = append(applied, settingDeletionProtection)
 	}
 
+	if s.TTLAttribute != old.TTLAttribute {
+		// Turning TTL off needs the attribute it was enabled on
+		spec := &types.TimeToLiveSpecification{AttributeName: aws.String(s.TTLAttribute), Enabled: aws.Bool(true)}
+		if s.TTLAttribute == "" {
+			spec = &types.TimeToLiveSpecification{AttributeName: aws.String(old.TTLAttribute), Enabled: aws.Bool(false)}
+		}
+		if _, err := a.Dynamo.UpdateTimeToLive(ctx, &dynamodb.UpdateTimeToLiveInput{TableName: name, TimeToLiveSpecification: spec}); err != nil {
+			return applied, fmt.Errorf("update time to live: %w", err)
+		}
+		applied = append(applied, settingTTL)
+	}
+
+	if s.PITR != old.PITR {
+		_, err := a.Dynamo.UpdateContinuousBackups(ctx, &dynamodb.UpdateContinuousBackupsInput{
+			TableName: name,
+			PointInTimeRecoverySpecification: &types.PointInTimeRecoverySpecification{
+				PointInTimeRecoveryEnabled: aws.Bool(s.PITR),
+			},
+		})
+		if err != nil {
+			return applied, fmt.Errorf("update point-in-time recovery: %w", err)
+		}
+		applied = append(applied, settingPITR)
+	}
+
+	var set []types.Tag
+	var unset []string
+	for k, v := range s.Tags {
+		if ov, ok := old.Tags[k]; !ok || ov != v {
+			set = append(set, types.Tag{Key: aws.String(k), Value: aws.String(v)})
+		}
+	}
+	for k := range old.Tags {
+		if _, ok := s.Tags[k]; !ok {
+			unset = append(unset, k)
+		}
+	}
+	if len(set) > 0 {
+		if _, err := a.Dynamo.TagResource(ctx, &dynamodb.TagResourceInput{ResourceArn: aws.String(old.ARN), Tags: set}); err != nil {
+			return applied, fmt.Errorf("tag table: %w", err)
+		}
+	}
+	if len(unset) > 0 {
+		if _, err := a.Dynamo.UntagResource(ctx, &dynamodb.UntagResourceInput{ResourceArn: aws.String(old.ARN), TagKeys: unset}); err != nil {
+			return applied, fmt.Errorf("untag table: %w", err)
+		}
+	}
+	if len(set) > 0 || len(unset) > 0 {
+		applied = append(applied, settingTags)
+	}
+
+	// Last, since it leaves the table UPDATING for a while
+	if s.StreamViewType != old.StreamViewType {
+		spec := &types.StreamSpecification{StreamEnabled: aws.Bool(s.StreamViewType != "")}
+		if s.StreamViewType != "" {
+			spec.StreamViewType = types.StreamViewType(s.StreamViewType)
+		}
+		if _, err := a.Dynamo.UpdateTable(ctx, &dynamodb.UpdateTableInput{TableName: name, StreamSpecification: spec}); err != nil {
+			return applied, fmt.Errorf("update stream: %w", err)
+		}
+		applied = append(applied, settingStream)
+	}
+	return applied, nil
+}
+
+// Backup is one on-demand backup of a table.
+type Backup struct {
+	ARN       string
+	Name      string
+	Status    string // CREATING, AVAILABLE or DELETED
+	Type      string // USER, SYSTEM or AWS_BACKUP
+	Created   time.Time
+	SizeBytes int64
+}
+
+// PITRWindow is the range a point-in-time restore can target.
+type PITRWindow struct {
+	Enabled  bool
+	Earliest time.Time
+	Latest   time.Time
+}
+
+// ListBackups returns the backups of a table, newest first, together with
+// its point-in-time recovery window.
+func (a *AWS) ListBackups(ctx context.Context, tableName string) ([]Backup, PITRWindow, error) {
+	var backups []Backup
+	var window PITRWindow
+	var start *string
+	for {
+		resp, err := a.Dynamo.ListBackups(ctx, &dynamodb.ListBackupsInput{
+			TableName:               aws.String(tableName),
+			ExclusiveStartBackupArn: start,
+		})
+		if err != nil {
+			return nil, window, fmt.Errorf("list backups: %w", err)
+		}
+		for _, b := range resp.BackupSummaries {
+			backups = append(backups, Backup{
+				ARN:       aws.ToString(b.BackupArn),
+				Name:      aws.ToString(b.BackupName),
+				Status:    string(b.BackupStatus),
+				Type:      string(b.BackupType),
+				Created:   aws.ToTime(b.BackupCreationDateTime),
+				SizeBytes: aws.ToInt64(b.BackupSizeBytes),
+			})
+		}
+		if resp.LastEvaluatedBackupArn == nil {
+			break
+		}
+		start = resp.LastEvaluatedBackupArn
+	}
+	sort.SliceStable(backups, func(i, j int) bool { return backups[i].Created.After(backups[j].Created) })
+
+	cb, err := a.Dynamo.DescribeContinuousBackups(ctx, &dynamodb.DescribeContinuousBackupsInput{TableName: aws.String(tableName)})
+	if err != nil {
+		return backups, window, fmt.Errorf("describe continuous backups: %w", err)
+	}
+	if d := cb.ContinuousBackupsDescription; d != nil && d.PointInTimeRecoveryDescription != nil {
+		p := d.PointInTimeRecoveryDescription
+		window.Enabled = p.PointInTimeRecoveryStatus == types.PointInTimeRecoveryStatusEnabled
+		window.Earliest = aws.ToTime(p.EarliestRestorableDateTime)
+		window.Latest = aws.ToTime(p.LatestRestorableDateTime)
+	}
+	return backups, window, nil
+}
+
+// CreateBackup starts an on-demand backup of a table.
+func (a *AWS) CreateBackup(ctx context.Context, tableName, name string) (string, error) {
+	resp, err := a.Dynamo.CreateBackup(ctx, &dynamodb.CreateBackupInput{
+		TableName:  aws.String(tableName),
+		BackupName: aws.String(name),
+	})
+	if err != nil {
+		return "", fmt.Errorf("create backup: %w", err)
+	}
+	return aws.ToString(resp.BackupDetails.BackupArn), nil
+}
+
+// RestoreBackup restores a backup into a new table.
+func (a *AWS) RestoreBackup(ctx context.Context, backupARN, target string) error {
+	_, err := a.Dynamo.RestoreTableFromBackup(ctx, &dynamodb.RestoreTableFromBackupInput{
+		BackupArn:       aws.String(backupARN),
+		TargetTableName: aws.String(target),
+	})
+	if err != nil {
+		return fmt.Errorf("restore backup: %w", err)
+	}
 	return nil
 }
+
+// RestorePointInTime restores a table as it was at the given time into a new
+// table. A zero time restores the latest restorable state.
+func (a *AWS) RestorePointInTime(ctx context.Context, source, target string, at time.Time) error {
+	input := &dynamodb.RestoreTableToPointInTimeInput{
+		SourceTableName: aws.String(source),
+		TargetTableName: aws.String(target),
+	}
+	if at.IsZero() {
+		input.UseLatestRestorableTime = aws.Bool(true)
+	} else {
+		input.RestoreDateTime = aws.Time(at)
+	}
+	if _, err := a.Dynamo.RestoreTableToPointInTime(ctx, input); err != nil {
+		return fmt.Errorf("restore to point in time: %w", err)
+	}
+	return nil
+}
+
+// StreamRecord is one change read from a table's stream. The images are
+// only present when the stream's view type includes them.
+type StreamRecord struct {
+	EventID  string
+	Type     string // INSERT, MODIFY or REMOVE
+	Time     time.Time
+	Keys     map[string]interface{}
+	OldImage map[string]interface{}
+	NewImage map[string]interface{}
+}
+
+// StreamPosition is how far a stream has been read: the next iterator of
+// every open shard that is being followed.
+type StreamPosition struct {
+	ARN       string
+	ViewType  string
+	Iterators map[string]string // Shard ID -> next shard iterator
+	Started   bool
+}
+
+// ReadStream reads the records written since pos. The first read resolves the
+// table's latest stream and starts at the tip of its open shards; shards
+// that open later, when DynamoDB splits or rotates them, are read from their
+// start so no change is missed. pos itself is left untouched.
+func (a *AWS) ReadStream(ctx context.Context, tableName string, pos StreamPosition) ([]StreamRecord, StreamPosition, error) {
+	next := StreamPosition{ARN: pos.ARN, ViewType: pos.ViewType, Iterators: make(map[string]string), Started: true}
+	for shard, it := range pos.Iterators {
+		next.Iterators[shard] = it
+	}
+
+	if next.ARN == "" {
+		desc, err := a.Dynamo.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(tableName)})
+		if err != nil {
+			return nil, pos, fmt.Errorf("describe table: %w", err)
+		}
+		spec := desc.Table.StreamSpecification
+		if spec == nil || !aws.ToBool(spec.StreamEnabled) || desc.Table.LatestStreamArn == nil {
+			return nil, pos, fmt.Errorf("streams are off for %s; turn them on in the settings panel (s)", tableName)
+		}
+		next.ARN = aws.ToString(desc.Table.LatestStreamArn)
+		next.ViewType = string(spec.StreamViewType)
+	}
+
+	// Pick up open shards that aren't followed yet
+	start := streamtypes.ShardIteratorTypeTrimHorizon
+	if !pos.Started {
+		start = streamtypes.ShardIteratorTypeLatest
+	}
+	var lastShard *string
+	for {
+		out, err := a.Streams.DescribeStream(ctx, &dynamodbstreams.DescribeStreamInput{
+			StreamArn:             aws.String(next.ARN),
+			ExclusiveStartShardId: lastShard,
+		})
+		if err != nil {
+			return nil, pos, fmt.Errorf("describe stream: %w", err)
+		}
+		for _, shard := range out.StreamDescription.Shards {
+			id := aws.ToString(shard.ShardId)
+			closed := shard.SequenceNumberRange != nil && shard.SequenceNumberRange.EndingSequenceNumber != nil
+			if _, ok := next.Iterators[id]; ok || closed {
+				continue
+			}
+			it, err := a.Streams.GetShardIterator(ctx, &dynamodbstreams.GetShardIteratorInput{
+				StreamArn:         aws.String(next.ARN),
+				ShardId:           shard.ShardId,
+				ShardIteratorType: start,
+			})
+			if err != nil {
+				return nil, pos, fmt.Errorf("get shard iterator: %w", err)
+			}
+			next.Iterators[id] = aws.ToString(it.ShardIterator)
+		}
+		lastShard = out.StreamDescription.LastEvaluatedShardId
+		if lastShard == nil {
+			break
+		}
+	}
+
+	var records []StreamRecord
+	for shard, it := range next.Iterators {
+		out, err := a.Streams.GetRecords(ctx, &dynamodbstreams.GetRecordsInput{ShardIterator: aws.String(it)})
+		if err != nil {
+			return nil, pos, fmt.Errorf("get records: %w", err)
+		}
+		for _, r := range out.Records {
+			rec, err := streamRecord(r)
+			if err != nil {
+				return nil, pos, err
+			}
+			records = append(records, rec)
+		}
+		// A closed shard has been read to its end; its children take over
+		if out.NextShardIterator == nil {
+			delete(next.Iterators, shard)
+		} else {
+			next.Iterators[shard] = aws.ToString(out.NextShardIterator)
+		}
+	}
+
+	sort.SliceStable(records, func(i, j int) bool { return records[i].Time.Before(records[j].Time) })
+	return records, next, nil
+}
+
+func streamRecord(r streamtypes.Record) (StreamRecord, error) {
+	rec := StreamRecord{EventID: aws.ToString(r.EventID), Type: string(r.EventName)}
+	d := r.Dynamodb
+	if d == nil {
+		return rec, nil
+	}
+	rec.Time = aws.ToTime(d.ApproximateCreationDateTime)
+
+	images := []struct {
+		from map[string]streamtypes.AttributeValue
+		to   *map[string]interface{}
+	}{{d.Keys, &rec.Keys}, {d.OldImage, &rec.OldImage}, {d.NewImage, &rec.NewImage}}
+	for _, img := range images {
+		if img.from == nil {
+			continue
+		}
+		av, err := attributevalue.FromDynamoDBStreamsMap(img.from)
+		if err != nil {
+			return rec, fmt.Errorf("convert stream record: %w", err)
+		}
+		if err := attributevalue.UnmarshalMap(av, img.to); err != nil {
+			return rec, fmt.Errorf("unmarshal stream record: %w", err)
+		}
+	}
+	return rec, nil
+}
+
+// SampleItems reads up to limit items for profiling. With one segment the
+// sample is the start of the table; with more, the limit is spread over a
+// segmented scan so the sample covers the whole key space.
+func (a *AWS) SampleItems(ctx context.Context, tableName string, limit, segments int) ([]map[string]interface{}, Capacity, error) {
+	var used Capacity
+	var items []map[string]interface{}
+	segments = max(segments, 1)
+	perSegment := max(limit/segments, 1)
+
+	for seg := 0; seg < segments; seg++ {
+		input := &dynamodb.ScanInput{
+			TableName:              aws.String(tableName),
+			ReturnConsumedCapacity: types.ReturnConsumedCapacityTotal,
+		}
+		if segments > 1 {
+			input.Segment = aws.Int32(int32(seg))
+			input.TotalSegments = aws.Int32(int32(segments))
+		}
+		read := 0
+		for read < perSegment {
+			input.Limit = aws.Int32(int32(perSegment - read))
+			resp, err := a.Dynamo.Scan(ctx, input)
+			if err != nil {
+				return nil, used, fmt.Errorf("sample scan failed: %w", err)
+			}
+			used.add(resp.ConsumedCapacity, false)
+
+			var page []map[string]interface{}
+			if err := attributevalue.UnmarshalListOfMaps(resp.Items, &page); err != nil {
+				return nil, used, fmt.Errorf("unmarshal items: %w", err)
+			}
+			items = append(items, page...)
+			read += len(page)
+
+			if resp.LastEvaluatedKey == nil {
+				break
+			}
+			input.ExclusiveStartKey = resp.LastEvaluatedKey
+		}
+	}
+	return items, used, nil
+}
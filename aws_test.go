@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// fakeBatchWriter leaves the keys in unprocessed for the first call, then
+// fails every call after it.
+type fakeBatchWriter struct {
+	unprocessed map[string]bool // Values of "id" DynamoDB doesn't get to
+	calls       int
+}
+
+func (f *fakeBatchWriter) BatchWriteItem(ctx context.Context, in *dynamodb.BatchWriteItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error) {
+	f.calls++
+	if f.calls > 1 {
+		return nil, errors.New("throttled")
+	}
+	out := &dynamodb.BatchWriteItemOutput{
+		UnprocessedItems: map[string][]types.WriteRequest{},
+		ConsumedCapacity: []types.ConsumedCapacity{{CapacityUnits: aws.Float64(float64(len(in.RequestItems["t"]) - len(f.unprocessed)))}},
+	}
+	for _, r := range in.RequestItems["t"] {
+		if id := r.DeleteRequest.Key["id"].(*types.AttributeValueMemberS).Value; f.unprocessed[id] {
+			out.UnprocessedItems["t"] = append(out.UnprocessedItems["t"], r)
+		}
+	}
+	return out, nil
+}
+
+func TestBatchDeleteCountsPartialChunk(t *testing.T) {
+	keys := make([]map[string]interface{}, 30)
+	for i := range keys {
+		keys[i] = map[string]interface{}{"id": fmt.Sprintf("k%d", i)}
+	}
+	// DynamoDB processes all but two keys of the first chunk, then the
+	// retry fails
+	db := &fakeBatchWriter{unprocessed: map[string]bool{"k3": true, "k17": true}}
+
+	deleted, used, err := batchDeleteItems(context.Background(), db, "t", keys)
+	if err == nil {
+		t.Fatal("batch delete succeeded, want the retry's error")
+	}
+	if len(deleted) != 23 {
+		t.Fatalf("deleted %d keys, want the 23 DynamoDB processed", len(deleted))
+	}
+	for _, k := range deleted {
+		if k == 3 || k == 17 {
+			t.Errorf("deleted includes unprocessed key %d", k)
+		}
+	}
+	if used.WriteUnits != 23 {
+		t.Errorf("used = %v, want 23 WCU", used)
+	}
+}
+
+func TestBatchDeleteRetriesUnprocessed(t *testing.T) {
+	keys := []map[string]interface{}{{"id": "a"}, {"id": "b"}, {"id": "c"}}
+	db := &retryingBatchWriter{}
+
+	deleted, _, err := batchDeleteItems(context.Background(), db, "t", keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(deleted, []int{0, 1, 2}) || db.calls != 2 {
+		t.Errorf("deleted %v in %d calls, want [0 1 2] in 2", deleted, db.calls)
+	}
+}
+
+// retryingBatchWriter leaves the last key unprocessed once.
+type retryingBatchWriter struct{ calls int }
+
+func (f *retryingBatchWriter) BatchWriteItem(ctx context.Context, in *dynamodb.BatchWriteItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error) {
+	f.calls++
+	reqs := in.RequestItems["t"]
+	if f.calls == 1 {
+		return &dynamodb.BatchWriteItemOutput{UnprocessedItems: map[string][]types.WriteRequest{"t": reqs[len(reqs)-1:]}}, nil
+	}
+	return &dynamodb.BatchWriteItemOutput{}, nil
+}
+
+func TestBatchSqlQueryBooksMixedChunksAsWrites(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+		w.Write([]byte(`{"Responses":[{},{}],"ConsumedCapacity":[{"TableName":"t","CapacityUnits":3}]}`))
+	}))
+	defer srv.Close()
+	api := &AWS{Dynamo: dynamodb.New(dynamodb.Options{
+		Region:       "us-east-1",
+		BaseEndpoint: aws.String(srv.URL),
+		Credentials:  aws.AnonymousCredentials{},
+	})}
+
+	// DynamoDB reports only the combined units for the whole batch
+	_, used, err := api.BatchSqlQuery(context.Background(), []string{
+		`SELECT * FROM "t" WHERE "id" = 'a'`,
+		`UPDATE "t" SET "n" = 1 WHERE "id" = 'a'`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if used.ReadUnits != 0 || used.WriteUnits != 3 {
+		t.Errorf("used = %+v, want 3 write units", used)
+	}
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Backup and restore forms
+const (
+	formBackup  = "backup"
+	formRestore = "restore"
+)
+
+// Fields of the restore form; a point-in-time restore adds the time.
+const (
+	rsTarget = iota
+	rsTime
+)
+
+// restoreTimeLayouts are accepted for a point-in-time restore, in UTC unless
+// the value carries a zone.
+var restoreTimeLayouts = []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02T15:04"}
+
+// parseRestoreTime reads the restore time field. "latest" or an empty value
+// return the zero time, which restores the latest restorable state.
+func parseRestoreTime(s string, window PITRWindow) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || strings.EqualFold(s, "latest") {
+		return time.Time{}, nil
+	}
+	for _, layout := range restoreTimeLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.UTC); err == nil {
+			if !window.Earliest.IsZero() && (t.Before(window.Earliest) || t.After(window.Latest)) {
+				return time.Time{}, fmt.Errorf("%s is outside the restorable window %s → %s",
+					s, formatBackupTime(window.Earliest), formatBackupTime(window.Latest))
+			}
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q is not a time; use latest or YYYY-MM-DD HH:MM:SS (UTC)", s)
+}
+
+func formatBackupTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02 15:04:05")
+}
+
+// openBackups loads the backup list of the table under the cursor.
+func (m *model) openBackups() tea.Cmd {
+	t := m.tables[m.tableCursor]
+	m.loading = true
+	m.view = viewLoading
+	m.statusMessage = fmt.Sprintf("Loading backups of %s...", t.Name)
+	return loadBackupsCmd(m.aws, t.Name)
+}
+
+func newBackupForm(table string) *schemaForm {
+	return &schemaForm{
+		kind:   formBackup,
+		table:  table,
+		title:  "Back up " + table,
+		submit: "create",
+		fields: []formField{
+			textField("Backup name", "", table+"-"+time.Now().UTC().Format("20060102-150405")),
+		},
+	}
+}
+
+// newRestoreForm restores b into a new table, or restores the table to a
+// point in time when b is nil.
+func newRestoreForm(table string, b *Backup, window PITRWindow) *schemaForm {
+	f := &schemaForm{
+		kind:   formRestore,
+		table:  table,
+		submit: "restore",
+		backup: b,
+		fields: []formField{textField("Target table", "", table+"-restored")},
+	}
+	if b != nil {
+		f.title = "Restore backup " + b.Name
+		f.info = []string{"Created " + formatBackupTime(b.Created) + " UTC", b.ARN}
+		return f
+	}
+	f.title = "Restore " + table + " to a point in time"
+	f.info = []string{fmt.Sprintf("Restorable %s → %s UTC", formatBackupTime(window.Earliest), formatBackupTime(window.Latest))}
+	f.fields = append(f.fields, textField("Restore time", "latest, or YYYY-MM-DD HH:MM:SS (UTC)", ""))
+	return f
+}
+
+// submitBackupForm starts the backup or restore described by the form.
+func (m *model) submitBackupForm() tea.Cmd {
+	f := m.schemaForm
+	source := f.table
+	target := f.fields[0].value()
+	if target == "" {
+		f.err = fmt.Errorf("a name is required")
+		return nil
+	}
+
+	if f.kind == formBackup {
+		audit := m.newAuditEntry(auditCreateBackup)
+		audit.Table = source
+		audit.Backup = target
+		m.statusMessage = fmt.Sprintf("Backing up %s...", source)
+		m.schemaForm, m.loading, m.view = nil, true, viewLoading
+		return createBackupCmd(m.aws, source, target, audit)
+	}
+
+	if m.tableIndex(target) >= 0 {
+		f.err = fmt.Errorf("table %s already exists", target)
+		return nil
+	}
+	audit := m.newAuditEntry(auditRestoreTable)
+	audit.Table = target
+	if f.backup != nil {
+		audit.Source = f.backup.ARN
+		m.statusMessage = fmt.Sprintf("Restoring %s into %s...", f.backup.Name, target)
+		m.schemaForm, m.loading, m.view = nil, true, viewLoading
+		return restoreBackupCmd(m.aws, source, f.backup.ARN, target, audit)
+	}
+
+	at, err := parseRestoreTime(f.fields[rsTime].value(), m.backupWindow)
+	if err != nil {
+		f.err = err
+		return nil
+	}
+	audit.Source = source
+	audit.RestoreTime = "latest"
+	if !at.IsZero() {
+		audit.RestoreTime = at.Format(time.RFC3339)
+	}
+	m.statusMessage = fmt.Sprintf("Restoring %s into %s...", source, target)
+	m.schemaForm, m.loading, m.view = nil, true, viewLoading
+	return restorePointInTimeCmd(m.aws, source, target, at, audit)
+}
+
+func (m *model) updateBackups(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	t := m.tables[m.tableCursor]
+	switch msg.String() {
+	case "esc", "q":
+		m.view = viewTableList
+	case "up", "k":
+		m.backupCursor = max(m.backupCursor-1, 0)
+	case "down", "j":
+		m.backupCursor = max(min(m.backupCursor+1, len(m.backups)-1), 0)
+	case "r":
+		return m, m.openBackups()
+	case "n":
+		return m, m.openSchemaForm(newBackupForm(t.Name))
+	case "enter":
+		if m.backupCursor < len(m.backups) {
+			b := m.backups[m.backupCursor]
+			if b.Status != "AVAILABLE" {
+				m.notice = fmt.Sprintf("Backup %s is %s and can't be restored yet", b.Name, b.Status)
+				return m, nil
+			}
+			return m, m.openSchemaForm(newRestoreForm(t.Name, &b, m.backupWindow))
+		}
+	case "p":
+		if !m.backupWindow.Enabled {
+			m.notice = "Point-in-time recovery is off for this table; turn it on in the settings panel (s)"
+			return m, nil
+		}
+		return m, m.openSchemaForm(newRestoreForm(t.Name, nil, m.backupWindow))
+	}
+	return m, nil
+}
+
+// renderBackups lists the backups of the selected table and its
+// point-in-time recovery window.
+func (m model) renderBackups() string {
+	t := m.tables[m.tableCursor]
+	header := m.renderHeader("Backups: " + t.Name)
+	dim := lipgloss.NewStyle().Foreground(textDim)
+
+	pitr := "Point-in-time recovery: off"
+	if m.backupWindow.Enabled {
+		pitr = fmt.Sprintf("Point-in-time recovery: restorable %s → %s UTC (p to restore)",
+			formatBackupTime(m.backupWindow.Earliest), formatBackupTime(m.backupWindow.Latest))
+	}
+
+	row := func(name, created, size, status, typ string) string {
+		return fmt.Sprintf("%-40s %-20s %10s  %-10s %s", truncateText(name, 40), created, size, status, typ)
+	}
+	lines := []string{
+		dim.Render(pitr),
+		"",
+		listHeaderStyle.Width(m.width - 4).Render(row("NAME", "CREATED (UTC)", "SIZE", "STATUS", "TYPE")),
+	}
+	if len(m.backups) == 0 {
+		lines = append(lines, itemRowStyle.Render("No on-demand backups. Press n to create one."))
+	}
+	start, end := windowRange(len(m.backups), m.backupCursor, max(m.height-12, 1))
+	for i := start; i < end; i++ {
+		b := m.backups[i]
+		line := row(b.Name, formatBackupTime(b.Created), formatBytes(b.SizeBytes), b.Status, b.Type)
+		if i == m.backupCursor {
+			lines = append(lines, listSelectedStyle.Width(m.width-4).Render(line))
+		} else {
+			lines = append(lines, listItemStyle.Render(line))
+		}
+	}
+	lines = append(lines, "", dim.Render("n new backup · enter restore into a new table · p point-in-time restore · r refresh · esc back"))
+
+	return lipgloss.JoinVertical(lipgloss.Left, header, m.renderTabs(), lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
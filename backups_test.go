@@ -0,0 +1,55 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseRestoreTime(t *testing.T) {
+	window := PITRWindow{
+		Enabled:  true,
+		Earliest: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+		Latest:   time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC),
+	}
+
+	for _, s := range []string{"", " latest ", "LATEST"} {
+		if at, err := parseRestoreTime(s, window); err != nil || !at.IsZero() {
+			t.Errorf("parseRestoreTime(%q) = %v, %v; want the zero time", s, at, err)
+		}
+	}
+
+	want := time.Date(2026, 10, 10, 8, 30, 0, 0, time.UTC)
+	for _, s := range []string{"2026-10-10 08:30", "2026-10-10 08:30:00", "2026-10-10T08:30", "2026-10-10T10:30:00+02:00"} {
+		at, err := parseRestoreTime(s, window)
+		if err != nil || !at.Equal(want) {
+			t.Errorf("parseRestoreTime(%q) = %v, %v; want %v", s, at, err, want)
+		}
+	}
+
+	for _, s := range []string{"2026-09-30 23:59", "2026-10-19 00:00", "yesterday"} {
+		if _, err := parseRestoreTime(s, window); err == nil {
+			t.Errorf("parseRestoreTime(%q): expected an error", s)
+		}
+	}
+}
+
+func TestBackupFormKeepsItsTable(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	api, called := fakeDynamo(t)
+	m := initialModel(api)
+	m.tables = []Table{{Name: "orders"}, {Name: "users"}}
+	m.openSchemaForm(newBackupForm("orders"))
+
+	m.tableCursor = 1 // The list moved under the open form
+	cmd := m.submitSchemaForm()
+	if cmd == nil {
+		t.Fatalf("submit returned no command: %v", m.schemaForm.err)
+	}
+	if want := "Backing up orders..."; m.statusMessage != want {
+		t.Errorf("status = %q, want %q", m.statusMessage, want)
+	}
+	if audited := lastAudited(t, cmd); audited != "orders" || !reflect.DeepEqual(*called, []string{"orders"}) {
+		t.Errorf("called %v, audited %q; want orders", *called, audited)
+	}
+}
@@ -52,6 +52,7 @@ type PlanBlock struct {
 	Read      ReadBlock  `json:"read"`
 	Write     *WriteBlock `json:"write"`
 	Safety    SafetyBlock `json:"safety"`
+	Aggregate *AggregateBlock `json:"aggregate"` // Operation "aggregate" only
 }
 
 type ReadBlock struct {
@@ -62,9 +63,10 @@ type ReadBlock struct {
 }
 
 type WriteBlock struct {
-	Action  string `json:"action"`
+	Action  string `json:"action"` // update, delete, or compute for client-side computed values
 	PerItem struct {
-		PartiqlTemplate string `json:"partiql_template"`
+		PartiqlTemplate string        `json:"partiql_template"`
+		Set             []ComputedSet `json:"set"` // Compute only
 	} `json:"per_item"`
 }
 
@@ -73,7 +75,18 @@ type SafetyBlock struct {
 	Reason            string `json:"reason"`
 }
 
-func (a *AWS) InvokeBedrock(ctx context.Context, question string, table Table) (LLMResult, error) {
+// PromptContext is what the model is told besides the table's keys.
+type PromptContext struct {
+	Profile    *TableProfile      // Cached attribute statistics; nil when never profiled
+	Loaded     int                // Items of the table loaded in the active tab
+	LoadedFrom string             // What returned them: a table scan or the query's statements
+	History    []conversationTurn // Earlier questions on this table in the active tab
+}
+
+// InvokeBedrock turns a question into an execution plan. A profile of the
+// table, when there is one, lists the non-key attributes for the model, and
+// the loaded items let it aggregate what is already on screen.
+func (a *AWS) InvokeBedrock(ctx context.Context, question string, table Table, pc PromptContext) (LLMResult, error) {
 	log.Printf("InvokeBedrock called with question: '%s' for table: '%s'", question, table.Name)
 
 	// Construct schema description
@@ -87,6 +100,16 @@ func (a *AWS) InvokeBedrock(ctx context.Context, question string, table Table) (
 	if len(table.GSIs) > 0 {
 		schemaDesc += fmt.Sprintf("Global Secondary Indexes: %v\n", table.GSIs)
 	}
+
+	schemaNote := "NOTE: The schema above only lists keys and indexes. The table contains other attributes not listed here. Do not refuse a query just because an attribute is not in this schema."
+	if pc.Profile != nil {
+		schemaDesc += pc.Profile.promptText()
+		schemaNote = "NOTE: The attributes above come from a sample, so rare attributes may be missing. Prefer the listed attribute names and match their types and value formats (use the examples), but do not refuse a query just because an attribute is not listed."
+	}
+	loadedDesc := "Loaded items: none. Aggregates must use source=\"read\"."
+	if pc.Loaded > 0 {
+		loadedDesc = fmt.Sprintf("Loaded items: %d items returned by %s.", pc.Loaded, pc.LoadedFrom)
+	}
 	prompt := fmt.Sprintf(`
 You are a DynamoDB expert. Your job is to produce a SAFE execution plan for DynamoDB.
 
@@ -95,6 +118,8 @@ This tool is a DynamoDB Manager. It supports:
 1. SQL MODE: Simple, efficient Single-Item Reads/Writes using PartiQL.
 2. PLAN MODE (READ): Complex Scans or Queries that return multiple items.
 3. PLAN MODE (WRITE): "Fetch-then-Mutate" operations for multi-item updates.
+3b. PLAN MODE (COMPUTE): "Fetch-then-Compute" updates whose new values depend on each item (math, string transforms, timestamps, random values, conditionals). An expression per attribute is evaluated client-side for every item found.
+4. PLAN MODE (AGGREGATE): COUNT, SUM, AVG, MIN, MAX and GROUP BY, computed client-side over the items the user has loaded or over every page of a read.
 
 CRITICAL RULES:
 - NEVER invent a write operation if the user did not ask for one.
@@ -102,17 +127,19 @@ CRITICAL RULES:
 - Only use "Fetch-then-Mutate" (write block) if the user explicitly asks to UPDATE, DELETE, or MODIFY data.
 
 1. READ: It runs a SELECT query to find items.
-2. WRITE: It applies a *static* PartiQL template to every item found.
+2. WRITE: It applies a *static* PartiQL template to every item found (action "update" or "delete"), or evaluates per-item expressions (action "compute").
 3. INSERTs must be fully specified SQL statements (Mode: sql). You cannot use a Plan to generate new items.
-It CANNOT generate unique values client-side during a PLAN. However, for INSERT (Mode: sql), YOU (the AI) can and should generate the random/dummy data yourself.
-Therefore, any request for "random" or "unique" values in an UPDATE/DELETE plan is IMPOSSIBLE and MUST be refused.
+For INSERT (Mode: sql), YOU (the AI) can and should generate the random/dummy data yourself. For UPDATEs that need a different value per item, use action "compute".
 
 Return EXACTLY ONE valid JSON object and nothing else (no markdown, no backticks, no explanations).
 
 INPUTS
 Schema (includes table name, PK/SK, GSIs):
 %s
-NOTE: The schema above only lists keys and indexes. The table contains other attributes not listed here. Do not refuse a query just because an attribute is not in this schema.
+%s
+%s
+
+%s
 
 User request:
 %s
@@ -120,13 +147,13 @@ User request:
 OUTPUT JSON SCHEMA
 {
   "mode": "sql" | "plan" | "refusal",
-  "reason": "Brief explanation of why this mode was chosen",
+  "reason": "One or two plain sentences, shown to the user before anything runs: how you read the request and why you chose this mode",
   "statements": ["<PartiQL>"],
   "refusal_reason": "<string if mode=refusal>",
 
   "plan": {
     "table": "<table name>",
-    "operation": "select" | "scan_then_write",
+    "operation": "select" | "scan_then_write" | "aggregate",
     "read": {
       "partiql": "<PartiQL SELECT>",
       "requires_scan": true | false,
@@ -134,15 +161,21 @@ OUTPUT JSON SCHEMA
       "projection": ["<PK name>", "<SK name>"] | ["*"]
     },
     "write": null | {
-      "action": "update" | "delete",
+      "action": "update" | "delete" | "compute",
       "per_item": {
         "partiql_template":
-          "<Key-bounded PartiQL UPDATE/DELETE with placeholders {{PK}} and {{SK}} if SK exists>"
+          "<Key-bounded PartiQL UPDATE/DELETE with placeholders {{PK}} and {{SK}} if SK exists; empty for compute>",
+        "set": [{"path": "<attribute path>", "expr": "<expression>"}] (compute only)
       }
     },
     "safety": {
       "needs_confirmation": true | false,
       "reason": "none" | "full_table_scan" | "multi_item_write"
+    },
+    "aggregate": null | {
+      "source": "loaded" | "read",
+      "group_by": ["<attribute path>"],
+      "metrics": [{"func": "count" | "sum" | "avg" | "min" | "max", "path": "<attribute path>" | "*"}]
     }
   }
 }
@@ -158,15 +191,25 @@ Result: {"mode": "sql", "statements": ["UPDATE \"Users\" SET \"status\"='active'
 INCORRECT UPDATE (Wrong Placeholders): "partiql_template": "UPDATE \"Users\" SET \"status\"='active' WHERE \"id\"={{id}}"
 CORRECT UPDATE (Plan Mode): "partiql_template": "UPDATE \"Users\" SET \"status\"='active' WHERE \"id\"={{PK}}"
 
+CORRECT AGGREGATE (Plan Mode):
+Request: "What is the average age of users per country?"
+Result: {"mode": "plan", "plan": {"table": "Users", "operation": "aggregate", "read": {"partiql": "SELECT \"country\", \"age\" FROM \"Users\"", "requires_scan": true, "index": null, "projection": ["country", "age"]}, "write": null, "safety": {"needs_confirmation": true, "reason": "full_table_scan"}, "aggregate": {"source": "read", "group_by": ["country"], "metrics": [{"func": "count", "path": "*"}, {"func": "avg", "path": "age"}]}}}
+Request: "How many of these are there per status?" (items are loaded)
+Result: {"mode": "plan", "plan": {"table": "Users", "operation": "aggregate", "read": {"partiql": "", "requires_scan": false, "index": null, "projection": []}, "write": null, "safety": {"needs_confirmation": false, "reason": "none"}, "aggregate": {"source": "loaded", "group_by": ["status"], "metrics": [{"func": "count", "path": "*"}]}}}
+
+CORRECT COMPUTED UPDATE (Plan Mode, action "compute"):
+Request: "Increase price by 10 for all items in category 'books'"
+Result: {"mode": "plan", "plan": {"table": "Items", "operation": "scan_then_write", "read": {"partiql": "SELECT * FROM \"Items\" WHERE \"category\"='books'", "requires_scan": true, "index": null, "projection": ["*"]}, "write": {"action": "compute", "per_item": {"partiql_template": "", "set": [{"path": "price", "expr": "price + 10"}]}}, "safety": {"needs_confirmation": true, "reason": "full_table_scan"}, "aggregate": null}}
+More "set" entries:
+- "Uppercase all usernames" -> {"path": "username", "expr": "upper(username)"}
+- "Set fullName to firstName + lastName" -> {"path": "fullName", "expr": "firstName || ' ' || lastName"}
+- "Set updated_at to now" -> {"path": "updated_at", "expr": "now()"}
+- "Give everyone a random password" -> {"path": "password", "expr": "random_string(16)"}
+- "Mark adults" -> {"path": "group", "expr": "if(age >= 18, 'adult', 'minor')"}
+
 REFUSAL EXAMPLES
-Request: "What is the average age of users?" -> {"mode": "refusal", "refusal_reason": "Aggregations like AVG are not supported."}
 Request: "Join Users and Orders tables" -> {"mode": "refusal", "refusal_reason": "Joins/Unions involving multiple tables are not supported."}
-Request: "Update all users to have random passwords" -> {"mode": "refusal", "refusal_reason": "Dynamic value generation (random) is not supported for updates."}
-Request: "Double the points for everyone" -> {"mode": "refusal", "refusal_reason": "Math operations on attributes (points = points * 2) are not supported."}
-Request: "Set fullName to firstName + lastName" -> {"mode": "refusal", "refusal_reason": "String concatenation is not supported."}
-Request: "Uppercase all usernames" -> {"mode": "refusal", "refusal_reason": "String transformation functions like UPPER() or LOWER() are not supported."}
-Request: "Increase price by 10 for all items" -> {"mode": "refusal", "refusal_reason": "Mathematical updates (price = price + 10) are not supported in PartiQL."}
-Request: "Set updated_at to current time" -> {"mode": "refusal", "refusal_reason": "Built-in timestamp functions like NOW() are not supported."}
+Request: "Create a table called Orders" -> {"mode": "refusal", "refusal_reason": "Schema changes are not supported here; use the table list (n creates a table)."}
 
 STRICT DYNAMODB RULES
 - UPDATE and DELETE must uniquely identify items using the FULL primary key.
@@ -179,21 +222,24 @@ STRICT DYNAMODB RULES
 
 LIMITATIONS (REFUSAL CRITERIA)
 If the user requests any of the following, return mode="refusal" with a helpful refusal_reason:
-1. Data Transformation: Operations like UPPER(), LOWER(), Concatenation, or math on attributes (e.g. "Double the price").
-2. Aggregations: COUNT, AVG, SUM, MAX, MIN, GROUP BY.
-3. DDL/Schema: CREATE, ALTER, DROP TABLE/INDEX.
-4. Joins/Unions: Operations involving multiple tables.
-5. Dynamic Value Generation: Requests asking to generate random values, UUIDs, or timestamps for EACH item during an update (e.g., "Set random password for everyone").
+1. DDL/Schema: CREATE, ALTER, DROP TABLE/INDEX.
+2. Joins/Unions: Operations involving multiple tables.
+3. Changing key attributes (PK or SK) of existing items.
 NOTE: Inefficient queries (Full Table Scans) are ALLOWED. Do not refuse them.
 
 DECISION RULES
 - If mode="refusal", set other fields to null/empty.
+- If the request is a follow-up to the conversation ("now only...", "of those", "same but sorted by...", "and for last week?"), build on the most recent generated query: keep its table, filters and mode, and add or change only what the new request asks for. If the request stands on its own, ignore the conversation.
 - For INSERT requests asking for random/dummy/sequential data, YOU (the AI) must generate the specific static values yourself and return them as a list of SQL statements in mode='sql'. Do NOT refuse. Do NOT use placeholders.
-- CRITICAL: If the user asks for random/dynamic values in an UPDATE/DELETE (e.g. "set random password"), return mode="refusal". Do NOT attempt to use {{random}} placeholders.
+- CRITICAL: If an UPDATE needs values computed from each item or generated per item (math, UPPER/LOWER, concatenation, now, uuid, random, if/else), return operation="scan_then_write" with write.action="compute". Do NOT put such logic into partiql_template and do NOT use {{random}} placeholders.
+- This applies even when the full key is given: a computed update of a single item is a plan whose read selects that item.
 - For INSERT operations (creating new items), ALWAYS use mode="sql" with fully specified statements. NEVER use a plan or templates for INSERT.
 - When mode='sql', statements MUST NOT contain ANY placeholders like {{...}}. You must generate actual values (random or specific).
 - If the request is a SELECT, UPDATE, or DELETE that uniquely identifies a SINGLE item using the FULL Primary Key (PK for simple tables; BOTH PK and SK for composite tables), return mode="sql".
 - If a SELECT uses a partial key (e.g. only PK on a PK+SK table) or non-key attributes, it may return MULTIPLE items; return mode="plan" with operation="select".
+- For COUNT, SUM, AVG, MIN, MAX or GROUP BY questions, return mode="plan" with operation="aggregate" and write=null. NEVER put COUNT(), SUM() or GROUP BY into PartiQL; DynamoDB does not support them.
+- Use aggregate.source="loaded" only when items are loaded and the user refers to them ("these", "the results", "loaded items"); leave read.partiql empty. Otherwise use source="read" with a SELECT in read.partiql that returns every item to aggregate, filtered as the user asked; every page of it is read.
+- Aggregate paths use dots for nested maps ("address.city") and [] for every element of a list ("lines[].price"). Use "*" as the path to count items.
 
 PARTIQL RULES
 - Double quotes for table/attribute names.
@@ -206,9 +252,83 @@ PARTIQL RULES
 - partiql_template must ONLY contain {{PK}} and {{SK}} placeholders. Do NOT use placeholders like {{id}}, {{random}}, etc.
 - Check list/string content: contains("tags", 'urgent'), begins_with("attr",'A')
 
+COMPUTE EXPRESSION RULES (write.per_item.set[].expr, NOT PartiQL)
+- Attributes by bare name or path: price, address.city, tags[0]; "double quotes" for names with spaces. A missing attribute is null.
+- Literals: 10, 2.5, 'text', true, false, null.
+- Operators: + - * / %%, || joins strings, = != < <= > >=, and, or, not.
+- Functions: upper(s), lower(s), trim(s), length(x), concat(a, b, ...), replace(s, old, new), substr(s, start, len), string(x), number(s), round(x, digits), floor(x), ceil(x), abs(x), min(a, b), max(a, b), now() (ISO 8601 UTC), epoch() (Unix seconds), uuid(), random_string(length), random_int(low, high), if(cond, then, else), coalesce(a, b, ...), exists(attr).
+- Every expression sees the item as it was read. Key attributes cannot be set.
+
 Return ONLY the JSON object.
-`, schemaDesc, question)
+`, schemaDesc, schemaNote, loadedDesc, conversationPrompt(pc.History), question)
+
+	rawText, err := a.invokeModel(ctx, prompt, 5000) // Near the max of 5,120 for Nova Lite
+	if err != nil {
+		return LLMResult{}, err
+	}
+	rawText = strings.TrimSpace(rawText)
+
+	// If the model ever wraps output in fences, strip them
+	rawText = strings.TrimPrefix(rawText, "```json")
+	rawText = strings.TrimPrefix(rawText, "```")
+	rawText = strings.TrimSuffix(rawText, "```")
+	rawText = strings.TrimSpace(rawText)
+
+	log.Printf("Raw LLM Response: %s", rawText)
+
+	var result LLMResult
+	if err := json.Unmarshal([]byte(rawText), &result); err != nil {
+		return LLMResult{}, fmt.Errorf("LLM JSON parse failed: %w; raw=%q", err, rawText)
+	}
+
+	log.Printf("Parsed Statements (Before Cleaning): %q", result.Statements)
+
+	if err := checkResult(&result, table); err != nil {
+		return LLMResult{}, err
+	}
+
+	log.Printf("Final Statements: %q", result.Statements)
 
+	return result, nil
+}
+
+// ExplainItems asks for a plain-English summary of a sample of the items a
+// query returned. Only the sample is sent; total says how many there were.
+func (a *AWS) ExplainItems(ctx context.Context, table Table, source, question, sample string, sent, total int) (string, error) {
+	log.Printf("ExplainItems called for table '%s' with %d of %d items", table.Name, sent, total)
+
+	keys := "Partition key: " + table.PK
+	if table.SK != "" {
+		keys += ", sort key: " + table.SK
+	}
+	asked := ""
+	if question != "" {
+		asked = fmt.Sprintf("The user asked: %q\n", question)
+	}
+	prompt := fmt.Sprintf(`
+You help a user of a DynamoDB console understand the items on their screen.
+
+Table: %s (%s)
+The items were returned by: %s
+%sThe result has %d items. These %d were sent to you, one JSON object per line:
+%s
+Write a short plain-English summary for the user, at most 8 sentences or "- " bullet points:
+- what the items are and what they have in common
+- the range or most common values of the attributes that matter, and any outliers
+- attributes that only some items have
+Only state what the items above show. If you were sent fewer items than the result has, say when a conclusion may not hold for the rest. Never invent attributes or values. Plain text only: no markdown headings, tables or code.
+`, table.Name, keys, source, asked, total, sent, sample)
+
+	text, err := a.invokeModel(ctx, prompt, 1000)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(text), nil
+}
+
+// invokeModel sends one prompt to Nova Lite and returns the text it answers
+// with. Temperature 0 keeps answers repeatable.
+func (a *AWS) invokeModel(ctx context.Context, prompt string, maxTokens int) (string, error) {
 	body := NovaRequest{
 		Messages: []NovaMessage{
 			{
@@ -218,18 +338,12 @@ Return ONLY the JSON object.
 				},
 			},
 		},
-		InferenceConfig: struct {
-			MaxNewTokens int     `json:"max_new_tokens"`
-			Temperature  float64 `json:"temperature"`
-		}{
-			MaxNewTokens: 5000, // Increased to 5000 (near max of 5,120 for Nova Lite)
-			Temperature:  0,
-		},
 	}
+	body.InferenceConfig.MaxNewTokens = maxTokens
 
 	payload, err := json.Marshal(body)
 	if err != nil {
-		return LLMResult{}, fmt.Errorf("marshal request: %w", err)
+		return "", fmt.Errorf("marshal request: %w", err)
 	}
 
 	resp, err := a.Bedrock.InvokeModel(ctx, &bedrockruntime.InvokeModelInput{
@@ -238,35 +352,23 @@ Return ONLY the JSON object.
 		Body:        payload,
 	})
 	if err != nil {
-		return LLMResult{}, fmt.Errorf("invoke model: %w", err)
+		return "", fmt.Errorf("invoke model: %w", err)
 	}
 
 	var response NovaResponse
 	if err := json.Unmarshal(resp.Body, &response); err != nil {
-		return LLMResult{}, fmt.Errorf("unmarshal response: %w", err)
+		return "", fmt.Errorf("unmarshal response: %w", err)
 	}
 
 	if len(response.Output.Message.Content) == 0 {
-		return LLMResult{}, fmt.Errorf("empty response from model")
+		return "", fmt.Errorf("empty response from model")
 	}
+	return response.Output.Message.Content[0].Text, nil
+}
 
-	rawText := strings.TrimSpace(response.Output.Message.Content[0].Text)
-
-	// If the model ever wraps output in fences, strip them
-	rawText = strings.TrimPrefix(rawText, "```json")
-	rawText = strings.TrimPrefix(rawText, "```")
-	rawText = strings.TrimSuffix(rawText, "```")
-	rawText = strings.TrimSpace(rawText)
-
-	log.Printf("Raw LLM Response: %s", rawText)
-
-	var result LLMResult
-	if err := json.Unmarshal([]byte(rawText), &result); err != nil {
-		return LLMResult{}, fmt.Errorf("LLM JSON parse failed: %w; raw=%q", err, rawText)
-	}
-
-	log.Printf("Parsed Statements (Before Cleaning): %q", result.Statements)
-
+// checkResult drops empty statements and rejects a result that can't be run
+// on t. Statements edited by hand before running go through the same checks.
+func checkResult(result *LLMResult, t Table) error {
 	// Filter empty statements to avoid DynamoDB ValidationException
 	if len(result.Statements) > 0 {
 		var cleanStmts []string
@@ -277,21 +379,44 @@ Return ONLY the JSON object.
 		}
 		result.Statements = cleanStmts
 	}
-	
-	log.Printf("Final Statements: %q", result.Statements)
 
 	// validations
 	if result.Mode == "sql" && len(result.Statements) == 0 {
-		return LLMResult{}, errors.New("mode=sql but no statements returned")
+		return errors.New("mode=sql but no statements returned")
 	}
 	if result.Mode == "plan" && result.Plan == nil {
-		return LLMResult{}, errors.New("mode=plan but plan is null")
+		return errors.New("mode=plan but plan is null")
 	}
-	if result.Plan != nil && result.Plan.Operation == "scan_then_write" && result.Plan.Write == nil {
-		return LLMResult{}, errors.New("scan_then_write requires write block")
+	p := result.Plan
+	if result.Mode != "plan" || p == nil {
+		return nil
 	}
-
-	return result, nil
+	if p.Operation == "scan_then_write" && p.Write == nil {
+		return errors.New("scan_then_write requires write block")
+	}
+	if !(p.Aggregate != nil && p.Aggregate.Source == aggregateLoaded) {
+		read := strings.ToUpper(strings.TrimSpace(p.Read.Partiql))
+		if !strings.HasPrefix(read, "SELECT") {
+			return errors.New("the plan's read must be a SELECT statement")
+		}
+	}
+	if p.Write == nil {
+		return nil
+	}
+	switch p.Write.Action {
+	case "compute":
+	case "update", "delete":
+		tpl := p.Write.PerItem.PartiqlTemplate
+		if !strings.Contains(tpl, "{{PK}}") {
+			return errors.New("the write template is missing the {{PK}} placeholder")
+		}
+		if t.SK != "" && !strings.Contains(tpl, "{{SK}}") {
+			return errors.New("the write template is missing the {{SK}} placeholder for the sort key")
+		}
+	default:
+		return fmt.Errorf("unknown write action %q", p.Write.Action)
+	}
+	return nil
 }
 
 func FormatPartiQLValue(v any) (string, error) {
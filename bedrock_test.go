@@ -31,9 +31,9 @@ func TestBedrockPrompts(t *testing.T) {
 		//{"Add 3 items: {id: 1, age: 20, name: 'a'}, {id: 2, age: 21, name: 'b'}, {id: 3, age: 22, name: 'c'}", "sql"},
 		//
 		// // Unsupported - Refusals
-		//{"Set a random password for everyone", "refusal"},
-		//{"What is the average age?", "refusal"},
-		// {"Uppercase all usernames", "refusal"},
+		//{"Set a random password for everyone", "plan"},
+		//{"What is the average age?", "plan"},
+		// {"Uppercase all usernames", "plan"},
 		// {"Join users with orders", "refusal"},
 		// {"Create a new table", "refusal"},
 	}
@@ -64,7 +64,7 @@ func TestBedrockPrompts(t *testing.T) {
 	for _, cas := range cases {
 		fmt.Printf("QUERY: %s\n", cas.question)
 
-		result, err := api.InvokeBedrock(ctx, cas.question, tbl)
+		result, err := api.InvokeBedrock(ctx, cas.question, tbl, PromptContext{})
 		if err != nil {
 			t.Errorf("InvokeBedrock failed for '%s': %v", cas.question, err)
 			continue
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Value types offered by the inline cell editor, in the order tab cycles them.
+const (
+	editString = iota
+	editNumber
+	editBool
+	editNull
+	editKinds
+)
+
+var editKindNames = [editKinds]string{"String", "Number", "Boolean", "Null"}
+
+// cellEditor is the in-grid editor for a single scalar attribute.
+type cellEditor struct {
+	attr    string
+	kind    int
+	input   textinput.Model // String and Number
+	boolVal bool            // Boolean
+}
+
+// editKindOf picks the widget for an existing value. It reports false for
+// maps, lists, sets and binary, which still go through the external editor.
+func editKindOf(v interface{}) (int, bool) {
+	switch v.(type) {
+	case nil:
+		return editNull, true
+	case string:
+		return editString, true
+	case bool:
+		return editBool, true
+	case float64, float32, int, int32, int64:
+		return editNumber, true
+	}
+	return 0, false
+}
+
+// startCellEdit opens the editor on the grid cell under the cursor. Missing
+// attributes start as an empty string.
+func (m *model) startCellEdit() tea.Cmd {
+	if len(m.visibleItems()) == 0 {
+		return nil
+	}
+	t := m.tables[m.tableCursor]
+	attr := m.gridAttr()
+	if (attr == t.PK || attr == t.SK) && !m.newItems[m.itemCursor] {
+		m.notice = "Key attributes can't be edited in place; use e to copy the item under a new key"
+		return nil
+	}
+
+	v, present := m.items[m.itemCursor][attr]
+	kind := editString
+	if present {
+		k, ok := editKindOf(v)
+		if !ok {
+			m.notice = fmt.Sprintf("%s is a nested value; use e to edit it", attr)
+			return nil
+		}
+		kind = k
+	}
+
+	ti := textinput.New()
+	ti.Prompt = "❯ "
+	ti.CharLimit = 0
+	ti.Width = max(10, m.width/3)
+	if kind == editString || kind == editNumber {
+		ti.SetValue(cellText(v))
+	}
+	ti.Focus()
+
+	b, _ := v.(bool)
+	m.cellEdit = &cellEditor{attr: attr, kind: kind, input: ti, boolVal: b}
+	return textinput.Blink
+}
+
+// value parses the editor contents into the Go value stored in the item.
+func (e *cellEditor) value() (interface{}, error) {
+	switch e.kind {
+	case editNumber:
+		s := strings.TrimSpace(e.input.Value())
+		n, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number", s)
+		}
+		return n, nil
+	case editBool:
+		return e.boolVal, nil
+	case editNull:
+		return nil, nil
+	}
+	return e.input.Value(), nil
+}
+
+func (m *model) updateCellEdit(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	e := m.cellEdit
+	switch msg.String() {
+	case "esc":
+		m.cellEdit = nil
+		return m, nil
+	case "tab", "shift+tab":
+		step := 1
+		if msg.String() == "shift+tab" {
+			step = editKinds - 1
+		}
+		e.kind = (e.kind + step) % editKinds
+		return m, nil
+	case "enter":
+		v, err := e.value()
+		if err != nil {
+			m.notice = err.Error()
+			return m, nil
+		}
+		m.applyCellEdit(e.attr, v)
+		m.cellEdit = nil
+		return m, nil
+	}
+
+	switch e.kind {
+	case editBool:
+		switch msg.String() {
+		case " ", "left", "right", "h", "l":
+			e.boolVal = !e.boolVal
+		case "t", "y":
+			e.boolVal = true
+		case "f", "n":
+			e.boolVal = false
+		}
+		return m, nil
+	case editNull:
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	e.input, cmd = e.input.Update(msg)
+	return m, cmd
+}
+
+// applyCellEdit stores an edited value on the selected item and records the
+// attribute so that saving sends a targeted update instead of a full put.
+func (m *model) applyCellEdit(attr string, v interface{}) {
+	idx := m.itemCursor
+	m.items[idx][attr] = v
+	// Items already changed in the external editor keep needing a full put
+	if !m.newItems[idx] && (!m.modifiedItems[idx] || len(m.editedAttrs[idx]) > 0) {
+		if m.editedAttrs[idx] == nil {
+			m.editedAttrs[idx] = make(map[string]bool)
+		}
+		m.editedAttrs[idx][attr] = true
+	}
+	m.modifiedItems[idx] = true
+	m.notice = fmt.Sprintf("Edited %s (press s to save)", attr)
+	m.updateViewport()
+}
+
+// pendingUpdate returns the attributes to SET when the selected item only has
+// inline edits. New items and items changed in the external editor are saved
+// with a full put, so ok is false for them.
+func (m *model) pendingUpdate() (set map[string]interface{}, ok bool) {
+	idx := m.itemCursor
+	attrs := m.editedAttrs[idx]
+	if len(attrs) == 0 || m.newItems[idx] {
+		return nil, false
+	}
+	set = make(map[string]interface{}, len(attrs))
+	for attr := range attrs {
+		set[attr] = m.items[idx][attr]
+	}
+	return set, true
+}
+
+// editKindLabel renders the type picker of the edit bar with the current
+// type highlighted.
+func (e *cellEditor) editKindLabel(on, off func(...string) string) string {
+	parts := make([]string, editKinds)
+	for k, name := range editKindNames {
+		if k == e.kind {
+			parts[k] = on(name)
+		} else {
+			parts[k] = off(name)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// editField renders the input widget for the current type.
+func (e *cellEditor) editField() string {
+	switch e.kind {
+	case editBool:
+		if e.boolVal {
+			return "❯ [true]  false "
+		}
+		return "❯  true  [false]"
+	case editNull:
+		return "❯ NULL"
+	}
+	return e.input.View()
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/textinput"
+)
+
+func TestEditKindOf(t *testing.T) {
+	cases := []struct {
+		v    interface{}
+		kind int
+		ok   bool
+	}{
+		{nil, editNull, true},
+		{"s", editString, true},
+		{true, editBool, true},
+		{1.5, editNumber, true},
+		{int64(3), editNumber, true},
+		{map[string]interface{}{}, 0, false},
+		{[]interface{}{}, 0, false},
+		{[]string{"a"}, 0, false},
+		{[]byte("b"), 0, false},
+	}
+	for _, c := range cases {
+		kind, ok := editKindOf(c.v)
+		if kind != c.kind || ok != c.ok {
+			t.Errorf("editKindOf(%#v) = %d, %v; want %d, %v", c.v, kind, ok, c.kind, c.ok)
+		}
+	}
+}
+
+func TestCellEditorValue(t *testing.T) {
+	cases := []struct {
+		kind    int
+		text    string
+		boolVal bool
+		want    interface{}
+		wantErr bool
+	}{
+		{editString, " 42 ", false, " 42 ", false}, // Strings are kept as typed
+		{editNumber, " 42 ", false, 42.0, false},
+		{editNumber, "-1.5e3", false, -1500.0, false},
+		{editNumber, "forty", false, nil, true},
+		{editNumber, "", false, nil, true},
+		{editBool, "ignored", true, true, false},
+		{editNull, "ignored", false, nil, false},
+	}
+	for _, c := range cases {
+		ti := textinput.New()
+		ti.SetValue(c.text)
+		e := cellEditor{kind: c.kind, input: ti, boolVal: c.boolVal}
+		got, err := e.value()
+		if (err != nil) != c.wantErr || got != c.want {
+			t.Errorf("%s %q: value() = %#v, %v; want %#v (error %v)", editKindNames[c.kind], c.text, got, err, c.want, c.wantErr)
+		}
+	}
+}
+
+func TestPendingUpdate(t *testing.T) {
+	s := newSession()
+	s.items = []Item{{"id": "a", "n": 1.0, "s": "x"}, {"id": "b"}}
+	m := model{tables: []Table{{Name: "t", PK: "id"}}, session: s}
+
+	m.applyCellEdit("n", 2.0)
+	m.applyCellEdit("s", nil)
+	set, ok := m.pendingUpdate()
+	if want := map[string]interface{}{"n": 2.0, "s": nil}; !ok || !reflect.DeepEqual(set, want) {
+		t.Errorf("pendingUpdate = %v, %v; want %v, true", set, ok, want)
+	}
+
+	// An item changed in the external editor still needs a full put
+	s.itemCursor = 1
+	s.modifiedItems[1] = true
+	m.applyCellEdit("n", 3.0)
+	if _, ok := m.pendingUpdate(); ok {
+		t.Error("pendingUpdate on an externally edited item = true, want a full put")
+	}
+
+	// So does a new item
+	s.items = append(s.items, Item{"id": "c"})
+	s.itemCursor = 2
+	s.newItems[2] = true
+	m.applyCellEdit("n", 4.0)
+	if _, ok := m.pendingUpdate(); ok {
+		t.Error("pendingUpdate on a new item = true, want a full put")
+	}
+}
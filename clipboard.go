@@ -0,0 +1,55 @@
+package main
+
+import (
+	"io"
+	"os"
+
+	"github.com/atotto/clipboard"
+	"github.com/aymanbagabas/go-osc52/v2"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// overSSH reports whether the session runs on a remote host, where the native
+// clipboard belongs to the wrong machine.
+func overSSH() bool {
+	return os.Getenv("SSH_TTY") != "" || os.Getenv("SSH_CONNECTION") != ""
+}
+
+// osc52Copy writes an OSC52 escape sequence, which the local terminal turns
+// into a clipboard write. It runs through tea.Exec, so the sequence goes to
+// the program's output while the renderer is paused rather than racing a
+// frame.
+type osc52Copy struct {
+	text string
+	out  io.Writer
+}
+
+func (c *osc52Copy) Run() error {
+	seq := osc52.New(c.text)
+	switch {
+	case os.Getenv("TMUX") != "":
+		seq = seq.Tmux()
+	case os.Getenv("STY") != "":
+		seq = seq.Screen()
+	}
+	_, err := seq.WriteTo(c.out)
+	return err
+}
+
+func (c *osc52Copy) SetStdin(io.Reader)    {}
+func (c *osc52Copy) SetStdout(w io.Writer) { c.out = w }
+func (c *osc52Copy) SetStderr(io.Writer)   {}
+
+// copyCmd puts text on the clipboard: with OSC52 over SSH, so the local
+// terminal receives it, and with the native clipboard otherwise. what
+// describes the copied value in the status bar.
+func copyCmd(text, what string) tea.Cmd {
+	if overSSH() {
+		return tea.Exec(&osc52Copy{text: text}, func(err error) tea.Msg {
+			return clipboardMsg{what: what, err: err}
+		})
+	}
+	return func() tea.Msg {
+		return clipboardMsg{what: what, err: clipboard.WriteAll(text)}
+	}
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// infoColumn is the pseudo-attribute for the default "Info" column, which
+// shows the first non-key attribute of each item.
+const infoColumn = ""
+
+// Inferred widths are clamped so one long value cannot push every other
+// column out of the pane.
+const (
+	minColumnWidth = 4
+	maxColumnWidth = 32
+)
+
+type itemColumn struct {
+	attr  string
+	title string
+	width int
+}
+
+// columnChoice is one row of the column picker.
+type columnChoice struct {
+	attr string
+	on   bool
+}
+
+// itemColumns returns the columns of the item list for table t. Without a
+// saved choice this is the classic PK / SK / Info layout sized to the pane;
+// saved columns get widths inferred from the loaded data instead.
+func (m *model) itemColumns(t Table, visible []int, availWidth int) []itemColumn {
+	attrs := m.config.Columns[t.Name]
+	if len(attrs) == 0 {
+		return defaultColumns(t, availWidth)
+	}
+
+	cols := make([]itemColumn, 0, len(attrs))
+	for _, attr := range attrs {
+		w := lipgloss.Width(attr)
+		for _, i := range visible {
+			if v, ok := m.items[i][attr]; ok {
+				w = max(w, lipgloss.Width(cellText(v)))
+			}
+			if w >= maxColumnWidth {
+				break
+			}
+		}
+		cols = append(cols, itemColumn{attr: attr, title: attr, width: max(minColumnWidth, min(w, maxColumnWidth))})
+	}
+	return cols
+}
+
+func defaultColumns(t Table, availWidth int) []itemColumn {
+	if t.SK != "" {
+		pkW := int(float64(availWidth) * 0.3)
+		skW := int(float64(availWidth) * 0.3)
+		return []itemColumn{
+			{attr: t.PK, title: t.PK, width: pkW},
+			{attr: t.SK, title: t.SK, width: skW},
+			{attr: infoColumn, title: "Info", width: availWidth - pkW - skW - 2},
+		}
+	}
+	pkW := int(float64(availWidth) * 0.4)
+	return []itemColumn{
+		{attr: t.PK, title: t.PK, width: pkW},
+		{attr: infoColumn, title: "Info", width: availWidth - pkW - 1},
+	}
+}
+
+// fitColumns picks the columns that fit in width, starting at the horizontal
+// scroll offset. The first column stays frozen so rows remain identifiable.
+// It also reports whether columns are hidden to the left or right.
+func fitColumns(cols []itemColumn, offset, width int) (shown []itemColumn, hiddenLeft, hiddenRight bool) {
+	if len(cols) == 0 {
+		return nil, false, false
+	}
+	shown = append(shown, cols[0])
+	used := cols[0].width
+
+	offset = max(0, min(offset, len(cols)-2))
+	first := 1 + offset
+	for i := first; i < len(cols); i++ {
+		if used+1+cols[i].width > width {
+			// Always show at least part of one scrolled column
+			if len(shown) == 1 {
+				c := cols[i]
+				c.width = max(width-used-1, 1)
+				shown = append(shown, c)
+			}
+			return shown, first > 1, true
+		}
+		shown = append(shown, cols[i])
+		used += 1 + cols[i].width
+	}
+	return shown, first > 1, false
+}
+
+// columnsHiddenRight reports whether the item list has columns scrolled out
+// of view on the right, using the same layout as renderTableItems.
+func (m *model) columnsHiddenRight() bool {
+	if len(m.tables) == 0 {
+		return false
+	}
+	availWidth := int(float64(m.width)*0.4) - 5
+	cols := m.itemColumns(m.tables[m.tableCursor], m.visibleItems(), availWidth)
+	_, _, hiddenRight := fitColumns(cols, m.columnOffset, availWidth)
+	return hiddenRight
+}
+
+// cellText renders an attribute value on a single line.
+func cellText(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	return strings.ReplaceAll(fmt.Sprintf("%v", v), "\n", " ")
+}
+
+// infoText is the value of the default Info column: the first non-key
+// attribute by name, or matchedAttr when the filter matched a non-key value.
+func infoText(item Item, t Table, matchedAttr string) string {
+	if matchedAttr != "" {
+		return fmt.Sprintf("%s: %s", matchedAttr, cellText(item[matchedAttr]))
+	}
+	var keys []string
+	for k := range item {
+		if k != t.PK && k != t.SK {
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) == 0 {
+		return ""
+	}
+	sort.Strings(keys) // Ensure stable order
+	return fmt.Sprintf("%s: %s", keys[0], cellText(item[keys[0]]))
+}
+
+// truncateText shortens s to at most w cells, marking the cut with an ellipsis.
+func truncateText(s string, w int) string {
+	if lipgloss.Width(s) <= w {
+		return s
+	}
+	if w <= 1 {
+		return ""
+	}
+	r := []rune(s)
+	for len(r) > 0 && lipgloss.Width(string(r)) > w-1 {
+		r = r[:len(r)-1]
+	}
+	return string(r) + "…"
+}
+
+// openColumnPicker lists the saved columns first, in order, followed by every
+// other attribute seen in the loaded items.
+func (m *model) openColumnPicker() {
+	t := m.tables[m.tableCursor]
+	saved := m.config.Columns[t.Name]
+
+	seen := make(map[string]bool)
+	var choices []columnChoice
+	if len(saved) > 0 {
+		for _, attr := range saved {
+			choices = append(choices, columnChoice{attr: attr, on: true})
+			seen[attr] = true
+		}
+	} else {
+		for _, attr := range []string{t.PK, t.SK} {
+			if attr != "" {
+				choices = append(choices, columnChoice{attr: attr, on: true})
+				seen[attr] = true
+			}
+		}
+	}
+
+	for _, attr := range m.loadedAttributes() {
+		if !seen[attr] {
+			choices = append(choices, columnChoice{attr: attr})
+		}
+	}
+
+	m.columnChoices = choices
+	m.columnCursor = 0
+	m.previousView = m.view
+	m.view = viewColumnPicker
+}
+
+// saveColumnChoices stores the picked columns for the current table. Picking
+// nothing falls back to the default layout.
+func (m *model) saveColumnChoices() error {
+	t := m.tables[m.tableCursor]
+	var attrs []string
+	for _, c := range m.columnChoices {
+		if c.on {
+			attrs = append(attrs, c.attr)
+		}
+	}
+
+	if m.config.Columns == nil {
+		m.config.Columns = make(map[string][]string)
+	}
+	if len(attrs) == 0 {
+		delete(m.config.Columns, t.Name)
+	} else {
+		m.config.Columns[t.Name] = attrs
+	}
+	m.columnOffset = 0
+	return SaveConfig(m.config)
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestColumnChoicesPersist(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	s := newSession()
+	s.items = []Item{{"id": "a", "status": "new", "total": 3.0}, {"id": "b", "owner": "kim"}}
+	m := model{tables: []Table{{Name: "orders", PK: "id"}}, session: s, config: defaultConfig()}
+
+	// Without a saved choice the key comes first and is on
+	m.openColumnPicker()
+	want := []columnChoice{{"id", true}, {"owner", false}, {"status", false}, {"total", false}}
+	if !reflect.DeepEqual(m.columnChoices, want) {
+		t.Fatalf("choices = %v, want %v", m.columnChoices, want)
+	}
+
+	m.columnChoices[2].on = true
+	m.columnChoices[3].on = true
+	if err := m.saveColumnChoices(); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.Columns["orders"]; !reflect.DeepEqual(got, []string{"id", "status", "total"}) {
+		t.Fatalf("saved columns = %v, want [id status total]", got)
+	}
+
+	// Reopening lists the saved columns first, in order
+	m.openColumnPicker()
+	want = []columnChoice{{"id", true}, {"status", true}, {"total", true}, {"owner", false}}
+	if !reflect.DeepEqual(m.columnChoices, want) {
+		t.Fatalf("reopened choices = %v, want %v", m.columnChoices, want)
+	}
+
+	// Picking nothing goes back to the default layout
+	for i := range m.columnChoices {
+		m.columnChoices[i].on = false
+	}
+	if err := m.saveColumnChoices(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg, _ := LoadConfig(); cfg.Columns["orders"] != nil {
+		t.Errorf("columns after clearing = %v, want none", cfg.Columns["orders"])
+	}
+}
+
+func TestItemColumnWidths(t *testing.T) {
+	s := newSession()
+	s.items = []Item{
+		{"id": "a", "n": 12345.0, "note": "a note much longer than any column is allowed to be"},
+		{"id": "b", "n": 1.0},
+	}
+	m := model{
+		tables:  []Table{{Name: "t", PK: "id"}},
+		session: s,
+		config:  Config{Columns: map[string][]string{"t": {"id", "n", "note"}}},
+	}
+
+	var widths []int
+	for _, c := range m.itemColumns(m.tables[0], []int{0, 1}, 80) {
+		widths = append(widths, c.width)
+	}
+	// id is padded to the minimum, n fits its widest value, note is clamped
+	if want := []int{minColumnWidth, 5, maxColumnWidth}; !reflect.DeepEqual(widths, want) {
+		t.Errorf("widths = %v, want %v", widths, want)
+	}
+}
+
+func TestFitColumns(t *testing.T) {
+	cols := []itemColumn{{attr: "id", width: 10}, {attr: "a", width: 10}, {attr: "b", width: 10}, {attr: "c", width: 10}}
+	attrs := func(shown []itemColumn) []string {
+		var out []string
+		for _, c := range shown {
+			out = append(out, c.attr)
+		}
+		return out
+	}
+
+	cases := []struct {
+		offset, width int
+		attrs         []string
+		left, right   bool
+	}{
+		{0, 100, []string{"id", "a", "b", "c"}, false, false},
+		{0, 30, []string{"id", "a"}, false, true},
+		{1, 30, []string{"id", "b"}, true, true},
+		{5, 30, []string{"id", "c"}, true, false}, // Offset is clamped so the last column stays reachable
+		{0, 14, []string{"id", "a"}, false, true}, // A partial column is shown rather than none
+	}
+	for _, c := range cases {
+		shown, left, right := fitColumns(cols, c.offset, c.width)
+		if !reflect.DeepEqual(attrs(shown), c.attrs) || left != c.left || right != c.right {
+			t.Errorf("fitColumns(offset %d, width %d) = %v, %v, %v; want %v, %v, %v",
+				c.offset, c.width, attrs(shown), left, right, c.attrs, c.left, c.right)
+		}
+	}
+}
+
+func TestTruncateText(t *testing.T) {
+	cases := []struct {
+		s    string
+		w    int
+		want string
+	}{
+		{"orders", 10, "orders"},
+		{"orders", 6, "orders"},
+		{"orders", 4, "ord…"},
+		{"commandes-été", 12, "commandes-é…"}, // Cut on runes, not bytes
+		{"注文テーブル", 5, "注文…"},                  // Wide runes take two cells
+		{"orders", 1, ""},
+	}
+	for _, c := range cases {
+		if got := truncateText(c.s, c.w); got != c.want {
+			t.Errorf("truncateText(%q, %d) = %q, want %q", c.s, c.w, got, c.want)
+		}
+	}
+}
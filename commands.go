@@ -2,7 +2,11 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
@@ -17,14 +21,14 @@ func loadTables(api *AWS) tea.Msg {
 	defer cancel()
 
 	log.Println("Calling ListTablesWithDetails...")
-	tables, region, accountId, err := api.ListTablesWithDetails(ctx)
+	tables, region, accountId, used, err := api.ListTablesWithDetails(ctx)
 	if err != nil {
 		log.Printf("ListTablesWithDetails failed: %v", err)
-		return errMsg(err)
+		return failed(err, used)
 	}
 
 	log.Printf("Successfully loaded %d tables from region %s, account %s", len(tables), region, accountId)
-	return tablesLoadedMsg{tables: tables, region: region, accountId: accountId}
+	return tablesLoadedMsg{tables: tables, region: region, accountId: accountId, capacity: used}
 
 }
 
@@ -32,26 +36,105 @@ func scanTable(api *AWS, name string, startKey map[string]types.AttributeValue,
 	return func() tea.Msg {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
-		items, nextKey, err := api.ScanTable(ctx, name, startKey)
+		items, nextKey, used, err := api.ScanTable(ctx, name, startKey)
 		if err != nil {
-			return errMsg(err)
+			return failed(err, used)
 		}
 
-		return itemsLoadedMsg{items: items, nextKey: nextKey, isAppend: isAppend}
+		return itemsLoadedMsg{items: items, nextKey: nextKey, isAppend: isAppend, capacity: used}
 	}
 }
 
-func saveItemCmd(api *AWS, tableName string, item Item) tea.Cmd {
+func saveItemCmd(api *AWS, tableName string, item Item, audit AuditEntry) tea.Cmd {
 	return func() tea.Msg {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
 
-		err := api.PutItem(ctx, tableName, item)
-		return itemSavedMsg{err}
+		used, err := api.PutItem(ctx, tableName, item)
+		audit.finish(used, err)
+		return itemSavedMsg{err: err, capacity: used}
 	}
 }
 
-func deleteItemCmd(api *AWS, tableName string, item Item, pkName, skName string) tea.Cmd {
+// updateItemCmd saves inline grid edits as a targeted update of the edited
+// attributes only.
+func updateItemCmd(api *AWS, tableName string, key map[string]interface{}, set map[string]interface{}, audit AuditEntry) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		used, err := api.UpdateItemAttributes(ctx, tableName, key, set)
+		for attr := range set {
+			audit.Attributes = append(audit.Attributes, attr)
+		}
+		sort.Strings(audit.Attributes)
+		audit.finish(used, err)
+		return itemSavedMsg{err: err, capacity: used}
+	}
+}
+
+// batchDeleteCmd deletes the selected items. indices are their positions in
+// m.items, reported back so the deleted ones can be dropped from the list.
+func batchDeleteCmd(api *AWS, tableName string, keys []map[string]interface{}, indices []int, audit AuditEntry) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+		defer cancel()
+
+		deleted, used, err := api.BatchDeleteItems(ctx, tableName, keys)
+		done := make([]int, len(deleted))
+		for i, k := range deleted {
+			done[i] = indices[k]
+		}
+		if err != nil {
+			// The entry lists what was deleted before the batch stopped
+			audit.Keys = make([]map[string]interface{}, len(deleted))
+			for i, k := range deleted {
+				audit.Keys[i] = keys[k]
+			}
+			audit.finish(used, fmt.Errorf("%d of %d deleted: %w", len(deleted), len(keys), err))
+		} else {
+			audit.finish(used, nil)
+		}
+		return batchDoneMsg{action: batchDelete, done: done, capacity: used, err: err}
+	}
+}
+
+// batchSetCmd sets the same attributes on every selected item, one targeted
+// update per item since DynamoDB has no batch update.
+func batchSetCmd(api *AWS, tableName string, keys []map[string]interface{}, indices []int, set map[string]interface{}, audit AuditEntry) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+		defer cancel()
+
+		var used Capacity
+		var err error
+		n := 0
+		for ; n < len(keys); n++ {
+			var c Capacity
+			c, err = api.UpdateItemAttributes(ctx, tableName, keys[n], set)
+			used = used.plus(c)
+			if err != nil {
+				err = fmt.Errorf("item %d of %d: %w", n+1, len(keys), err)
+				break
+			}
+		}
+		for attr := range set {
+			audit.Attributes = append(audit.Attributes, attr)
+		}
+		audit.finish(used, err)
+		return batchDoneMsg{action: batchSet, done: indices[:n], set: set, capacity: used, err: err}
+	}
+}
+
+// exportItemsCmd writes items to a JSON file in the working directory.
+func exportItemsCmd(tableName string, items []Item) tea.Cmd {
+	return func() tea.Msg {
+		path := exportPath(tableName)
+		return exportedMsg{path: path, count: len(items), err: writeExport(path, items)}
+	}
+}
+
+func deleteItemCmd(api *AWS, tableName string, item Item, pkName, skName string, audit AuditEntry) tea.Cmd {
 	return func() tea.Msg {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
@@ -67,17 +150,251 @@ func deleteItemCmd(api *AWS, tableName string, item Item, pkName, skName string)
 			}
 		}
 
-		err := api.DeleteItem(ctx, tableName, keyMap)
-		return itemDeletedMsg{err}
+		used, err := api.DeleteItem(ctx, tableName, keyMap)
+		audit.Keys = []map[string]interface{}{keyMap}
+		audit.finish(used, err)
+		return itemDeletedMsg{err: err, capacity: used}
 	}
 }
 
-func generateSQLCmd(api *AWS, question string, table Table) tea.Cmd {
+func loadAuditLogCmd() tea.Msg {
+	entries, err := LoadAuditLog()
+	return auditLoadedMsg{entries: entries, err: err}
+}
+
+// generateSQLCmd asks Bedrock for a plan. pc tells the model which
+// attributes exist and what is loaded.
+func generateSQLCmd(api *AWS, question string, table Table, pc PromptContext) tea.Cmd {
 	return func() tea.Msg {
 		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 		defer cancel()
 
-		result, err := api.InvokeBedrock(ctx, question, table)
+		result, err := api.InvokeBedrock(ctx, question, table, pc)
 		return sqlGeneratedMsg{result: result, err: err}
 	}
-}
\ No newline at end of file
+}
+
+// tablePollInterval is how often a changing table's status is checked.
+const tablePollInterval = 3 * time.Second
+
+func createTableCmd(api *AWS, spec TableSpec, audit AuditEntry) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		err := api.CreateTable(ctx, spec)
+		audit.finish(Capacity{}, err)
+		return schemaChangedMsg{action: auditCreateTable, table: spec.Name, spec: spec, err: err}
+	}
+}
+
+func deleteTableCmd(api *AWS, tableName string, audit AuditEntry) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		err := api.DeleteTable(ctx, tableName)
+		audit.finish(Capacity{}, err)
+		return schemaChangedMsg{action: auditDeleteTable, table: tableName, err: err}
+	}
+}
+
+func createIndexCmd(api *AWS, t Table, idx IndexSpec, audit AuditEntry) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		err := api.CreateGSI(ctx, t, idx)
+		audit.finish(Capacity{}, err)
+		return schemaChangedMsg{action: auditCreateIndex, table: t.Name, index: idx.Name, err: err}
+	}
+}
+
+func deleteIndexCmd(api *AWS, tableName, index string, audit AuditEntry) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		err := api.DeleteGSI(ctx, tableName, index)
+		audit.finish(Capacity{}, err)
+		return schemaChangedMsg{action: auditDeleteIndex, table: tableName, index: index, err: err}
+	}
+}
+
+// pollTableCmd waits one poll interval and describes the table again.
+func pollTableCmd(api *AWS, tableName string) tea.Cmd {
+	return func() tea.Msg {
+		time.Sleep(tablePollInterval)
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		details, err := api.DescribeTable(ctx, tableName)
+		var notFound *types.ResourceNotFoundException
+		if errors.As(err, &notFound) {
+			return tableStatusMsg{name: tableName, gone: true}
+		}
+		return tableStatusMsg{name: tableName, details: details, err: err}
+	}
+}
+
+func loadSettingsCmd(api *AWS, tableName string) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		s, err := api.DescribeTableSettings(ctx, tableName)
+		return settingsLoadedMsg{table: tableName, settings: s, err: err}
+	}
+}
+
+// saveSettingsCmd applies the changed settings. The audit entry lists the
+// settings that were applied, even when a later one failed.
+func saveSettingsCmd(api *AWS, tableName string, old, s TableSettings, audit AuditEntry) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+		defer cancel()
+
+		applied, err := api.UpdateTableSettings(ctx, tableName, old, s)
+		audit.Settings = applied
+		audit.finish(Capacity{}, err)
+		if err != nil && len(applied) > 0 {
+			err = fmt.Errorf("%w (already applied: %s)", err, strings.Join(applied, ", "))
+		}
+		return schemaChangedMsg{action: auditUpdateSettings, table: tableName, settings: applied, err: err}
+	}
+}
+
+func loadBackupsCmd(api *AWS, tableName string) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		backups, window, err := api.ListBackups(ctx, tableName)
+		return backupsLoadedMsg{backups: backups, window: window, err: err}
+	}
+}
+
+func createBackupCmd(api *AWS, tableName, name string, audit AuditEntry) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		_, err := api.CreateBackup(ctx, tableName, name)
+		audit.finish(Capacity{}, err)
+		return schemaChangedMsg{action: auditCreateBackup, table: tableName, err: err}
+	}
+}
+
+func restoreBackupCmd(api *AWS, source, backupARN, target string, audit AuditEntry) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		err := api.RestoreBackup(ctx, backupARN, target)
+		audit.finish(Capacity{}, err)
+		return schemaChangedMsg{action: auditRestoreTable, table: target, source: source, err: err}
+	}
+}
+
+func restorePointInTimeCmd(api *AWS, source, target string, at time.Time, audit AuditEntry) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		err := api.RestorePointInTime(ctx, source, target, at)
+		audit.finish(Capacity{}, err)
+		return schemaChangedMsg{action: auditRestoreTable, table: target, source: source, err: err}
+	}
+}
+
+// streamPollInterval is how often a stream tail asks for new records.
+const streamPollInterval = 2 * time.Second
+
+// readStreamCmd reads the records written to the table's stream since pos,
+// after one poll interval when wait is set.
+func readStreamCmd(api *AWS, tableName string, tail *streamTail, pos StreamPosition, wait bool) tea.Cmd {
+	return func() tea.Msg {
+		if wait {
+			time.Sleep(streamPollInterval)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		records, next, err := api.ReadStream(ctx, tableName, pos)
+		return streamRecordsMsg{tail: tail, pos: next, records: records, err: err}
+	}
+}
+
+// refreshTickCmd waits one auto-refresh interval.
+func refreshTickCmd(s *session, gen int, every time.Duration) tea.Cmd {
+	return func() tea.Msg {
+		time.Sleep(every)
+		return refreshTickMsg{session: s, gen: gen}
+	}
+}
+
+// refreshItemsCmd re-fetches what a tab shows: the statements of a query
+// result, or as many scan pages as were loaded.
+func refreshItemsCmd(api *AWS, s *session, gen int, tableName string, query []string, pages int) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancel()
+
+		msg := refreshedMsg{session: s, gen: gen}
+		switch {
+		case len(query) == 1:
+			msg.items, msg.capacity, msg.err = api.SqlQuery(ctx, Operation{expression: query[0]})
+		case len(query) > 1:
+			msg.items, msg.capacity, msg.err = api.BatchSqlQuery(ctx, query)
+		default:
+			var startKey map[string]types.AttributeValue
+			for page := 0; page < max(pages, 1); page++ {
+				items, next, used, err := api.ScanTable(ctx, tableName, startKey)
+				msg.capacity = msg.capacity.plus(used)
+				if err != nil {
+					msg.err = err
+					break
+				}
+				msg.items = append(msg.items, items...)
+				msg.nextKey, startKey = next, next
+				if next == nil {
+					break
+				}
+			}
+		}
+		return msg
+	}
+}
+
+// changesExpiredCmd clears the change marks after they have been shown.
+func changesExpiredCmd(s *session, gen int) tea.Cmd {
+	return func() tea.Msg {
+		time.Sleep(changeHighlight)
+		return changesExpiredMsg{session: s, gen: gen}
+	}
+}
+
+// profileTableCmd samples a table, profiles its attributes and caches the
+// profile on disk.
+func profileTableCmd(api *AWS, account, region, tableName string, size, segments int) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+		defer cancel()
+
+		items, used, err := api.SampleItems(ctx, tableName, size, segments)
+		if err != nil {
+			return profileLoadedMsg{err: err, capacity: used}
+		}
+		p := &TableProfile{
+			Table:      tableName,
+			Account:    account,
+			Region:     region,
+			SampledAt:  time.Now(),
+			Items:      len(items),
+			Segments:   segments,
+			Capacity:   used,
+			Attributes: buildProfile(items),
+		}
+		return profileLoadedMsg{profile: p, saveErr: SaveProfile(p)}
+	}
+}
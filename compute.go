@@ -0,0 +1,356 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ComputedSet is one attribute a computed update writes. Expr (see expr.go)
+// is evaluated client-side against every item the plan read.
+type ComputedSet struct {
+	Path string `json:"path"`
+	Expr string `json:"expr"`
+}
+
+// computedSet is a ComputedSet with its expression parsed.
+type computedSet struct {
+	target string
+	node   exprNode
+	reads  []string // Attributes the expression reads, other than the key
+}
+
+// compileComputed parses every expression of a compute plan and checks the
+// attributes it writes, before anything is read.
+func compileComputed(sets []ComputedSet, t Table) ([]computedSet, error) {
+	if len(sets) == 0 {
+		return nil, fmt.Errorf("The compute plan sets no attributes.")
+	}
+	seen := make(map[string]bool)
+	var out []computedSet
+	for _, s := range sets {
+		target := strings.TrimSpace(s.Path)
+		if target == "" || strings.Contains(target, "[]") {
+			return nil, fmt.Errorf("Can't set %q: give one attribute path.", s.Path)
+		}
+		top, _, _ := strings.Cut(target, ".")
+		top, _, _ = strings.Cut(top, "[")
+		if top == t.PK || top == t.SK {
+			return nil, fmt.Errorf("Can't set %s: key attributes can't be updated.", target)
+		}
+		if seen[target] {
+			return nil, fmt.Errorf("%s is set twice.", target)
+		}
+		seen[target] = true
+		node, err := parseExpr(s.Expr)
+		if err != nil {
+			return nil, fmt.Errorf("%s = %s: %w", target, s.Expr, err)
+		}
+		var reads []string
+		for _, path := range exprReads(node) {
+			top, _, _ := strings.Cut(path, ".")
+			top, _, _ = strings.Cut(top, "[")
+			if top != t.PK && top != t.SK {
+				reads = append(reads, path)
+			}
+		}
+		out = append(out, computedSet{target: target, node: node, reads: reads})
+	}
+	return out, nil
+}
+
+// computedWrite is a compute plan evaluated over the items it read: one
+// UPDATE per item whose values change.
+type computedWrite struct {
+	statements []string
+	keys       []map[string]interface{}
+	labels     []string // keyLabel of the item each statement updates
+	skipped    []string // One line per item an expression failed on
+	unchanged  int      // Items that already hold the computed values
+}
+
+// buildComputed evaluates the expressions against each item. Every
+// expression sees the item as it was read, not the values set before it.
+// Each UPDATE only applies while the attributes it sets and the ones its
+// expressions read still hold the values that were read, so a concurrent
+// write isn't overwritten or built on.
+func buildComputed(items []Item, sets []computedSet, t Table, now time.Time) *computedWrite {
+	env := &exprEnv{now: now}
+	w := &computedWrite{}
+	for _, item := range items {
+		where, err := keyCondition(item, t)
+		if err != nil {
+			w.skipped = append(w.skipped, fmt.Sprintf("%s: %v", keyLabel(item, t), err))
+			continue
+		}
+		assigns, checks, err := computeAssigns(item, sets, env)
+		switch {
+		case err != nil:
+			w.skipped = append(w.skipped, fmt.Sprintf("%s: %v", keyLabel(item, t), err))
+		case len(assigns) == 0:
+			w.unchanged++
+		default:
+			w.statements = append(w.statements, fmt.Sprintf("UPDATE %s SET %s WHERE %s AND %s", quoteIdent(t.Name), strings.Join(assigns, ", "), where, strings.Join(checks, " AND ")))
+			w.keys = append(w.keys, itemKey(item, t))
+			w.labels = append(w.labels, keyLabel(item, t))
+		}
+	}
+	return w
+}
+
+// computeAssigns renders the SET assignments of one item, leaving out
+// attributes that already hold their computed value, and the checks that
+// each attribute set or read by those assignments still holds the value that
+// was read.
+func computeAssigns(item Item, sets []computedSet, env *exprEnv) (assigns, checks []string, err error) {
+	checked := make(map[string]bool)
+	check := func(path string) error {
+		if checked[path] {
+			return nil
+		}
+		checked[path] = true
+		c, err := unchangedCheck(path, pathLookup(item, path))
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		checks = append(checks, c)
+		return nil
+	}
+
+	var reads []string
+	for _, s := range sets {
+		v, err := s.node.eval(item, env)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", s.target, err)
+		}
+		old := pathLookup(item, s.target)
+		if len(old) == 1 && reflect.DeepEqual(old[0], v) {
+			continue
+		}
+		if parent, ok := parentExists(item, s.target); !ok {
+			return nil, nil, fmt.Errorf("%s: %s is missing or not a map or list, so DynamoDB can't set a value in it", s.target, parent)
+		}
+		lit, err := partiqlLiteral(v)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", s.target, err)
+		}
+		if err := check(s.target); err != nil {
+			return nil, nil, err
+		}
+		assigns = append(assigns, partiqlPath(s.target)+" = "+lit)
+		reads = append(reads, s.reads...)
+	}
+	for _, path := range reads {
+		if err := check(path); err != nil {
+			return nil, nil, err
+		}
+	}
+	return assigns, checks, nil
+}
+
+// parentExists checks that the map or list a nested path sets a value in
+// exists, since DynamoDB rejects a SET through a missing parent. It returns
+// the parent path and false when it doesn't.
+func parentExists(item Item, path string) (string, bool) {
+	cut := strings.LastIndexAny(path, ".[")
+	if cut < 0 {
+		return "", true
+	}
+	parent := path[:cut]
+	values := pathLookup(item, parent)
+	if len(values) != 1 {
+		return parent, false
+	}
+	switch values[0].(type) {
+	case map[string]interface{}:
+		return parent, path[cut] == '.'
+	case []interface{}:
+		return parent, path[cut] == '['
+	}
+	return parent, false
+}
+
+// unchangedCheck is the condition that path still holds old, the values
+// pathLookup read there: missing when there were none, NULL when the
+// attribute is stored as NULL.
+func unchangedCheck(path string, old []interface{}) (string, error) {
+	switch {
+	case len(old) == 0:
+		return partiqlPath(path) + " IS MISSING", nil
+	case old[0] == nil:
+		return partiqlPath(path) + " IS NULL", nil
+	}
+	lit, err := partiqlLiteral(old[0])
+	if err != nil {
+		return "", fmt.Errorf("can't check the value read: %w", err)
+	}
+	return partiqlPath(path) + " = " + lit, nil
+}
+
+// changedSinceRead picks the items whose check failed, because they were
+// written after the plan read them, out of the error of a computed write.
+// The error it returns holds only the statements that failed otherwise.
+func (w *computedWrite) changedSinceRead(err error) ([]string, error) {
+	var failed batchStatementErrors
+	if !errors.As(err, &failed) {
+		return nil, err
+	}
+	var changed []string
+	var rest batchStatementErrors
+	for _, f := range failed {
+		if f.code == string(types.BatchStatementErrorCodeEnumConditionalCheckFailed) {
+			changed = append(changed, w.labels[f.index])
+		} else {
+			rest = append(rest, f)
+		}
+	}
+	if len(rest) == 0 {
+		return changed, nil
+	}
+	return changed, rest
+}
+
+// keyLabel names an item by its key values in messages.
+func keyLabel(item Item, t Table) string {
+	if t.SK == "" {
+		return cellText(item[t.PK])
+	}
+	return cellText(item[t.PK]) + " / " + cellText(item[t.SK])
+}
+
+// partiqlPath quotes each name of an attribute path for a SET clause.
+func partiqlPath(path string) string {
+	var b strings.Builder
+	for i, part := range strings.Split(path, ".") {
+		if i > 0 {
+			b.WriteString(".")
+		}
+		name, rest, _ := strings.Cut(part, "[")
+		b.WriteString(quoteIdent(name))
+		if rest != "" {
+			b.WriteString("[" + rest)
+		}
+	}
+	return b.String()
+}
+
+// partiqlLiteral renders any value an expression can produce, including the
+// maps and lists it may copy from another attribute.
+func partiqlLiteral(v interface{}) (string, error) {
+	switch t := v.(type) {
+	case nil:
+		return "NULL", nil
+	case []interface{}:
+		parts := make([]string, len(t))
+		for i, e := range t {
+			lit, err := partiqlLiteral(e)
+			if err != nil {
+				return "", err
+			}
+			parts[i] = lit
+		}
+		return "[" + strings.Join(parts, ", ") + "]", nil
+	case map[string]interface{}:
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		parts := make([]string, len(keys))
+		for i, k := range keys {
+			lit, err := partiqlLiteral(t[k])
+			if err != nil {
+				return "", err
+			}
+			key, _ := FormatPartiQLValue(k)
+			parts[i] = key + ": " + lit
+		}
+		return "{" + strings.Join(parts, ", ") + "}", nil
+	}
+	return FormatPartiQLValue(v)
+}
+
+// computedText describes the SET step of a compute plan for the confirmation.
+func computedText(sets []ComputedSet) string {
+	lines := make([]string, len(sets))
+	for i, s := range sets {
+		lines[i] = fmt.Sprintf("SET %s = %s", s.Path, s.Expr)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// preview lists the updates, and the items that were skipped, for the bulk
+// confirmation.
+func (w *computedWrite) preview() string {
+	var b strings.Builder
+	if len(w.skipped) > 0 {
+		fmt.Fprintf(&b, "SKIPPED (%d):\n%s\n\n", len(w.skipped), strings.Join(w.skipped, "\n"))
+	}
+	fmt.Fprintf(&b, "UPDATES (%d):\n%s", len(w.statements), strings.Join(w.statements, "\n"))
+	return b.String()
+}
+
+// prepareComputed evaluates a compute plan over the items its read found and
+// opens the bulk confirmation on the resulting UPDATEs.
+func (m *model) prepareComputed() {
+	t := m.tables[m.tableCursor]
+	sets, err := compileComputed(m.llmResult.Plan.Write.PerItem.Set, t)
+	if err != nil {
+		m.settleTurn("rejected: "+err.Error(), true)
+		m.err = err
+		m.view = viewError
+		return
+	}
+	w := buildComputed(m.pendingPlanItems, sets, t, time.Now())
+	if len(w.statements) == 0 {
+		m.err = fmt.Errorf("Nothing to update: %d items already hold the computed values, %d were skipped.", w.unchanged, len(w.skipped))
+		m.settleTurn(m.err.Error(), true)
+		m.view = viewError
+		return
+	}
+	m.computed = w
+	m.sqlViewport.Width = int(float64(m.width) * 0.7)
+	m.sqlViewport.Height = max(m.height-16, 5)
+	m.sqlViewport.SetContent(w.preview())
+	m.sqlViewport.GotoTop()
+	m.view = viewBulkConfirmation
+}
+
+// executeComputed runs the confirmed per-item UPDATEs and re-scans the table.
+func (m *model) executeComputed() tea.Cmd {
+	m.loading = true
+	m.view = viewLoading
+	m.statusMessage = "Executing Computed Updates..."
+
+	api, table, w := m.aws, m.tables[m.tableCursor].Name, m.computed
+	m.computed = nil
+	audit := m.newAuditEntry(auditPlanBulkWrite, w.statements...)
+	audit.Keys = w.keys
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		_, used, err := api.BatchSqlQuery(ctx, w.statements)
+		audit.finish(used, err)
+		changed, err := w.changedSinceRead(err)
+		if err != nil {
+			return failed(err, used)
+		}
+		scanItems, nextKey, scanUsed, err := api.ScanTable(ctx, table, nil)
+		if err != nil {
+			return failed(err, used.plus(scanUsed))
+		}
+		msg := itemsLoadedMsg{items: scanItems, nextKey: nextKey, isAppend: false, capacity: used.plus(scanUsed)}
+		if len(changed) > 0 {
+			msg.notice = fmt.Sprintf("%d of %d items changed since read and were left as they are: %s", len(changed), len(w.statements), strings.Join(changed, ", "))
+		}
+		return msg
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildComputed(t *testing.T) {
+	tbl := Table{Name: "Items", PK: "id", SK: "sk"}
+	items := []Item{
+		{"id": "a", "sk": 1.0, "price": 10.0, "name": "pen", "meta": map[string]interface{}{}},
+		{"id": "b", "sk": 2.0, "price": 20.0, "name": "INK", "meta": map[string]interface{}{"label": "ink"}},
+		{"id": "c", "sk": 3.0, "name": "cup", "meta": map[string]interface{}{}}, // No price to add to
+		{"id": "d", "sk": 4.0, "price": 1.0, "name": "mug"},                     // No meta map to set a label in
+	}
+	sets, err := compileComputed([]ComputedSet{
+		{Path: "price", Expr: "price + 5"},
+		{Path: "meta.label", Expr: "upper(name)"},
+	}, tbl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := buildComputed(items, sets, tbl, time.Now())
+
+	want := []string{
+		`UPDATE "Items" SET "price" = 15, "meta"."label" = 'PEN' WHERE "id" = 'a' AND "sk" = 1 AND "price" = 10 AND "meta"."label" IS MISSING AND "name" = 'pen'`,
+		`UPDATE "Items" SET "price" = 25, "meta"."label" = 'INK' WHERE "id" = 'b' AND "sk" = 2 AND "price" = 20 AND "meta"."label" = 'ink' AND "name" = 'INK'`,
+	}
+	if !reflect.DeepEqual(w.statements, want) {
+		t.Errorf("statements = %q, want %q", w.statements, want)
+	}
+	if len(w.skipped) != 2 || !strings.HasPrefix(w.skipped[0], "c / 3: price:") || !strings.HasPrefix(w.skipped[1], "d / 4: meta.label: meta is missing") {
+		t.Errorf("skipped = %q", w.skipped)
+	}
+
+	// A NULL attribute is checked as NULL, not as missing
+	stamp, err := compileComputed([]ComputedSet{{Path: "updated_at", Expr: "'today'"}}, tbl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w = buildComputed([]Item{{"id": "a", "sk": 1.0, "updated_at": nil}}, stamp, tbl, time.Now())
+	if want := `UPDATE "Items" SET "updated_at" = 'today' WHERE "id" = 'a' AND "sk" = 1 AND "updated_at" IS NULL`; len(w.statements) != 1 || w.statements[0] != want {
+		t.Errorf("statements = %q, want %q", w.statements, want)
+	}
+
+	// Attributes an expression reads are checked too, once each, but not the key
+	total, err := compileComputed([]ComputedSet{{Path: "total", Expr: "price * qty + length(id) * 0 + coalesce(total, 0) * 0"}}, tbl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w = buildComputed([]Item{{"id": "a", "sk": 1.0, "price": 2.0, "qty": 3.0}}, total, tbl, time.Now())
+	if want := `UPDATE "Items" SET "total" = 6 WHERE "id" = 'a' AND "sk" = 1 AND "total" IS MISSING AND "price" = 2 AND "qty" = 3`; len(w.statements) != 1 || w.statements[0] != want {
+		t.Errorf("statements = %q, want %q", w.statements, want)
+	}
+
+	// Items already holding the value are left out
+	same, _ := compileComputed([]ComputedSet{{Path: "name", Expr: "upper(name)"}}, tbl)
+	w = buildComputed(items, same, tbl, time.Now())
+	if len(w.statements) != 3 || w.unchanged != 1 {
+		t.Errorf("statements %q, unchanged %d", w.statements, w.unchanged)
+	}
+}
+
+func TestChangedSinceRead(t *testing.T) {
+	w := &computedWrite{statements: []string{"a", "b", "c"}, labels: []string{"a", "b", "c"}}
+	err := batchStatementErrors{
+		{index: 0, code: "ConditionalCheckFailed", message: "The conditional request failed"},
+		{index: 2, code: "ThrottlingError", message: "slow down"},
+	}
+
+	changed, rest := w.changedSinceRead(err)
+	if !reflect.DeepEqual(changed, []string{"a"}) {
+		t.Errorf("changed = %v, want [a]", changed)
+	}
+	if rest == nil || strings.Contains(rest.Error(), "Conditional") || !strings.Contains(rest.Error(), "Statement 3 failed: ThrottlingError") {
+		t.Errorf("remaining error = %v, want only statement 3", rest)
+	}
+
+	// Only changed items is not an error
+	if changed, rest := w.changedSinceRead(err[:1]); len(changed) != 1 || rest != nil {
+		t.Errorf("changed %v, error %v; want one item and no error", changed, rest)
+	}
+	if _, rest := w.changedSinceRead(errors.New("network down")); rest == nil {
+		t.Error("a failed call was dropped")
+	}
+}
+
+func TestCompileComputedRejects(t *testing.T) {
+	tbl := Table{Name: "Items", PK: "id", SK: "sk"}
+	bad := [][]ComputedSet{
+		nil,
+		{{Path: "id", Expr: "'x'"}},
+		{{Path: "sk", Expr: "1"}},
+		{{Path: "tags[]", Expr: "1"}},
+		{{Path: "a", Expr: "1"}, {Path: "a", Expr: "2"}},
+		{{Path: "a", Expr: "price +"}},
+	}
+	for _, sets := range bad {
+		if _, err := compileComputed(sets, tbl); err == nil {
+			t.Errorf("%v: no error", sets)
+		}
+	}
+}
+
+func TestPartiqlLiteral(t *testing.T) {
+	got, err := partiqlLiteral(map[string]interface{}{"b": []interface{}{1.0, "it's", nil}, "a": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{'a': true, 'b': [1, 'it''s', NULL]}`; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
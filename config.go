@@ -8,6 +8,30 @@ import (
 
 type Config struct {
 	Theme string `json:"theme"`
+	// ScanConfirmRCU is the estimated read capacity above which a full table
+	// scan must be confirmed by typing a phrase. Zero confirms every scan and
+	// negative disables the check; an absent field keeps the default.
+	ScanConfirmRCU float64 `json:"scan_confirm_rcu"`
+	// Columns maps a table name to the attributes shown in its item list.
+	Columns map[string][]string `json:"columns,omitempty"`
+	// Favorites are table names pinned to the top of the table list.
+	Favorites []string `json:"favorites,omitempty"`
+	// GroupTables groups the table list by name prefix (prod-, dev-, ...).
+	GroupTables bool `json:"group_tables,omitempty"`
+	// ProfileSampleSize is how many items a table profile samples (default 1000).
+	ProfileSampleSize int `json:"profile_sample_size,omitempty"`
+	// ProfileSegments spreads the profile sample over a segmented scan of this
+	// many segments instead of reading the start of the table.
+	ProfileSegments int `json:"profile_segments,omitempty"`
+	// ExplainMaxItems caps how many items "explain results" sends to Bedrock
+	// (default 25).
+	ExplainMaxItems int `json:"explain_max_items,omitempty"`
+}
+
+const defaultScanConfirmRCU = 10000
+
+func defaultConfig() Config {
+	return Config{Theme: "Dark", ScanConfirmRCU: defaultScanConfirmRCU}
 }
 
 func getConfigDir() (string, error) {
@@ -27,7 +51,7 @@ func LoadConfig() (Config, error) {
 
 	configPath := filepath.Join(configDir, "config.json")
 	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		return Config{Theme: "Dark"}, nil // Default
+		return defaultConfig(), nil
 	}
 
 	data, err := os.ReadFile(configPath)
@@ -35,11 +59,10 @@ func LoadConfig() (Config, error) {
 		return Config{}, err
 	}
 
-	var cfg Config
+	cfg := defaultConfig()
 	if err := json.Unmarshal(data, &cfg); err != nil {
 		return Config{}, err
 	}
-
 	return cfg, nil
 }
 
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadConfigScanConfirmRCU(t *testing.T) {
+	cases := []struct {
+		file string
+		want float64
+	}{
+		{`{"theme": "Dark"}`, defaultScanConfirmRCU},
+		{`{"scan_confirm_rcu": 0}`, 0},
+		{`{"scan_confirm_rcu": -1}`, -1},
+		{`{"scan_confirm_rcu": 500}`, 500},
+	}
+	for _, c := range cases {
+		home := t.TempDir()
+		t.Setenv("HOME", home)
+		dir := filepath.Join(home, ".config", "dynotui")
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(c.file), 0644); err != nil {
+			t.Fatal(err)
+		}
+		cfg, err := LoadConfig()
+		if err != nil {
+			t.Fatalf("%s: %v", c.file, err)
+		}
+		if cfg.ScanConfirmRCU != c.want {
+			t.Errorf("%s: ScanConfirmRCU = %v, want %v", c.file, cfg.ScanConfirmRCU, c.want)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	maxConversationTurns = 6   // Most recent turns sent back to the model
+	maxTurnText          = 600 // Generated PartiQL and outcomes are cut to this many characters
+	turnExampleKeys      = 3   // Item keys quoted in a result summary
+)
+
+// conversationTurn is one question asked with / on a tab, what the model
+// made of it and what running it did. Follow-up questions are sent with the
+// earlier turns so they can refine the last query.
+type conversationTurn struct {
+	Table     string
+	Question  string
+	Generated string // Statements or plan, as shown in the confirmation
+	Outcome   string // Empty until the result comes back; stays empty when it wasn't run
+}
+
+// generatedText condenses an LLM result for the conversation history.
+func generatedText(r LLMResult) string {
+	if r.Mode != "plan" || r.Plan == nil {
+		return "SQL: " + strings.Join(r.Statements, "; ")
+	}
+	p := r.Plan
+	s := fmt.Sprintf("plan %s, read: %s", p.Operation, p.Read.Partiql)
+	switch {
+	case p.Aggregate != nil:
+		s += fmt.Sprintf(", aggregate (%s): %s", p.Aggregate.Source, p.Aggregate.describe())
+	case p.Write != nil && p.Write.Action == "compute":
+		s += ", compute: " + strings.ReplaceAll(computedText(p.Write.PerItem.Set), "\n", "; ")
+	case p.Write != nil:
+		s += fmt.Sprintf(", %s each: %s", p.Write.Action, p.Write.PerItem.PartiqlTemplate)
+	}
+	return s
+}
+
+// askBedrock sends a question about the selected table to Bedrock, with the
+// conversation so far.
+func (m *model) askBedrock(question string) tea.Cmd {
+	m.lastQuestion = question
+	m.previousView = m.view // Save current view (List or Items)
+	m.loading = true
+	m.view = viewLoading
+	m.statusMessage = "Generating SQL with Bedrock..."
+	t := m.tables[m.tableCursor]
+	return generateSQLCmd(m.aws, question, t, m.promptContext(t))
+}
+
+// conversationFor returns the turns of the active tab that are about table.
+func (m *model) conversationFor(table string) []conversationTurn {
+	if len(m.conversation) == 0 || m.conversation[0].Table != table {
+		return nil
+	}
+	return m.conversation
+}
+
+// addTurn records a question and what the model generated for it. Asking
+// about another table starts a new conversation.
+func (m *model) addTurn(table, question string, r LLMResult) {
+	if m.conversationFor(table) == nil {
+		m.conversation = nil
+	}
+	turn := conversationTurn{Table: table, Question: question}
+	if r.Mode == "refusal" {
+		turn.Outcome = "refused: " + r.RefusalReason
+	} else {
+		turn.Generated = truncateText(generatedText(r), maxTurnText)
+	}
+	m.conversation = append(m.conversation, turn)
+	if n := len(m.conversation); n > maxConversationTurns {
+		m.conversation = m.conversation[n-maxConversationTurns:]
+	}
+	m.awaitingOutcome = r.Mode != "refusal"
+}
+
+// editTurn replaces what the last turn generated with the hand-edited
+// version that is about to run.
+func (m *model) editTurn(r LLMResult) {
+	if !m.awaitingOutcome || len(m.conversation) == 0 {
+		return
+	}
+	last := &m.conversation[len(m.conversation)-1]
+	last.Generated = truncateText(generatedText(r)+" (edited by the user before running)", maxTurnText)
+}
+
+// settleTurn adds to the outcome of the last turn while its result is still
+// coming in; done ends the turn, so unrelated loads don't touch it later.
+func (m *model) settleTurn(outcome string, done bool) {
+	if !m.awaitingOutcome || len(m.conversation) == 0 {
+		return
+	}
+	last := &m.conversation[len(m.conversation)-1]
+	if last.Outcome != "" {
+		outcome = last.Outcome + "; " + outcome
+	}
+	last.Outcome = truncateText(outcome, maxTurnText)
+	if done {
+		m.awaitingOutcome = false
+	}
+}
+
+// itemsOutcome summarizes a returned result by its size and first keys.
+func itemsOutcome(verb string, items []map[string]interface{}, t Table) string {
+	s := fmt.Sprintf("%s %d items", verb, len(items))
+	if len(items) == 0 {
+		return s
+	}
+	var keys []string
+	for _, item := range items[:min(len(items), turnExampleKeys)] {
+		keys = append(keys, keyLabel(Item(item), t))
+	}
+	return s + " (e.g. " + strings.Join(keys, ", ") + ")"
+}
+
+// aggregateOutcome summarizes a summary table by its first rows.
+func aggregateOutcome(r *AggregateResult) string {
+	s := fmt.Sprintf("aggregated %d items into %d rows", r.Items, len(r.Rows))
+	var rows []string
+	for _, row := range r.Rows[:min(len(r.Rows), 5)] {
+		cells := make([]string, len(row))
+		for i, c := range row {
+			cells[i] = r.Columns[i] + "=" + c
+		}
+		rows = append(rows, strings.Join(cells, " "))
+	}
+	if len(rows) > 0 {
+		s += ": " + strings.Join(rows, " | ")
+	}
+	return s
+}
+
+// clearConversation forgets the questions asked on the active tab.
+func (m *model) clearConversation() {
+	m.conversation = nil
+	m.awaitingOutcome = false
+	m.notice = "AI conversation cleared; the next question starts fresh"
+}
+
+// questionPlaceholder hints in the / bar whether a question will be read as
+// a follow-up.
+func (m *model) questionPlaceholder() string {
+	if len(m.tables) == 0 {
+		return "Type a command..."
+	}
+	turns := m.conversationFor(m.tables[m.tableCursor].Name)
+	if len(turns) == 0 {
+		return "Type a command..."
+	}
+	return fmt.Sprintf("Follow up on %q (C clears the conversation)...", truncateText(turns[len(turns)-1].Question, 40))
+}
+
+// conversationPrompt lists the earlier turns for the model, oldest first.
+func conversationPrompt(turns []conversationTurn) string {
+	if len(turns) == 0 {
+		return "Conversation so far: none. This is a new question."
+	}
+	var b strings.Builder
+	b.WriteString("Conversation so far on this table (oldest first):\n")
+	for i, t := range turns {
+		outcome := t.Outcome
+		if outcome == "" {
+			outcome = "not run"
+		}
+		fmt.Fprintf(&b, "%d. Question: %s\n", i+1, t.Question)
+		if t.Generated != "" {
+			fmt.Fprintf(&b, "   Generated: %s\n", t.Generated)
+		}
+		fmt.Fprintf(&b, "   Outcome: %s\n", outcome)
+	}
+	return b.String()
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConversationTurns(t *testing.T) {
+	m := &model{session: &session{}}
+	sql := LLMResult{Mode: "sql", Statements: []string{`SELECT * FROM "orders" WHERE "status" = 'open'`}}
+
+	m.addTurn("orders", "open orders", sql)
+	m.settleTurn("read found 2 items", false)
+	m.settleTurn("write cancelled", true)
+	m.settleTurn("executed; the table was re-scanned", true) // A later scan isn't part of the turn
+	if got := m.conversation[0].Outcome; got != "read found 2 items; write cancelled" {
+		t.Errorf("outcome = %q", got)
+	}
+
+	m.addTurn("orders", "drop the table", LLMResult{Mode: "refusal", RefusalReason: "DDL"})
+	m.settleTurn("failed: boom", true)
+	if got := m.conversation[1].Outcome; got != "refused: DDL" {
+		t.Errorf("refusal outcome = %q", got)
+	}
+
+	for i := 0; i < maxConversationTurns; i++ {
+		m.addTurn("orders", "again", sql)
+	}
+	if len(m.conversation) != maxConversationTurns || m.conversation[0].Question != "again" {
+		t.Errorf("kept %d turns, first %q", len(m.conversation), m.conversation[0].Question)
+	}
+	if m.conversationFor("users") != nil {
+		t.Error("history of orders offered for users")
+	}
+
+	m.addTurn("users", "all users", sql)
+	if len(m.conversation) != 1 || m.conversation[0].Table != "users" {
+		t.Errorf("another table kept %d turns", len(m.conversation))
+	}
+}
+
+func TestConversationPrompt(t *testing.T) {
+	if got := conversationPrompt(nil); !strings.Contains(got, "none") {
+		t.Errorf("empty prompt = %q", got)
+	}
+	got := conversationPrompt([]conversationTurn{
+		{Table: "orders", Question: "open orders", Generated: "SQL: SELECT 1", Outcome: "returned 3 items"},
+		{Table: "orders", Question: "only mine", Generated: "SQL: SELECT 2"},
+	})
+	for _, want := range []string{"1. Question: open orders", "Generated: SQL: SELECT 1", "Outcome: returned 3 items", "2. Question: only mine", "Outcome: not run"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("prompt is missing %q:\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Formats offered after pressing y on an item, by the key that picks them.
+var copyFormats = []struct {
+	key, name string
+}{
+	{"j", "JSON"},
+	{"d", "DynamoDB JSON"},
+	{"k", "key"},
+	{"s", "SELECT"},
+	{"x", "DELETE"},
+}
+
+// copyPrompt lists the copy formats in the status bar while a format key is awaited.
+func copyPrompt() string {
+	parts := make([]string, len(copyFormats))
+	for i, f := range copyFormats {
+		parts[i] = f.key + " " + f.name
+	}
+	return "Copy item as: " + strings.Join(parts, " · ") + " (esc cancels)"
+}
+
+// dynamoJSON converts an item value to the typed wire format used by the AWS
+// CLI and console, e.g. {"S": "abc"} or {"N": "12"}. Sets stay sets, which
+// attributevalue.Marshal would turn into lists.
+func dynamoJSON(v interface{}) interface{} {
+	switch t := v.(type) {
+	case nil:
+		return map[string]interface{}{"NULL": true}
+	case string:
+		return map[string]interface{}{"S": t}
+	case bool:
+		return map[string]interface{}{"BOOL": t}
+	case float64, float32, int, int32, int64:
+		return map[string]interface{}{"N": strconv.FormatFloat(toFloat(t), 'f', -1, 64)}
+	case []byte:
+		return map[string]interface{}{"B": t}
+	case []string:
+		return map[string]interface{}{"SS": t}
+	case []float64:
+		ns := make([]string, len(t))
+		for i, n := range t {
+			ns[i] = strconv.FormatFloat(n, 'f', -1, 64)
+		}
+		return map[string]interface{}{"NS": ns}
+	case [][]byte:
+		return map[string]interface{}{"BS": t}
+	case []interface{}:
+		l := make([]interface{}, len(t))
+		for i, e := range t {
+			l[i] = dynamoJSON(e)
+		}
+		return map[string]interface{}{"L": l}
+	case map[string]interface{}:
+		return map[string]interface{}{"M": dynamoJSONMap(t)}
+	}
+	return map[string]interface{}{"S": fmt.Sprintf("%v", v)}
+}
+
+func dynamoJSONMap(m map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		out[k] = dynamoJSON(v)
+	}
+	return out
+}
+
+// quoteIdent quotes a table or attribute name for PartiQL.
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// keyCondition renders the WHERE clause that selects exactly item.
+func keyCondition(item Item, t Table) (string, error) {
+	attrs := []string{t.PK}
+	if t.SK != "" {
+		attrs = append(attrs, t.SK)
+	}
+	var conds []string
+	for _, attr := range attrs {
+		lit, err := FormatPartiQLValue(item[attr])
+		if err != nil {
+			return "", fmt.Errorf("key %s: %w", attr, err)
+		}
+		conds = append(conds, quoteIdent(attr)+" = "+lit)
+	}
+	return strings.Join(conds, " AND "), nil
+}
+
+// formatItem renders item in the copy format picked by key.
+func formatItem(key string, item Item, t Table) (string, error) {
+	switch key {
+	case "j":
+		b, err := json.MarshalIndent(item, "", "  ")
+		return string(b), err
+	case "d":
+		b, err := json.MarshalIndent(dynamoJSONMap(item), "", "  ")
+		return string(b), err
+	case "k":
+		b, err := json.Marshal(itemKey(item, t))
+		return string(b), err
+	case "s", "x":
+		where, err := keyCondition(item, t)
+		if err != nil {
+			return "", err
+		}
+		if key == "s" {
+			return fmt.Sprintf("SELECT * FROM %s WHERE %s", quoteIdent(t.Name), where), nil
+		}
+		return fmt.Sprintf("DELETE FROM %s WHERE %s", quoteIdent(t.Name), where), nil
+	}
+	return "", fmt.Errorf("unknown copy format %q", key)
+}
+
+// copyItem handles the key pressed after y: it copies the selected item in
+// that format, or cancels on any other key.
+func (m *model) copyItem(key string) tea.Cmd {
+	m.copyPending = false
+	for _, f := range copyFormats {
+		if f.key != key {
+			continue
+		}
+		t := m.tables[m.tableCursor]
+		text, err := formatItem(key, m.items[m.itemCursor], t)
+		if err != nil {
+			m.notice = fmt.Sprintf("Copy failed: %v", err)
+			return nil
+		}
+		return copyCmd(text, "item as "+f.name)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatItem(t *testing.T) {
+	table := Table{Name: "orders", PK: "id", SK: "ts"}
+	item := Item{"id": "o'1", "ts": 5.0, "tags": []string{"a"}, "gone": nil}
+
+	sel, err := formatItem("s", item, table)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `SELECT * FROM "orders" WHERE "id" = 'o''1' AND "ts" = 5`; sel != want {
+		t.Errorf("select = %s, want %s", sel, want)
+	}
+
+	del, _ := formatItem("x", item, table)
+	if !strings.HasPrefix(del, `DELETE FROM "orders" WHERE`) {
+		t.Errorf("delete = %s", del)
+	}
+
+	ddb, err := formatItem("d", item, table)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"N": "5"`, `"SS": [`, `"NULL": true`, `"S": "o'1"`} {
+		if !strings.Contains(ddb, want) {
+			t.Errorf("DynamoDB JSON missing %s:\n%s", want, ddb)
+		}
+	}
+
+	if _, err := formatItem("s", Item{"id": []byte("x"), "ts": 1.0}, table); err == nil {
+		t.Error("binary key should not format as PartiQL")
+	}
+}
+
+func TestOSC52CopyWritesToProgramOutput(t *testing.T) {
+	t.Setenv("TMUX", "")
+	t.Setenv("STY", "")
+	var out strings.Builder
+	c := &osc52Copy{text: "hello"}
+	c.SetStdout(&out)
+	if err := c.Run(); err != nil {
+		t.Fatal(err)
+	}
+	// "hello" in base64, wrapped in OSC 52 for the clipboard
+	if want := "\x1b]52;c;aGVsbG8=\x07"; out.String() != want {
+		t.Errorf("wrote %q, want %q", out.String(), want)
+	}
+}
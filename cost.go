@@ -0,0 +1,206 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// On-demand request pricing in USD per million request units (us-east-1,
+// Standard table class). Other regions differ slightly, so every figure
+// derived from these is shown as an approximation.
+const (
+	onDemandReadPricePerMillion  = 0.125
+	onDemandWritePricePerMillion = 0.625
+)
+
+const billingPayPerRequest = "PAY_PER_REQUEST"
+
+// Item size units DynamoDB rounds up to when charging.
+const (
+	readUnitBytes  = 4 * 1024
+	writeUnitBytes = 1024
+)
+
+func (c Capacity) plus(o Capacity) Capacity {
+	return Capacity{ReadUnits: c.ReadUnits + o.ReadUnits, WriteUnits: c.WriteUnits + o.WriteUnits}
+}
+
+func (c Capacity) isZero() bool {
+	return c.ReadUnits == 0 && c.WriteUnits == 0
+}
+
+// onDemandCost is what c would cost on a PAY_PER_REQUEST table.
+func (c Capacity) onDemandCost() float64 {
+	return c.ReadUnits*onDemandReadPricePerMillion/1e6 + c.WriteUnits*onDemandWritePricePerMillion/1e6
+}
+
+func (c Capacity) String() string {
+	return fmt.Sprintf("%.1f RCU / %.1f WCU", c.ReadUnits, c.WriteUnits)
+}
+
+// formatCost renders small dollar amounts without collapsing them to $0.00.
+func formatCost(usd float64) string {
+	if usd == 0 {
+		return "$0"
+	}
+	if usd < 0.01 {
+		return fmt.Sprintf("$%.6f", usd)
+	}
+	return fmt.Sprintf("$%.2f", usd)
+}
+
+func (t Table) isOnDemand() bool {
+	return t.BillingMode == billingPayPerRequest
+}
+
+// avgItemSize is DescribeTable's size estimate divided by its item count.
+// DescribeTable refreshes these roughly every six hours, so fall back to 1KB
+// for new or empty tables.
+func (t Table) avgItemSize() float64 {
+	if t.ItemCount <= 0 || t.SizeBytes <= 0 {
+		return 1024
+	}
+	return float64(t.SizeBytes) / float64(t.ItemCount)
+}
+
+// eventuallyConsistentRCU is the cost of reading n bytes with an eventually
+// consistent read, which is what ExecuteStatement and Scan use by default.
+func eventuallyConsistentRCU(bytes float64) float64 {
+	return math.Max(1, math.Ceil(bytes/readUnitBytes)) * 0.5
+}
+
+func itemWCU(bytes float64) float64 {
+	return math.Max(1, math.Ceil(bytes/writeUnitBytes))
+}
+
+// estimateScan is the read capacity of a full table scan. A scan is charged on
+// the total data read, not per item.
+func estimateScan(t Table) Capacity {
+	return Capacity{ReadUnits: eventuallyConsistentRCU(float64(t.SizeBytes))}
+}
+
+// describeScan explains what a full scan of t will read and cost. On-demand
+// tables get a dollar figure; provisioned tables are charged in throughput
+// instead, so show how long the scan would saturate the provisioned RCU.
+func describeScan(t Table) string {
+	est := estimateScan(t)
+	s := fmt.Sprintf("Scan reads ~%d items (%s) ≈ %.0f RCU", t.ItemCount, formatBytes(t.SizeBytes), est.ReadUnits)
+	switch {
+	case t.isOnDemand():
+		s += fmt.Sprintf(", ≈ %s on-demand", formatCost(est.onDemandCost()))
+	case t.ReadCapacity > 0:
+		secs := est.ReadUnits / float64(t.ReadCapacity)
+		s += fmt.Sprintf(", ≈ %.0fs of the table's %d provisioned RCU", math.Ceil(secs), t.ReadCapacity)
+	}
+	return s
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// scanPageItems is how many items ScanTable returns per loaded page.
+const scanPageItems = 1000
+
+// estimateScanPages is the read capacity of scanning the first pages pages
+// of t again, which is never more than a full scan.
+func estimateScanPages(t Table, pages int) Capacity {
+	bytes := float64(max(pages, 1)*scanPageItems) * t.avgItemSize()
+	return Capacity{ReadUnits: math.Min(eventuallyConsistentRCU(bytes), estimateScan(t).ReadUnits)}
+}
+
+// estimateStatements guesses the capacity of running PartiQL statements against t.
+// Statements that look like scans are charged as a full table read.
+func estimateStatements(t Table, statements []string) Capacity {
+	var est Capacity
+	avg := t.avgItemSize()
+	for _, sql := range statements {
+		if isLikelyScan(sql, t.PK) {
+			est = est.plus(estimateScan(t))
+			if !isMutationStatement(sql) {
+				continue
+			}
+			// A keyless UPDATE/DELETE may touch every item
+			est.WriteUnits += itemWCU(avg) * float64(max(t.ItemCount, 1))
+			continue
+		}
+		if isMutationStatement(sql) {
+			est.WriteUnits += itemWCU(avg)
+		} else {
+			est.ReadUnits += eventuallyConsistentRCU(avg)
+		}
+	}
+	return est
+}
+
+// estimatePlanRead guesses the read capacity of a plan's read step.
+func estimatePlanRead(t Table, p *PlanBlock) Capacity {
+	if p.Aggregate != nil && p.Aggregate.Source == aggregateLoaded {
+		return Capacity{} // Nothing is read
+	}
+	if p.Read.RequiresScan || isLikelyScan(p.Read.Partiql, t.PK) {
+		return estimateScan(t)
+	}
+	return estimateStatements(t, []string{p.Read.Partiql})
+}
+
+// estimateBulkWrite is the cost of writing one key-bounded statement per item.
+func estimateBulkWrite(t Table, n int) Capacity {
+	return Capacity{WriteUnits: itemWCU(t.avgItemSize()) * float64(n)}
+}
+
+// describeEstimate is the one-line summary shown in confirmation dialogs.
+func describeEstimate(t Table, est Capacity) string {
+	s := fmt.Sprintf("Estimated: ~%s", est)
+	if t.isOnDemand() {
+		s += fmt.Sprintf(" (≈ %s on-demand)", formatCost(est.onDemandCost()))
+	}
+	return s
+}
+
+// capacityErr is the error of a call that consumed capacity before failing,
+// such as a batch with one bad statement or a write whose re-scan timed out.
+type capacityErr struct {
+	error
+	capacity Capacity
+}
+
+// failed reports err with what was consumed on the way to it, so the session
+// totals still count capacity that a failed call was charged for.
+func failed(err error, used Capacity) tea.Msg {
+	if used.isZero() {
+		return errMsg(err)
+	}
+	return errMsg(capacityErr{err, used})
+}
+
+// failedCapacity is what the call behind an errMsg consumed.
+func failedCapacity(err error) Capacity {
+	var ce capacityErr
+	if errors.As(err, &ce) {
+		return ce.capacity
+	}
+	return Capacity{}
+}
+
+// trackCapacity records what the last operation consumed and adds it to the
+// session total shown in the status bar.
+func (m *model) trackCapacity(c Capacity) {
+	if c.isZero() {
+		return
+	}
+	m.lastCapacity = c
+	m.sessionCapacity = m.sessionCapacity.plus(c)
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEstimateScan(t *testing.T) {
+	tbl := Table{Name: "orders", PK: "id", ItemCount: 1000, SizeBytes: 8 * 1024 * 1024}
+
+	// 8 MiB / 4 KiB = 2048 read units, halved for eventually consistent reads
+	if got := estimateScan(tbl).ReadUnits; got != 1024 {
+		t.Errorf("estimateScan = %v RCU, want 1024", got)
+	}
+
+	// An empty table still costs the minimum half unit
+	if got := estimateScan(Table{}).ReadUnits; got != 0.5 {
+		t.Errorf("estimateScan(empty) = %v RCU, want 0.5", got)
+	}
+}
+
+func TestEstimateStatements(t *testing.T) {
+	tbl := Table{Name: "orders", PK: "id", ItemCount: 100, SizeBytes: 100 * 2048}
+
+	keyed := estimateStatements(tbl, []string{`SELECT * FROM "orders" WHERE "id" = 'a'`})
+	if keyed.ReadUnits != 0.5 || keyed.WriteUnits != 0 {
+		t.Errorf("keyed select = %v, want 0.5 RCU / 0 WCU", keyed)
+	}
+
+	// 2 KiB items take two write units each
+	write := estimateStatements(tbl, []string{`UPDATE "orders" SET "a" = 1 WHERE "id" = 'a'`})
+	if write.WriteUnits != 2 {
+		t.Errorf("keyed update = %v, want 2 WCU", write)
+	}
+
+	scan := estimateStatements(tbl, []string{`SELECT * FROM "orders"`})
+	if scan != estimateScan(tbl) {
+		t.Errorf("unkeyed select = %v, want a full scan %v", scan, estimateScan(tbl))
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	cases := map[int64]string{
+		512:             "512 B",
+		2048:            "2.0 KiB",
+		5 * 1024 * 1024: "5.0 MiB",
+	}
+	for in, want := range cases {
+		if got := formatBytes(in); got != want {
+			t.Errorf("formatBytes(%d) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestFailedKeepsCapacity(t *testing.T) {
+	m := initialModel(nil)
+	used := Capacity{ReadUnits: 3, WriteUnits: 25}
+	m.Update(failed(errors.New("statement 26 failed"), used))
+	if m.sessionCapacity != used {
+		t.Errorf("session = %v after a failed batch, want %v", m.sessionCapacity, used)
+	}
+	if m.err == nil || m.err.Error() != "statement 26 failed" {
+		t.Errorf("err = %v, want the batch's error", m.err)
+	}
+}
@@ -33,27 +33,8 @@ func openEditor(item Item, isNew bool) tea.Cmd {
 		return func() tea.Msg { return editorFinishedMsg{err: err} }
 	}
 
-	// Open editor
-	editor := os.Getenv("EDITOR")
-	if editor == "" {
-		// Try to find a suitable editor
-		editors := []string{"nvim", "vim", "nano", "vi"}
-		for _, e := range editors {
-			if _, err := exec.LookPath(e); err == nil {
-				editor = e
-				break
-			}
-		}
-		// Fallback if nothing found
-		if editor == "" {
-			editor = "nvim" 
-		}
-	}
-
-	c := exec.Command(editor, f.Name())
-	
 	// tea.ExecProcess returns a tea.Cmd directly.
-	return tea.ExecProcess(c, func(err error) tea.Msg {
+	return tea.ExecProcess(editorCommand(f.Name()), func(err error) tea.Msg {
 		if err != nil {
 			os.Remove(f.Name())
 			return editorFinishedMsg{err: err}
@@ -90,3 +71,23 @@ func openEditor(item Item, isNew bool) tea.Cmd {
 		return editorFinishedMsg{newItem: newItem, isNew: isNew}
 	})
 }
+
+// editorCommand opens path in $EDITOR, or the first common editor found.
+func editorCommand(path string) *exec.Cmd {
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		// Try to find a suitable editor
+		editors := []string{"nvim", "vim", "nano", "vi"}
+		for _, e := range editors {
+			if _, err := exec.LookPath(e); err == nil {
+				editor = e
+				break
+			}
+		}
+		// Fallback if nothing found
+		if editor == "" {
+			editor = "nvim"
+		}
+	}
+	return exec.Command(editor, path)
+}
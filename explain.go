@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	defaultExplainMaxItems = 25    // Items "explain results" sends unless Config.ExplainMaxItems says otherwise
+	maxExplainChars        = 24000 // The item sample is cut at this many characters of JSON
+)
+
+// explanation is Bedrock's summary of the items loaded in a tab.
+type explanation struct {
+	text    string
+	sent    int    // Items sent to Bedrock
+	dropped int    // Items left out because each alone was over maxExplainChars
+	total   int    // Items that were on screen
+	source  string // What returned the items
+	scroll  int
+}
+
+// describeResult says in plain English what running a result will read and
+// change, from the statements themselves rather than from the model.
+func describeResult(r LLMResult, t Table, loaded int) []string {
+	if r.Mode != "plan" || r.Plan == nil {
+		lines := make([]string, len(r.Statements))
+		for i, s := range r.Statements {
+			lines[i] = describeStatement(s, t)
+		}
+		if len(lines) > 1 {
+			lines = append(lines, fmt.Sprintf("The %d statements run together as one batch.", len(lines)))
+		}
+		return lines
+	}
+
+	p := r.Plan
+	var lines []string
+	switch {
+	case p.Aggregate != nil && p.Aggregate.Source == aggregateLoaded:
+		lines = append(lines, fmt.Sprintf("Uses the %d items shown in this tab; nothing is read from DynamoDB.", loaded))
+	case p.Aggregate != nil:
+		lines = append(lines, strings.Replace(describeStatement(p.Read.Partiql, t), "Reads", "Reads every page of", 1))
+	default:
+		lines = append(lines, describeStatement(p.Read.Partiql, t))
+	}
+	switch {
+	case p.Aggregate != nil:
+		lines = append(lines, fmt.Sprintf("Then computes %s on this machine; nothing is written.", p.Aggregate.describe()))
+	case p.Write == nil:
+		lines = append(lines, "Nothing is written.")
+	case p.Write.Action == "compute":
+		var sets []string
+		for _, s := range p.Write.PerItem.Set {
+			sets = append(sets, s.Path+" = "+s.Expr)
+		}
+		lines = append(lines, fmt.Sprintf("Then computes %s for each item found and shows the updates before anything is written.", strings.Join(sets, ", ")))
+	case p.Write.Action == "delete":
+		lines = append(lines, "Then deletes each item found, after showing how many there are.")
+	default:
+		lines = append(lines, fmt.Sprintf("Then changes each item found (%s), after showing how many there are.", writeClause(p.Write.PerItem.PartiqlTemplate)))
+	}
+	return lines
+}
+
+// describeStatement says what one PartiQL statement does to t.
+func describeStatement(sql string, t Table) string {
+	s := strings.Join(strings.Fields(sql), " ")
+	upper := strings.ToUpper(s)
+	scope := "every item of " + t.Name
+	if i := strings.Index(upper, " WHERE "); i >= 0 {
+		scope = "the items of " + t.Name + " where " + s[i+len(" WHERE "):]
+	}
+	scan := ""
+	if isLikelyScan(s, t.PK) {
+		scan = " (a full table scan)"
+	}
+	verb, _, _ := strings.Cut(upper, " ")
+	switch verb {
+	case "SELECT":
+		return fmt.Sprintf("Reads %s%s.", scope, scan)
+	case "INSERT":
+		return fmt.Sprintf("Inserts a new item into %s.", t.Name)
+	case "UPDATE":
+		return fmt.Sprintf("Changes %s (%s)%s.", scope, writeClause(s), scan)
+	case "DELETE":
+		return fmt.Sprintf("Deletes %s%s.", scope, scan)
+	}
+	return "Runs " + s
+}
+
+// writeClause is the SET/REMOVE part of an UPDATE statement or template.
+func writeClause(sql string) string {
+	s := strings.Join(strings.Fields(sql), " ")
+	upper := strings.ToUpper(s)
+	start := strings.Index(upper, " SET ")
+	if r := strings.Index(upper, " REMOVE "); start < 0 || (r >= 0 && r < start) {
+		start = r
+	}
+	if start < 0 {
+		return s
+	}
+	end := len(s)
+	if i := strings.Index(upper, " WHERE "); i > start {
+		end = i
+	}
+	return strings.TrimSpace(s[start:end])
+}
+
+// explainSample renders up to limit items as JSON lines for the prompt,
+// stopping early when the text would grow past maxExplainChars. An item that
+// is over the limit on its own is left out and counted as dropped.
+func explainSample(items []Item, limit int) (sample string, sent, dropped int) {
+	var b strings.Builder
+	for _, item := range items[:min(len(items), limit)] {
+		line, err := json.Marshal(item)
+		if err != nil {
+			continue
+		}
+		if len(line)+1 > maxExplainChars {
+			dropped++
+			continue
+		}
+		if b.Len()+len(line)+1 > maxExplainChars {
+			break
+		}
+		b.Write(line)
+		b.WriteString("\n")
+		sent++
+	}
+	return b.String(), sent, dropped
+}
+
+// explainLimit is how many items an explanation may send to Bedrock.
+func (m *model) explainLimit() int {
+	if m.config.ExplainMaxItems > 0 {
+		return m.config.ExplainMaxItems
+	}
+	return defaultExplainMaxItems
+}
+
+// startExplain sends a sample of the items on screen to Bedrock for a
+// summary.
+func (m *model) startExplain() tea.Cmd {
+	var items []Item
+	for _, i := range m.visibleItems() {
+		items = append(items, m.items[i])
+	}
+	if len(items) == 0 {
+		m.notice = "No items to explain"
+		return nil
+	}
+	t := m.tables[m.tableCursor]
+	sample, sent, dropped := explainSample(items, m.explainLimit())
+	if sent == 0 {
+		m.notice = fmt.Sprintf("Nothing to explain: each item is over the %d-character limit for Bedrock", maxExplainChars)
+		return nil
+	}
+	source := m.promptContext(t).LoadedFrom
+	question := ""
+	if m.isCustomQuery {
+		question = m.lastQuestion
+	}
+
+	m.previousView = m.view
+	m.loading = true
+	m.view = viewLoading
+	m.statusMessage = fmt.Sprintf("Asking Bedrock to explain %d items...", sent)
+	api, total := m.aws, len(items)
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		text, err := api.ExplainItems(ctx, t, source, question, sample, sent, total)
+		return explainedMsg{result: &explanation{text: text, sent: sent, dropped: dropped, total: total, source: source}, err: err}
+	}
+}
+
+func (m *model) updateExplain(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	e := m.explanation
+	switch msg.String() {
+	case "esc", "q":
+		m.view = m.previousView
+		m.explanation = nil
+	case "up", "k":
+		e.scroll = max(e.scroll-1, 0)
+	case "down", "j":
+		e.scroll++
+	case "ctrl+d":
+		e.scroll += 10
+	case "ctrl+u":
+		e.scroll = max(e.scroll-10, 0)
+	case "y":
+		return m, copyCmd(e.text, "explanation")
+	}
+	if m.explanation != nil {
+		body, height := m.explainBody()
+		e.scroll = min(e.scroll, max(len(body)-height, 0))
+	}
+	return m, nil
+}
+
+// explainBody wraps the explanation to the window and returns its lines
+// with how many of them fit.
+func (m model) explainBody() ([]string, int) {
+	body := strings.Split(lipgloss.NewStyle().Width(m.width-6).Render(m.explanation.text), "\n")
+	return body, max(m.height-10, 1)
+}
+
+// renderExplain draws Bedrock's summary of the loaded items.
+func (m model) renderExplain() string {
+	e := m.explanation
+	header := m.renderHeader("Explain: " + m.tables[m.tableCursor].Name)
+	dim := lipgloss.NewStyle().Foreground(textDim)
+
+	summary := fmt.Sprintf("Bedrock's summary of %d of %d items · from %s", e.sent, e.total, e.source)
+	if e.dropped > 0 {
+		summary += fmt.Sprintf(" · %d too large to send were left out", e.dropped)
+	} else if e.sent < e.total {
+		summary += fmt.Sprintf(" · only the first %d were sent", e.sent)
+	}
+
+	// A resize can leave the scroll past the end until the next key
+	body, height := m.explainBody()
+	scroll := min(e.scroll, max(len(body)-height, 0))
+	body = body[scroll:min(scroll+height, len(body))]
+
+	lines := []string{dim.Render(truncateText(summary, m.width-4)), ""}
+	lines = append(lines, body...)
+	lines = append(lines, "", dim.Render("j/k scroll · y copy · esc back"))
+
+	return lipgloss.JoinVertical(lipgloss.Left, header, m.renderTabs(), lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestDescribeResult(t *testing.T) {
+	table := Table{Name: "users", PK: "id", SK: "ts"}
+	sql := LLMResult{Mode: "sql", Statements: []string{
+		`SELECT * FROM "users" WHERE "id" = 'a'`,
+		`UPDATE "users" SET "n" = 1 REMOVE "x" WHERE "id" = 'a' AND "ts" = 1`,
+		`DELETE FROM "users"`,
+	}}
+	want := []string{
+		`Reads the items of users where "id" = 'a'.`,
+		`Changes the items of users where "id" = 'a' AND "ts" = 1 (SET "n" = 1 REMOVE "x").`,
+		`Deletes every item of users (a full table scan).`,
+		`The 3 statements run together as one batch.`,
+	}
+	if got := describeResult(sql, table, 0); !reflect.DeepEqual(got, want) {
+		t.Errorf("sql:\n%q\nwant\n%q", got, want)
+	}
+
+	plan := LLMResult{Mode: "plan", Plan: &PlanBlock{
+		Operation: "aggregate",
+		Aggregate: &AggregateBlock{Source: aggregateLoaded, GroupBy: []string{"status"}, Metrics: []AggregateMetric{{Func: "count", Path: "*"}}},
+	}}
+	got := describeResult(plan, table, 12)
+	if len(got) != 2 || !strings.Contains(got[0], "12 items shown in this tab") || !strings.Contains(got[1], "nothing is written") {
+		t.Errorf("aggregate: %q", got)
+	}
+}
+
+func TestWriteClause(t *testing.T) {
+	cases := map[string]string{
+		`UPDATE "t" SET "a" = 1 WHERE "id" = {{PK}}`:            `SET "a" = 1`,
+		`UPDATE "t" REMOVE "b" SET "a" = 1 WHERE "id" = {{PK}}`: `REMOVE "b" SET "a" = 1`,
+		`DELETE FROM "t" WHERE "id" = {{PK}}`:                   `DELETE FROM "t" WHERE "id" = {{PK}}`,
+	}
+	for in, want := range cases {
+		if got := writeClause(in); got != want {
+			t.Errorf("%s: %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestExplainSample(t *testing.T) {
+	items := []Item{{"id": "a"}, {"id": "b"}, {"id": strings.Repeat("x", maxExplainChars)}, {"id": "d"}}
+	text, sent, dropped := explainSample(items, 10)
+	if sent != 3 || dropped != 1 || text != "{\"id\":\"a\"}\n{\"id\":\"b\"}\n{\"id\":\"d\"}\n" {
+		t.Errorf("sent %d, dropped %d: %q", sent, dropped, text)
+	}
+	if _, sent, _ := explainSample(items, 1); sent != 1 {
+		t.Errorf("limit 1 sent %d", sent)
+	}
+
+	// A large first item doesn't get past the cap either
+	if text, sent, dropped := explainSample(items[2:3], 10); sent != 0 || dropped != 1 || text != "" {
+		t.Errorf("oversized first item: sent %d, dropped %d, %d chars", sent, dropped, len(text))
+	}
+
+	// The sample stops once the next item would push it over the cap
+	half := Item{"id": strings.Repeat("y", maxExplainChars/2)}
+	if text, sent, dropped := explainSample([]Item{half, half, {"id": "z"}}, 10); sent != 1 || dropped != 0 || len(text) > maxExplainChars {
+		t.Errorf("full sample: sent %d, dropped %d, %d chars", sent, dropped, len(text))
+	}
+}
+
+func TestExplainScrollStopsAtTheEnd(t *testing.T) {
+	e := &explanation{text: strings.Repeat("line\n", 29) + "line", sent: 1, total: 1, source: "loaded items"}
+	m := model{tables: []Table{{Name: "t", PK: "id"}}, session: newSession(), width: 80, height: 20, explanation: e, view: viewExplain}
+	m.sessions = []*session{m.session}
+
+	// 30 lines in a 10-line window scroll at most 20
+	for i := 0; i < 5; i++ {
+		m.updateExplain(tea.KeyMsg{Type: tea.KeyCtrlD})
+	}
+	if e.scroll != 20 {
+		t.Errorf("scroll = %d, want 20", e.scroll)
+	}
+	m.updateExplain(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("k")})
+	if e.scroll != 19 {
+		t.Errorf("scroll after one line up = %d, want 19", e.scroll)
+	}
+
+	// Drawing a taller window doesn't move it
+	m.height = 60
+	m.renderExplain()
+	if e.scroll != 19 {
+		t.Errorf("scroll after rendering = %d, want 19", e.scroll)
+	}
+}
@@ -0,0 +1,783 @@
+package main
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// The per-item expression language of computed updates. An expression reads
+// the item's attributes by path and yields the new value of one attribute:
+//
+//	price * 1.1                      arithmetic: + - * / %
+//	firstName || ' ' || lastName     string concatenation
+//	upper(name), lower(name)         string functions
+//	now(), epoch(), uuid()           evaluated per item
+//	if(age >= 18, 'adult', 'minor')  conditionals
+//
+// Paths are dotted for nested maps ("address.city"), "[n]" picks a list
+// element and double quotes take names with other characters
+// ("first name"). A missing attribute is null.
+
+// exprEnv is shared by every item of one computed update, so now() gives the
+// same time on all of them.
+type exprEnv struct {
+	now time.Time
+}
+
+type exprNode interface {
+	eval(item Item, env *exprEnv) (interface{}, error)
+}
+
+type exprLiteral struct{ value interface{} }
+
+type exprPath struct{ path string }
+
+type exprUnary struct {
+	op string
+	x  exprNode
+}
+
+type exprBinary struct {
+	op   string
+	x, y exprNode
+}
+
+type exprCall struct {
+	name string
+	args []exprNode
+}
+
+// exprFuncs lists the functions with their minimum and maximum argument
+// counts; -1 means any number.
+var exprFuncs = map[string][2]int{
+	"upper": {1, 1}, "lower": {1, 1}, "trim": {1, 1}, "length": {1, 1},
+	"concat": {1, -1}, "replace": {3, 3}, "substr": {2, 3},
+	"string": {1, 1}, "number": {1, 1},
+	"round": {1, 2}, "floor": {1, 1}, "ceil": {1, 1}, "abs": {1, 1},
+	"min": {2, -1}, "max": {2, -1},
+	"now": {0, 0}, "epoch": {0, 0}, "uuid": {0, 0}, "random_string": {1, 1}, "random_int": {2, 2},
+	"if": {3, 3}, "coalesce": {1, -1}, "exists": {1, 1},
+}
+
+// parseExpr compiles an expression; it is checked before any item is read.
+func parseExpr(src string) (exprNode, error) {
+	toks, err := lexExpr(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &exprParser{toks: toks}
+	n, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokEOF {
+		return nil, fmt.Errorf("unexpected %q at %d", p.peek().text, p.peek().pos)
+	}
+	return n, nil
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokNumber
+	tokString
+	tokIdent  // Bare name, keyword or function
+	tokQuoted // "Quoted" attribute name
+	tokOp
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func lexExpr(src string) ([]token, error) {
+	var toks []token
+	r := []rune(src)
+	for i := 0; i < len(r); {
+		c := r[i]
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case unicode.IsDigit(c) || (c == '.' && i+1 < len(r) && unicode.IsDigit(r[i+1])):
+			j := i
+			for j < len(r) && (unicode.IsDigit(r[j]) || r[j] == '.' || r[j] == 'e' || r[j] == 'E' ||
+				((r[j] == '+' || r[j] == '-') && (r[j-1] == 'e' || r[j-1] == 'E'))) {
+				j++
+			}
+			toks = append(toks, token{tokNumber, string(r[i:j]), i})
+			i = j
+		case c == '\'' || c == '"':
+			var b strings.Builder
+			j := i + 1
+			for ; j < len(r); j++ {
+				if r[j] == c {
+					if j+1 < len(r) && r[j+1] == c { // Doubled quote escapes itself
+						b.WriteRune(c)
+						j++
+						continue
+					}
+					break
+				}
+				b.WriteRune(r[j])
+			}
+			if j >= len(r) {
+				return nil, fmt.Errorf("unterminated %c at %d", c, i)
+			}
+			kind := tokString
+			if c == '"' {
+				kind = tokQuoted
+			}
+			toks = append(toks, token{kind, b.String(), i})
+			i = j + 1
+		case unicode.IsLetter(c) || c == '_':
+			j := i
+			for j < len(r) && (unicode.IsLetter(r[j]) || unicode.IsDigit(r[j]) || r[j] == '_') {
+				j++
+			}
+			toks = append(toks, token{tokIdent, string(r[i:j]), i})
+			i = j
+		default:
+			op := string(c)
+			if i+1 < len(r) {
+				switch two := string(r[i : i+2]); two {
+				case "||", "<=", ">=", "!=", "<>", "==":
+					op = two
+				}
+			}
+			if !exprOps[op] {
+				return nil, fmt.Errorf("unexpected %q at %d", op, i)
+			}
+			toks = append(toks, token{tokOp, op, i})
+			i += len([]rune(op))
+		}
+	}
+	return append(toks, token{tokEOF, "end of expression", len(r)}), nil
+}
+
+var exprOps = map[string]bool{
+	"+": true, "-": true, "*": true, "/": true, "%": true, "(": true, ")": true, ",": true, ".": true, "[": true, "]": true,
+	"=": true, "==": true, "!=": true, "<>": true, "<": true, "<=": true, ">": true, ">=": true, "||": true,
+}
+
+type exprParser struct {
+	toks []token
+	pos  int
+}
+
+func (p *exprParser) peek() token { return p.toks[p.pos] }
+
+func (p *exprParser) next() token {
+	t := p.toks[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+// accept consumes the next token when it is the operator or keyword s.
+func (p *exprParser) accept(s string) bool {
+	t := p.peek()
+	if (t.kind == tokOp && t.text == s) || (t.kind == tokIdent && strings.EqualFold(t.text, s)) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *exprParser) expect(op string) error {
+	if !p.accept(op) {
+		return fmt.Errorf("expected %q at %d, got %q", op, p.peek().pos, p.peek().text)
+	}
+	return nil
+}
+
+func (p *exprParser) parseOr() (exprNode, error) {
+	return p.parseLeft(p.parseAnd, "or")
+}
+
+func (p *exprParser) parseAnd() (exprNode, error) {
+	return p.parseLeft(p.parseNot, "and")
+}
+
+func (p *exprParser) parseNot() (exprNode, error) {
+	if p.accept("not") {
+		x, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return exprUnary{"not", x}, nil
+	}
+	return p.parseCompare()
+}
+
+func (p *exprParser) parseCompare() (exprNode, error) {
+	x, err := p.parseConcat()
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range []string{"=", "==", "!=", "<>", "<", "<=", ">", ">="} {
+		if p.accept(op) {
+			y, err := p.parseConcat()
+			if err != nil {
+				return nil, err
+			}
+			switch op {
+			case "==":
+				op = "="
+			case "<>":
+				op = "!="
+			}
+			return exprBinary{op, x, y}, nil
+		}
+	}
+	return x, nil
+}
+
+func (p *exprParser) parseConcat() (exprNode, error) {
+	return p.parseLeft(p.parseAdd, "||")
+}
+
+func (p *exprParser) parseAdd() (exprNode, error) {
+	return p.parseLeft(p.parseMul, "+", "-")
+}
+
+func (p *exprParser) parseMul() (exprNode, error) {
+	return p.parseLeft(p.parseUnary, "*", "/", "%")
+}
+
+// parseLeft parses a left-associative chain of operators ops over operands
+// parsed by sub.
+func (p *exprParser) parseLeft(sub func() (exprNode, error), ops ...string) (exprNode, error) {
+	x, err := sub()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		matched := ""
+		for _, op := range ops {
+			if p.accept(op) {
+				matched = strings.ToLower(op)
+				break
+			}
+		}
+		if matched == "" {
+			return x, nil
+		}
+		y, err := sub()
+		if err != nil {
+			return nil, err
+		}
+		x = exprBinary{matched, x, y}
+	}
+}
+
+func (p *exprParser) parseUnary() (exprNode, error) {
+	if p.accept("-") {
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return exprUnary{"-", x}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *exprParser) parsePrimary() (exprNode, error) {
+	t := p.next()
+	switch t.kind {
+	case tokNumber:
+		f, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("bad number %q at %d", t.text, t.pos)
+		}
+		return exprLiteral{f}, nil
+	case tokString:
+		return exprLiteral{t.text}, nil
+	case tokOp:
+		if t.text == "(" {
+			x, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			return x, p.expect(")")
+		}
+	case tokIdent:
+		switch strings.ToLower(t.text) {
+		case "true":
+			return exprLiteral{true}, nil
+		case "false":
+			return exprLiteral{false}, nil
+		case "null":
+			return exprLiteral{nil}, nil
+		}
+		if p.accept("(") {
+			return p.parseCall(t)
+		}
+		return p.parsePath(t.text)
+	case tokQuoted:
+		return p.parsePath(t.text)
+	}
+	return nil, fmt.Errorf("unexpected %q at %d", t.text, t.pos)
+}
+
+func (p *exprParser) parseCall(name token) (exprNode, error) {
+	fn := strings.ToLower(name.text)
+	arity, ok := exprFuncs[fn]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %s() at %d", name.text, name.pos)
+	}
+	var args []exprNode
+	if !p.accept(")") {
+		for {
+			x, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, x)
+			if p.accept(")") {
+				break
+			}
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if len(args) < arity[0] || (arity[1] >= 0 && len(args) > arity[1]) {
+		return nil, fmt.Errorf("%s() takes %s, got %d", fn, arityText(arity), len(args))
+	}
+	return exprCall{fn, args}, nil
+}
+
+func arityText(a [2]int) string {
+	switch {
+	case a[0] == a[1]:
+		return fmt.Sprintf("%d arguments", a[0])
+	case a[1] < 0:
+		return fmt.Sprintf("at least %d arguments", a[0])
+	}
+	return fmt.Sprintf("%d to %d arguments", a[0], a[1])
+}
+
+// parsePath reads the rest of an attribute path after its first name.
+func (p *exprParser) parsePath(first string) (exprNode, error) {
+	path := first
+	for {
+		switch {
+		case p.accept("."):
+			t := p.next()
+			if t.kind != tokIdent && t.kind != tokQuoted {
+				return nil, fmt.Errorf("expected an attribute name at %d", t.pos)
+			}
+			path += "." + t.text
+		case p.accept("["):
+			t := p.next()
+			if t.kind != tokNumber {
+				return nil, fmt.Errorf("expected a list index at %d", t.pos)
+			}
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+			path += "[" + t.text + "]"
+		default:
+			return exprPath{path}, nil
+		}
+	}
+}
+
+// exprReads lists the attribute paths an expression reads, each once, in
+// the order they appear.
+func exprReads(n exprNode) []string {
+	var paths []string
+	var walk func(exprNode)
+	walk = func(n exprNode) {
+		switch n := n.(type) {
+		case exprPath:
+			for _, p := range paths {
+				if p == n.path {
+					return
+				}
+			}
+			paths = append(paths, n.path)
+		case exprUnary:
+			walk(n.x)
+		case exprBinary:
+			walk(n.x)
+			walk(n.y)
+		case exprCall:
+			for _, a := range n.args {
+				walk(a)
+			}
+		}
+	}
+	walk(n)
+	return paths
+}
+
+func (n exprLiteral) eval(Item, *exprEnv) (interface{}, error) { return n.value, nil }
+
+func (n exprPath) eval(item Item, _ *exprEnv) (interface{}, error) {
+	if values := pathValues(item, n.path); len(values) > 0 {
+		return values[0], nil
+	}
+	return nil, nil
+}
+
+func (n exprUnary) eval(item Item, env *exprEnv) (interface{}, error) {
+	x, err := n.x.eval(item, env)
+	if err != nil {
+		return nil, err
+	}
+	if n.op == "not" {
+		b, err := exprBool(x, "not")
+		return !b, err
+	}
+	f, err := exprNumber(x, "-")
+	return -f, err
+}
+
+func (n exprBinary) eval(item Item, env *exprEnv) (interface{}, error) {
+	x, err := n.x.eval(item, env)
+	if err != nil {
+		return nil, err
+	}
+	// and/or short-circuit so a guard can protect the other side
+	if n.op == "and" || n.op == "or" {
+		b, err := exprBool(x, n.op)
+		if err != nil || b == (n.op == "or") {
+			return b, err
+		}
+		y, err := n.y.eval(item, env)
+		if err != nil {
+			return nil, err
+		}
+		return exprBool(y, n.op)
+	}
+	y, err := n.y.eval(item, env)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "||":
+		return exprString(x) + exprString(y), nil
+	case "=", "!=":
+		eq := (x == nil && y == nil) || (x != nil && y != nil && sortRank(x) == sortRank(y) && compareValues(x, y) == 0)
+		return eq == (n.op == "="), nil
+	case "<", "<=", ">", ">=":
+		if x == nil || y == nil {
+			return nil, fmt.Errorf("%s compares a missing value", n.op)
+		}
+		if sortRank(x) != sortRank(y) {
+			return nil, fmt.Errorf("%s compares %s with %s", n.op, dynamoType(x), dynamoType(y))
+		}
+		c := compareValues(x, y)
+		switch n.op {
+		case "<":
+			return c < 0, nil
+		case "<=":
+			return c <= 0, nil
+		case ">":
+			return c > 0, nil
+		}
+		return c >= 0, nil
+	}
+
+	a, err := exprNumber(x, n.op)
+	if err != nil {
+		return nil, err
+	}
+	b, err := exprNumber(y, n.op)
+	if err != nil {
+		return nil, err
+	}
+	var r float64
+	switch n.op {
+	case "+":
+		r = a + b
+	case "-":
+		r = a - b
+	case "*":
+		r = a * b
+	default:
+		if b == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		if n.op == "%" {
+			r = math.Mod(a, b)
+		} else {
+			r = a / b
+		}
+	}
+	return exprFinite(exprRound(r), fmt.Sprintf("%g %s %g", a, n.op, b))
+}
+
+// exprFinite rejects the infinities and NaN that float arithmetic can
+// produce, which DynamoDB can't store; what names the expression.
+func exprFinite(f float64, what string) (interface{}, error) {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return nil, fmt.Errorf("%s is not a finite number", what)
+	}
+	return f, nil
+}
+
+// exprRound drops binary float noise (6.6000000000000005) so computed values
+// are written the way a person would type them.
+func exprRound(f float64) float64 {
+	r, err := strconv.ParseFloat(strconv.FormatFloat(f, 'g', 15, 64), 64)
+	if err != nil {
+		return f
+	}
+	return r
+}
+
+func (n exprCall) eval(item Item, env *exprEnv) (interface{}, error) {
+	// if and coalesce only evaluate the arguments they need
+	switch n.name {
+	case "if":
+		c, err := n.args[0].eval(item, env)
+		if err != nil {
+			return nil, err
+		}
+		b, err := exprBool(c, "if")
+		if err != nil {
+			return nil, err
+		}
+		if b {
+			return n.args[1].eval(item, env)
+		}
+		return n.args[2].eval(item, env)
+	case "coalesce":
+		for _, a := range n.args {
+			v, err := a.eval(item, env)
+			if err != nil || v != nil {
+				return v, err
+			}
+		}
+		return nil, nil
+	}
+
+	args := make([]interface{}, len(n.args))
+	for i, a := range n.args {
+		v, err := a.eval(item, env)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = v
+	}
+
+	switch n.name {
+	case "upper", "lower", "trim":
+		s, err := exprText(args[0], n.name)
+		if err != nil {
+			return nil, err
+		}
+		switch n.name {
+		case "upper":
+			return strings.ToUpper(s), nil
+		case "lower":
+			return strings.ToLower(s), nil
+		}
+		return strings.TrimSpace(s), nil
+	case "length":
+		switch v := args[0].(type) {
+		case string:
+			return float64(len([]rune(v))), nil
+		case []interface{}:
+			return float64(len(v)), nil
+		case map[string]interface{}:
+			return float64(len(v)), nil
+		}
+		return nil, fmt.Errorf("length() needs a string, list or map, got %s", exprTypeName(args[0]))
+	case "concat":
+		var b strings.Builder
+		for _, a := range args {
+			b.WriteString(exprString(a))
+		}
+		return b.String(), nil
+	case "replace":
+		s, err := exprText(args[0], n.name)
+		if err != nil {
+			return nil, err
+		}
+		return strings.ReplaceAll(s, exprString(args[1]), exprString(args[2])), nil
+	case "substr":
+		s, err := exprText(args[0], n.name)
+		if err != nil {
+			return nil, err
+		}
+		r := []rune(s)
+		start, err := exprNumber(args[1], n.name)
+		if err != nil {
+			return nil, err
+		}
+		from := min(max(int(start), 0), len(r))
+		to := len(r)
+		if len(args) == 3 {
+			l, err := exprNumber(args[2], n.name)
+			if err != nil {
+				return nil, err
+			}
+			to = min(from+max(int(l), 0), len(r))
+		}
+		return string(r[from:to]), nil
+	case "string":
+		return exprString(args[0]), nil
+	case "number":
+		if s, ok := args[0].(string); ok {
+			f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+			if err != nil {
+				return nil, fmt.Errorf("number(): %q is not a number", s)
+			}
+			return exprFinite(f, fmt.Sprintf("number(%q)", s))
+		}
+		return exprNumber(args[0], n.name)
+	case "round", "floor", "ceil", "abs":
+		f, err := exprNumber(args[0], n.name)
+		if err != nil {
+			return nil, err
+		}
+		switch n.name {
+		case "floor":
+			return math.Floor(f), nil
+		case "ceil":
+			return math.Ceil(f), nil
+		case "abs":
+			return math.Abs(f), nil
+		}
+		digits := 0.0
+		if len(args) == 2 {
+			if digits, err = exprNumber(args[1], n.name); err != nil {
+				return nil, err
+			}
+		}
+		scale := math.Pow(10, digits)
+		return exprFinite(math.Round(f*scale)/scale, fmt.Sprintf("round(%g, %g)", f, digits))
+	case "min", "max":
+		best, err := exprNumber(args[0], n.name)
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range args[1:] {
+			f, err := exprNumber(a, n.name)
+			if err != nil {
+				return nil, err
+			}
+			if (n.name == "min") == (f < best) {
+				best = f
+			}
+		}
+		return best, nil
+	case "now":
+		return env.now.UTC().Format(time.RFC3339), nil
+	case "epoch":
+		return float64(env.now.Unix()), nil
+	case "uuid":
+		return newUUID()
+	case "random_string":
+		l, err := exprNumber(args[0], n.name)
+		if err != nil {
+			return nil, err
+		}
+		if l < 1 || l > 256 {
+			return nil, fmt.Errorf("random_string() length must be 1 to 256")
+		}
+		return randomString(int(l))
+	case "random_int":
+		lo, err := exprNumber(args[0], n.name)
+		if err != nil {
+			return nil, err
+		}
+		hi, err := exprNumber(args[1], n.name)
+		if err != nil {
+			return nil, err
+		}
+		if hi < lo {
+			return nil, fmt.Errorf("random_int() needs low <= high")
+		}
+		r, err := rand.Int(rand.Reader, big.NewInt(int64(hi)-int64(lo)+1))
+		if err != nil {
+			return nil, err
+		}
+		return float64(int64(lo) + r.Int64()), nil
+	case "exists":
+		return args[0] != nil, nil
+	}
+	return nil, fmt.Errorf("unknown function %s()", n.name)
+}
+
+func exprTypeName(v interface{}) string {
+	if v == nil {
+		return "a missing value"
+	}
+	return dynamoType(v)
+}
+
+func exprNumber(v interface{}, op string) (float64, error) {
+	if sortRank(v) != rankNumber {
+		return 0, fmt.Errorf("%s needs a number, got %s", op, exprTypeName(v))
+	}
+	return toFloat(v), nil
+}
+
+func exprBool(v interface{}, op string) (bool, error) {
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("%s needs a boolean, got %s", op, exprTypeName(v))
+	}
+	return b, nil
+}
+
+func exprText(v interface{}, op string) (string, error) {
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("%s() needs a string, got %s", op, exprTypeName(v))
+	}
+	return s, nil
+}
+
+// exprString is how a value reads when joined into a string; a missing
+// value joins as nothing.
+func exprString(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	}
+	return profileValue(v)
+}
+
+func newUUID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40 // Version 4
+	b[8] = b[8]&0x3f | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
+
+const randomAlphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+func randomString(n int) (string, error) {
+	out := make([]byte, n)
+	for i := range out {
+		r, err := rand.Int(rand.Reader, big.NewInt(int64(len(randomAlphabet))))
+		if err != nil {
+			return "", err
+		}
+		out[i] = randomAlphabet[r.Int64()]
+	}
+	return string(out), nil
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestExprEval(t *testing.T) {
+	item := Item{
+		"price":   12.5,
+		"qty":     3.0,
+		"name":    "Widget",
+		"first":   "Ada",
+		"last":    "Lovelace",
+		"age":     17.0,
+		"address": map[string]interface{}{"city": "Oslo"},
+		"tags":    []interface{}{"a", "b"},
+		"odd key": "x",
+	}
+	env := &exprEnv{now: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}
+
+	cases := []struct {
+		expr string
+		want interface{}
+	}{
+		{"price + 10", 22.5},
+		{"price * qty - 1", 36.5},
+		{"-(qty + 1) * 2", -8.0},
+		{"qty % 2", 1.0},
+		{"round(price / 3, 2)", 4.17},
+		{"upper(name)", "WIDGET"},
+		{"lower(name) || '-' || qty", "widget-3"},
+		{"first || ' ' || last", "Ada Lovelace"},
+		{"concat(first, missing, '!')", "Ada!"},
+		{"address.city", "Oslo"},
+		{"tags[1]", "b"},
+		{`"odd key"`, "x"},
+		{"if(age >= 18, 'adult', 'minor')", "minor"},
+		{"if(exists(nickname), nickname, first)", "Ada"},
+		{"coalesce(nickname, 'none')", "none"},
+		{"not (age > 20) and name = 'Widget'", true},
+		{"missing = null", true},
+		{"now()", "2024-05-01T12:00:00Z"},
+		{"epoch()", 1714564800.0},
+		{"length(tags) + length(name)", 8.0},
+		{"replace(name, 'dg', 'DG')", "WiDGet"},
+		{"substr(last, 0, 4)", "Love"},
+		{"number('7') + 1", 8.0},
+		{"max(price, qty, 20)", 20.0},
+		{"'it''s'", "it's"},
+	}
+	for _, c := range cases {
+		n, err := parseExpr(c.expr)
+		if err != nil {
+			t.Errorf("%s: parse: %v", c.expr, err)
+			continue
+		}
+		got, err := n.eval(item, env)
+		if err != nil {
+			t.Errorf("%s: eval: %v", c.expr, err)
+			continue
+		}
+		if got != c.want {
+			t.Errorf("%s = %#v, want %#v", c.expr, got, c.want)
+		}
+	}
+
+	n, _ := parseExpr("uuid()")
+	id, _ := n.eval(item, env)
+	if !regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`).MatchString(id.(string)) {
+		t.Errorf("uuid() = %v", id)
+	}
+	n, _ = parseExpr("random_string(12)")
+	if s, _ := n.eval(item, env); len(s.(string)) != 12 {
+		t.Errorf("random_string(12) = %v", s)
+	}
+}
+
+func TestExprErrors(t *testing.T) {
+	parse := []string{"price +", "upper()", "nope(1)", "'open", "price ! 2", "(price"}
+	for _, src := range parse {
+		if _, err := parseExpr(src); err == nil {
+			t.Errorf("%s: parsed", src)
+		}
+	}
+
+	item := Item{"name": "x", "price": 1.0}
+	eval := []string{"name + 1", "missing * 2", "price / 0", "upper(price)", "if(name, 1, 2)", "name < price",
+		"1e308 * 10", "-1e308 - 1e308", "number('Inf')", "number('-inf')", "number('NaN')", "round(price, 400)"}
+	for _, src := range eval {
+		n, err := parseExpr(src)
+		if err != nil {
+			t.Errorf("%s: parse: %v", src, err)
+			continue
+		}
+		if _, err := n.eval(item, &exprEnv{}); err == nil {
+			t.Errorf("%s: no error", src)
+		}
+	}
+}
+
+func TestExprNonFiniteNamesExpression(t *testing.T) {
+	cases := map[string]string{
+		"1e308 * 10":    "1e+308 * 10 is not a finite number",
+		"number('NaN')": `number("NaN") is not a finite number`,
+	}
+	for src, want := range cases {
+		n, err := parseExpr(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := n.eval(Item{}, &exprEnv{}); err == nil || err.Error() != want {
+			t.Errorf("%s: error %v, want %q", src, err, want)
+		}
+	}
+
+	// The preview skips the item instead of writing Infinity
+	tbl := Table{Name: "t", PK: "id"}
+	sets, err := compileComputed([]ComputedSet{{Path: "n", Expr: "n * 10"}}, tbl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := buildComputed([]Item{{"id": "a", "n": 1e308}, {"id": "b", "n": 1.0}}, sets, tbl, time.Now())
+	if len(w.statements) != 1 || len(w.skipped) != 1 || w.skipped[0] != "a: n: 1e+308 * 10 is not a finite number" {
+		t.Errorf("statements %q, skipped %q; want b updated and a skipped", w.statements, w.skipped)
+	}
+}
+
+func TestExprRound(t *testing.T) {
+	n, _ := parseExpr("price * 1.1")
+	got, _ := n.eval(Item{"price": 6.0}, &exprEnv{})
+	if got != 6.6 {
+		t.Errorf("6 * 1.1 = %v, want 6.6", got)
+	}
+}
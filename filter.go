@@ -0,0 +1,190 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// fuzzyMatch reports whether pattern matches text, case-insensitively, and
+// returns the rune positions in text that matched. A contiguous substring
+// match is preferred so highlighting stays readable; otherwise the pattern
+// only has to appear as a subsequence.
+func fuzzyMatch(pattern, text string) ([]int, bool) {
+	if pattern == "" {
+		return nil, true
+	}
+	p := []rune(strings.ToLower(pattern))
+	t := []rune(strings.ToLower(text))
+
+	// Contiguous match
+	for i := 0; i+len(p) <= len(t); i++ {
+		match := true
+		for j := range p {
+			if t[i+j] != p[j] {
+				match = false
+				break
+			}
+		}
+		if match {
+			pos := make([]int, len(p))
+			for j := range p {
+				pos[j] = i + j
+			}
+			return pos, true
+		}
+	}
+
+	// Subsequence match
+	var pos []int
+	j := 0
+	for i := 0; i < len(t) && j < len(p); i++ {
+		if t[i] == p[j] {
+			pos = append(pos, i)
+			j++
+		}
+	}
+	if j < len(p) {
+		return nil, false
+	}
+	return pos, true
+}
+
+// matchItem checks one item against the filter. Key values are always
+// searched; with allAttrs every other attribute is searched too, and the
+// first matching non-key attribute is returned so the list can show it.
+func matchItem(item Item, t Table, pattern string, allAttrs bool) (bool, string) {
+	if pattern == "" {
+		return true, ""
+	}
+	if _, ok := fuzzyMatch(pattern, cellText(item[t.PK])); ok {
+		return true, ""
+	}
+	if t.SK != "" {
+		if _, ok := fuzzyMatch(pattern, cellText(item[t.SK])); ok {
+			return true, ""
+		}
+	}
+	if !allAttrs {
+		return false, ""
+	}
+
+	var attrs []string
+	for k := range item {
+		if k != t.PK && k != t.SK {
+			attrs = append(attrs, k)
+		}
+	}
+	sort.Strings(attrs)
+	for _, k := range attrs {
+		if _, ok := fuzzyMatch(pattern, cellText(item[k])); ok {
+			return true, k
+		}
+	}
+	return false, ""
+}
+
+// visibleItems returns the indices into m.items that pass the current filter,
+// in list order. Without a filter every item is visible. m.itemCursor always
+// stays an index into m.items, so everything keyed by it keeps working.
+func (m *model) visibleItems() []int {
+	if len(m.tables) == 0 {
+		return nil
+	}
+	t := m.tables[m.tableCursor]
+	pattern := m.itemFilter.Value()
+	out := make([]int, 0, len(m.items))
+	for i, item := range m.items {
+		if ok, _ := matchItem(item, t, pattern, m.filterAllAttrs); ok {
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
+// visiblePos returns where m.itemCursor sits within visible, or -1.
+func (m *model) visiblePos(visible []int) int {
+	for pos, idx := range visible {
+		if idx == m.itemCursor {
+			return pos
+		}
+	}
+	return -1
+}
+
+// moveCursor moves the item cursor by delta rows through the visible items.
+func (m *model) moveCursor(delta int) {
+	visible := m.visibleItems()
+	if len(visible) == 0 {
+		return
+	}
+	pos := m.visiblePos(visible)
+	if pos < 0 {
+		pos = 0
+	} else {
+		pos = max(0, min(pos+delta, len(visible)-1))
+	}
+	if visible[pos] != m.itemCursor {
+		m.inspectorCursor = 0
+		m.viewport.GotoTop()
+	}
+	m.itemCursor = visible[pos]
+	m.updateViewport()
+}
+
+// snapCursorToFilter keeps the cursor on a visible item after the filter or
+// the item list changes.
+func (m *model) snapCursorToFilter() {
+	visible := m.visibleItems()
+	if len(visible) > 0 && m.visiblePos(visible) < 0 {
+		m.itemCursor = visible[0]
+	}
+	m.updateViewport()
+}
+
+func (m *model) clearItemFilter() {
+	m.itemFilter.SetValue("")
+	m.itemFilter.Blur()
+	m.filtering = false
+}
+
+// highlightMatches renders text with the runes matching pattern emphasised.
+func highlightMatches(text, pattern string, base, hl lipgloss.Style) string {
+	pos, ok := fuzzyMatch(pattern, text)
+	if pattern == "" || !ok {
+		return base.Render(text)
+	}
+	marked := make(map[int]bool, len(pos))
+	for _, p := range pos {
+		marked[p] = true
+	}
+
+	var b strings.Builder
+	var run []rune
+	runMarked := false
+	flush := func() {
+		if len(run) == 0 {
+			return
+		}
+		if runMarked {
+			b.WriteString(hl.Render(string(run)))
+		} else {
+			b.WriteString(base.Render(string(run)))
+		}
+		run = run[:0]
+	}
+	for i, r := range []rune(text) {
+		if marked[i] != runMarked {
+			flush()
+			runMarked = marked[i]
+		}
+		if unicode.IsControl(r) {
+			r = ' '
+		}
+		run = append(run, r)
+	}
+	flush()
+	return b.String()
+}
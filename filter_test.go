@@ -0,0 +1,72 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFuzzyMatch(t *testing.T) {
+	cases := []struct {
+		pattern, text string
+		pos           []int
+		ok            bool
+	}{
+		{"", "anything", nil, true},
+		{"ord", "ORDER#1", []int{0, 1, 2}, true},
+		{"r#1", "order#1", []int{4, 5, 6}, true},     // Contiguous match preferred over the first r
+		{"odr", "order#1", []int{0, 2, 4}, true},     // Subsequence
+		{"café", "Le Café", []int{3, 4, 5, 6}, true}, // Rune positions, not bytes
+		{"xyz", "order#1", nil, false},
+		{"order#12", "order#1", nil, false},
+	}
+	for _, c := range cases {
+		pos, ok := fuzzyMatch(c.pattern, c.text)
+		if ok != c.ok || !reflect.DeepEqual(pos, c.pos) {
+			t.Errorf("fuzzyMatch(%q, %q) = %v, %v; want %v, %v", c.pattern, c.text, pos, ok, c.pos, c.ok)
+		}
+	}
+}
+
+func TestMatchItem(t *testing.T) {
+	tbl := Table{Name: "orders", PK: "id", SK: "sk"}
+	item := Item{"id": "cust-7", "sk": "ORDER#42", "status": "shipped", "note": "fragile"}
+
+	cases := []struct {
+		pattern  string
+		allAttrs bool
+		ok       bool
+		attr     string
+	}{
+		{"", false, true, ""},
+		{"cust", false, true, ""},
+		{"order#4", false, true, ""},
+		{"ship", false, false, ""},
+		{"ship", true, true, "status"},
+		{"i", true, true, "note"}, // Both match; attributes are searched in name order
+		{"zzz", true, false, ""},
+	}
+	for _, c := range cases {
+		ok, attr := matchItem(item, tbl, c.pattern, c.allAttrs)
+		if ok != c.ok || attr != c.attr {
+			t.Errorf("matchItem(%q, all=%v) = %v, %q; want %v, %q", c.pattern, c.allAttrs, ok, attr, c.ok, c.attr)
+		}
+	}
+}
+
+func TestVisibleItemsKeepsIndices(t *testing.T) {
+	s := newSession()
+	s.items = []Item{{"id": "apple"}, {"id": "banana"}, {"id": "apricot"}}
+	m := model{tables: []Table{{Name: "fruit", PK: "id"}}, session: s}
+
+	s.itemFilter.SetValue("ap")
+	if got := m.visibleItems(); !reflect.DeepEqual(got, []int{0, 2}) {
+		t.Fatalf("visibleItems = %v, want [0 2]", got)
+	}
+
+	// The cursor moves between visible rows but stays an index into items
+	s.itemCursor = 0
+	m.moveCursor(1)
+	if s.itemCursor != 2 {
+		t.Errorf("cursor = %d after moving down, want 2", s.itemCursor)
+	}
+}
@@ -0,0 +1,344 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Section headers of the edit document of a plan.
+const (
+	editRead    = "-- READ"
+	editWrite   = "-- WRITE"
+	editCompute = "-- COMPUTE"
+)
+
+// generatedEdit is the inline editor of the SQL confirmation.
+type generatedEdit struct {
+	area textarea.Model
+	err  error // Why the last attempt to apply was rejected
+}
+
+// reviewGenerated checks m.llmResult and opens the SQL confirmation on it.
+// Nothing changes when a check fails, so a rejected edit leaves the reviewed
+// statements in place.
+func (m *model) reviewGenerated() error {
+	t := m.tables[m.tableCursor]
+	if err := checkResult(&m.llmResult, t); err != nil {
+		return err
+	}
+	p := m.llmResult.Plan
+	if m.llmResult.Mode == "plan" {
+		if p.Operation == "aggregate" {
+			if err := m.checkAggregate(p); err != nil {
+				return err
+			}
+		}
+		if p.Write != nil && p.Write.Action == "compute" {
+			if _, err := compileComputed(p.Write.PerItem.Set, t); err != nil {
+				return err
+			}
+		}
+	}
+
+	m.isScanWarning = false
+	var body string
+	if m.llmResult.Mode == "sql" {
+		for _, sql := range m.llmResult.Statements {
+			if isLikelyScan(sql, t.PK) {
+				m.isScanWarning = true
+				break
+			}
+		}
+		body = strings.Join(m.llmResult.Statements, "\n\n")
+	} else {
+		isAggregate := p.Operation == "aggregate"
+		if (p.Safety.NeedsConfirmation && p.Safety.Reason == "full_table_scan") || isLikelyScan(p.Read.Partiql, t.PK) {
+			m.isScanWarning = true
+		}
+		// Plan details
+		body = fmt.Sprintf("OPERATION: %s\n\n", strings.ToUpper(p.Operation))
+		switch {
+		case isAggregate && p.Aggregate.Source == aggregateLoaded:
+			m.isScanWarning = false
+			body += fmt.Sprintf("STEP 1 (READ):\nthe %s shown in this tab\n\n", m.loadedScope(m.loadedCount(t.Name)))
+		case isAggregate:
+			body += fmt.Sprintf("STEP 1 (READ - Every Page):\n%s\n\n", p.Read.Partiql)
+		default:
+			body += fmt.Sprintf("STEP 1 (READ):\n%s\n\n", p.Read.Partiql)
+		}
+		if isAggregate {
+			body += fmt.Sprintf("STEP 2 (AGGREGATE - Client-side):\n%s\n", p.Aggregate.describe())
+		}
+		if p.Write != nil && p.Write.Action == "compute" {
+			body += fmt.Sprintf("STEP 2 (COMPUTE - Per Item, previewed before writing):\n%s\n", computedText(p.Write.PerItem.Set))
+		} else if p.Write != nil {
+			body += fmt.Sprintf("STEP 2 (WRITE - Per Item):\n%s\n", p.Write.PerItem.PartiqlTemplate)
+		}
+		if p.Safety.NeedsConfirmation {
+			body += fmt.Sprintf("\nNOTE: %s", p.Safety.Reason)
+		}
+	}
+	m.sqlViewport.Width = int(float64(m.width) * 0.7)
+	body = explainText(m.llmResult, t, m.loadedCount(t.Name), m.llmEdited, m.sqlViewport.Width-2) + body
+	if m.llmEdited {
+		body = "(edited by hand)\n\n" + body
+	}
+	m.sqlViewport.Height = max(m.height-14, 5)
+	m.sqlViewport.SetContent(body)
+	m.sqlViewport.GotoTop()
+
+	m.scanPhrase = ""
+	m.confirmInput.Blur()
+	if m.isScanWarning && m.config.ScanConfirmRCU >= 0 && estimateScan(t).ReadUnits > m.config.ScanConfirmRCU {
+		m.scanPhrase = "scan " + t.Name
+		m.confirmInput.Placeholder = m.scanPhrase
+		m.confirmInput.SetValue("")
+		m.confirmInput.Focus()
+	}
+	m.view = viewSqlConfirmation
+	return nil
+}
+
+// explainText is the plain-English part of the confirmation: what running
+// the result does and the model's reasoning for it.
+func explainText(r LLMResult, t Table, loaded int, edited bool, width int) string {
+	wrap := lipgloss.NewStyle().Width(max(width, 20))
+	var b strings.Builder
+	b.WriteString("WHAT THIS DOES:\n")
+	for _, line := range describeResult(r, t, loaded) {
+		b.WriteString(wrap.Render("- "+line) + "\n")
+	}
+	if reason := strings.TrimSpace(r.Reason); reason != "" {
+		label := "WHY (the model's reasoning): "
+		if edited {
+			label = "WHY (the model's reasoning, before your edit): "
+		}
+		b.WriteString("\n" + wrap.Render(label+reason) + "\n")
+	}
+	return b.String() + "\n"
+}
+
+// generatedDoc renders the editable parts of a result as text: the
+// statements of sql mode, or the read, write template and computed values of
+// a plan under their section headers.
+func generatedDoc(r LLMResult) string {
+	var b strings.Builder
+	b.WriteString("-- Edit, then save to re-check. Lines starting with -- are comments.\n")
+	if r.Mode != "plan" || r.Plan == nil {
+		b.WriteString("-- Statements run in order; separate them with a blank line.\n")
+		b.WriteString(strings.Join(r.Statements, "\n\n"))
+		b.WriteString("\n")
+		return b.String()
+	}
+	p := r.Plan
+	if p.Aggregate != nil {
+		fmt.Fprintf(&b, "-- AGGREGATE (not editable): %s\n", p.Aggregate.describe())
+	}
+	fmt.Fprintf(&b, "%s: the query that finds the items\n%s\n", editRead, p.Read.Partiql)
+	switch {
+	case p.Write != nil && p.Write.Action == "compute":
+		fmt.Fprintf(&b, "%s: one \"path = expression\" per line, evaluated for every item found\n", editCompute)
+		for _, s := range p.Write.PerItem.Set {
+			fmt.Fprintf(&b, "%s = %s\n", s.Path, s.Expr)
+		}
+	case p.Write != nil:
+		fmt.Fprintf(&b, "%s: %s for every item found; {{PK}} and {{SK}} stand for its keys\n", editWrite, p.Write.Action)
+		fmt.Fprintf(&b, "%s\n", p.Write.PerItem.PartiqlTemplate)
+	}
+	return b.String()
+}
+
+// applyGeneratedDoc reads an edited document back into a copy of r. It
+// reports false when nothing was changed.
+func applyGeneratedDoc(r LLMResult, doc string) (LLMResult, bool, error) {
+	if strings.TrimSpace(doc) == strings.TrimSpace(generatedDoc(r)) {
+		return r, false, nil
+	}
+	if r.Mode != "plan" || r.Plan == nil {
+		var stmts, para []string
+		for _, line := range strings.Split(stripComments(doc)+"\n", "\n") {
+			if strings.TrimSpace(line) != "" {
+				para = append(para, strings.TrimRight(line, " \t"))
+			} else if len(para) > 0 {
+				stmts = append(stmts, strings.Join(para, "\n"))
+				para = nil
+			}
+		}
+		if len(stmts) == 0 {
+			return r, false, fmt.Errorf("no statements left")
+		}
+		r.Statements = stmts
+		return r, true, nil
+	}
+
+	sections := map[string][]string{}
+	section := editRead
+	for _, line := range strings.Split(doc, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "--") {
+			for _, h := range []string{editRead, editWrite, editCompute} {
+				if strings.HasPrefix(trimmed, h) {
+					section = h
+				}
+			}
+			continue
+		}
+		if trimmed != "" {
+			sections[section] = append(sections[section], trimmed)
+		}
+	}
+
+	p := *r.Plan
+	p.Read.Partiql = strings.Join(sections[editRead], "\n")
+	switch {
+	case p.Write != nil && p.Write.Action == "compute":
+		w := *p.Write
+		w.PerItem.Set = nil
+		for _, line := range sections[editCompute] {
+			path, expr, ok := strings.Cut(line, "=")
+			if !ok {
+				return r, false, fmt.Errorf("%q: write computed values as path = expression", line)
+			}
+			w.PerItem.Set = append(w.PerItem.Set, ComputedSet{Path: strings.TrimSpace(path), Expr: strings.TrimSpace(expr)})
+		}
+		p.Write = &w
+	case p.Write != nil:
+		w := *p.Write
+		w.PerItem.PartiqlTemplate = strings.Join(sections[editWrite], "\n")
+		p.Write = &w
+	}
+	r.Plan = &p
+	return r, true, nil
+}
+
+// stripComments drops the -- comment lines of an edit document.
+func stripComments(doc string) string {
+	var lines []string
+	for _, line := range strings.Split(doc, "\n") {
+		if !strings.HasPrefix(strings.TrimSpace(line), "--") {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// editDoc is what an editor opens with: the last rejected edit, so it can be
+// fixed, or else the statements under review.
+func (m *model) editDoc() string {
+	if m.rejectedEdit != "" {
+		return m.rejectedEdit
+	}
+	return generatedDoc(m.llmResult)
+}
+
+// editable reports whether the result under review has anything to edit.
+func (m *model) editable() bool {
+	p := m.llmResult.Plan
+	if m.llmResult.Mode == "plan" && p != nil && p.Aggregate != nil && p.Aggregate.Source == aggregateLoaded {
+		m.notice = "Nothing to edit: this aggregate runs on the loaded items"
+		return false
+	}
+	return true
+}
+
+// startGeneratedEdit opens the inline editor on the statements under review.
+func (m *model) startGeneratedEdit() tea.Cmd {
+	if !m.editable() {
+		return nil
+	}
+	ta := textarea.New()
+	ta.ShowLineNumbers = false
+	ta.CharLimit = 0
+	ta.MaxHeight = 0
+	ta.SetWidth(m.sqlViewport.Width)
+	ta.SetHeight(m.sqlViewport.Height)
+	ta.SetValue(m.editDoc())
+	m.genEdit = &generatedEdit{area: ta}
+	return m.genEdit.area.Focus()
+}
+
+// updateGeneratedEdit handles keys while the inline editor is open: ctrl+s
+// applies the edit, esc discards it.
+func (m *model) updateGeneratedEdit(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		m.genEdit = nil
+		return nil
+	case "ctrl+s":
+		doc := m.genEdit.area.Value()
+		if err := m.applyGeneratedEdit(doc); err != nil {
+			m.genEdit.err = err
+			return nil
+		}
+		m.genEdit = nil
+		return nil
+	}
+	var cmd tea.Cmd
+	m.genEdit.area, cmd = m.genEdit.area.Update(msg)
+	return cmd
+}
+
+// editGeneratedCmd opens the statements under review in $EDITOR.
+func (m *model) editGeneratedCmd() tea.Cmd {
+	if !m.editable() {
+		return nil
+	}
+	f, err := os.CreateTemp("", "dynotui-*.sql")
+	if err != nil {
+		return func() tea.Msg { return generatedEditedMsg{err: err} }
+	}
+	defer f.Close()
+	if _, err := f.WriteString(m.editDoc()); err != nil {
+		os.Remove(f.Name())
+		return func() tea.Msg { return generatedEditedMsg{err: err} }
+	}
+	return tea.ExecProcess(editorCommand(f.Name()), func(err error) tea.Msg {
+		defer os.Remove(f.Name())
+		if err != nil {
+			return generatedEditedMsg{err: err}
+		}
+		content, err := os.ReadFile(f.Name())
+		if err != nil {
+			return generatedEditedMsg{err: err}
+		}
+		return generatedEditedMsg{doc: string(content)}
+	})
+}
+
+// applyGeneratedEdit re-checks an edited document the way model output is
+// checked and, when it passes, puts it under review in place of the
+// original. A rejected edit is kept for the next editor to open with.
+func (m *model) applyGeneratedEdit(doc string) error {
+	if strings.TrimSpace(stripComments(doc)) == "" {
+		m.rejectedEdit = ""
+		m.notice = "Edit discarded; the statements are unchanged"
+		return nil
+	}
+	prev, prevEdited := m.llmResult, m.llmEdited
+	r, changed, err := applyGeneratedDoc(prev, doc)
+	if err == nil && !changed {
+		m.rejectedEdit = ""
+		m.notice = "No changes"
+		return nil
+	}
+	if err == nil {
+		m.llmResult = r
+		m.llmEdited = true
+		if err = m.reviewGenerated(); err != nil {
+			m.llmResult, m.llmEdited = prev, prevEdited
+		}
+	}
+	if err != nil {
+		m.rejectedEdit = doc
+		return fmt.Errorf("Edit rejected: %w", err)
+	}
+	m.rejectedEdit = ""
+	m.editTurn(m.llmResult)
+	m.notice = "Edited statements passed the checks; review them before running"
+	return nil
+}
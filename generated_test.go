@@ -0,0 +1,71 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestApplyGeneratedDoc(t *testing.T) {
+	sql := LLMResult{Mode: "sql", Statements: []string{`SELECT * FROM "t" WHERE "id" = 'a'`}}
+	if _, changed, err := applyGeneratedDoc(sql, generatedDoc(sql)); changed || err != nil {
+		t.Errorf("unchanged doc: changed %v, err %v", changed, err)
+	}
+	doc := strings.Replace(generatedDoc(sql), "'a'", "'b'", 1) + "\n  \nDELETE FROM \"t\"\nWHERE \"id\" = 'c'\n"
+	got, changed, err := applyGeneratedDoc(sql, doc)
+	want := []string{`SELECT * FROM "t" WHERE "id" = 'b'`, "DELETE FROM \"t\"\nWHERE \"id\" = 'c'"}
+	if err != nil || !changed || !reflect.DeepEqual(got.Statements, want) {
+		t.Errorf("statements = %q (changed %v, err %v), want %q", got.Statements, changed, err, want)
+	}
+	if sql.Statements[0] != `SELECT * FROM "t" WHERE "id" = 'a'` {
+		t.Error("the original result was modified")
+	}
+
+	plan := LLMResult{Mode: "plan", Plan: &PlanBlock{
+		Operation: "scan_then_write",
+		Read:      ReadBlock{Partiql: `SELECT * FROM "t"`},
+		Write:     &WriteBlock{Action: "compute"},
+	}}
+	plan.Plan.Write.PerItem.Set = []ComputedSet{{Path: "price", Expr: "price * 1.1"}}
+	doc = strings.NewReplacer(`FROM "t"`, `FROM "t" WHERE "cat" = 'book'`, "price * 1.1", "round(price * 1.1, 2)\nseen = true").Replace(generatedDoc(plan))
+	got, _, err = applyGeneratedDoc(plan, doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Plan.Read.Partiql != `SELECT * FROM "t" WHERE "cat" = 'book'` {
+		t.Errorf("read = %q", got.Plan.Read.Partiql)
+	}
+	wantSets := []ComputedSet{{Path: "price", Expr: "round(price * 1.1, 2)"}, {Path: "seen", Expr: "true"}}
+	if !reflect.DeepEqual(got.Plan.Write.PerItem.Set, wantSets) {
+		t.Errorf("sets = %+v", got.Plan.Write.PerItem.Set)
+	}
+	if plan.Plan.Read.Partiql != `SELECT * FROM "t"` || len(plan.Plan.Write.PerItem.Set) != 1 {
+		t.Error("the original plan was modified")
+	}
+	if _, _, err := applyGeneratedDoc(plan, doc+"no assignment\n"); err == nil {
+		t.Error("a compute line without = was accepted")
+	}
+}
+
+func TestCheckResult(t *testing.T) {
+	table := Table{Name: "t", PK: "id", SK: "ts"}
+	update := func(read, tpl string) *LLMResult {
+		r := &LLMResult{Mode: "plan", Plan: &PlanBlock{Operation: "scan_then_write", Read: ReadBlock{Partiql: read}, Write: &WriteBlock{Action: "update"}}}
+		r.Plan.Write.PerItem.PartiqlTemplate = tpl
+		return r
+	}
+	if err := checkResult(update(`SELECT * FROM "t"`, `UPDATE "t" SET "a" = 1 WHERE "id" = {{PK}} AND "ts" = {{SK}}`), table); err != nil {
+		t.Errorf("valid plan: %v", err)
+	}
+	bad := []*LLMResult{
+		{Mode: "sql", Statements: []string{" "}},
+		update(`DELETE FROM "t"`, `UPDATE "t" SET "a" = 1 WHERE "id" = {{PK}} AND "ts" = {{SK}}`),
+		update(`SELECT * FROM "t"`, `UPDATE "t" SET "a" = 1 WHERE "id" = {{PK}}`),
+		update(`SELECT * FROM "t"`, `UPDATE "t" SET "a" = 1`),
+	}
+	for i, r := range bad {
+		if err := checkResult(r, table); err == nil {
+			t.Errorf("case %d: no error", i)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Grid cells are narrower than list columns since many more are on screen.
+const maxGridColumnWidth = 24
+
+// summarizeValue renders a grid cell. Nested values are collapsed to a short
+// summary; the JSON inspector shows them in full.
+func summarizeValue(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case map[string]interface{}:
+		return fmt.Sprintf("{%d attrs}", len(t))
+	case []interface{}:
+		return fmt.Sprintf("[%d items]", len(t))
+	case []string:
+		return fmt.Sprintf("<SS %d>", len(t))
+	case []float64:
+		return fmt.Sprintf("<NS %d>", len(t))
+	case [][]byte:
+		return fmt.Sprintf("<BS %d>", len(t))
+	case []byte:
+		return fmt.Sprintf("<B %dB>", len(t))
+	}
+	return cellText(v)
+}
+
+// gridColumns returns the union of attributes across the loaded items with
+// widths inferred from their summaries. The key columns come first and are
+// frozen; frozen is how many of them there are.
+func (m *model) gridColumns(visible []int) (cols []itemColumn, frozen int) {
+	t := m.tables[m.tableCursor]
+	frozen = 1
+	if t.SK != "" {
+		frozen = 2
+	}
+	for _, attr := range m.loadedAttributes() {
+		w := lipgloss.Width(attr)
+		for _, i := range visible {
+			if v, ok := m.items[i][attr]; ok {
+				w = max(w, lipgloss.Width(summarizeValue(v)))
+			}
+			if w >= maxGridColumnWidth {
+				break
+			}
+		}
+		cols = append(cols, itemColumn{attr: attr, title: attr, width: max(minColumnWidth, min(w, maxGridColumnWidth))})
+	}
+	return cols, frozen
+}
+
+// gridWidth is the room left for cells once the border and cursor gutter of
+// the full-width grid are taken out.
+func (m *model) gridWidth() int {
+	return m.width - 6
+}
+
+// gridLayout returns the indices into cols shown at the given scroll offset:
+// the frozen key columns followed by as many scrolling columns as fit.
+func gridLayout(cols []itemColumn, frozen, offset, width int) (shown []int, hiddenRight bool) {
+	used := 0
+	for i := 0; i < frozen && i < len(cols); i++ {
+		shown = append(shown, i)
+		used += cols[i].width + 1
+	}
+	for i := frozen + offset; i < len(cols); i++ {
+		if used+cols[i].width+1 > width {
+			return shown, true
+		}
+		shown = append(shown, i)
+		used += cols[i].width + 1
+	}
+	return shown, false
+}
+
+// moveGridCol moves the cell cursor across the grid columns and scrolls the
+// unfrozen columns so the cursor stays on screen.
+func (m *model) moveGridCol(delta int) {
+	cols, frozen := m.gridColumns(m.visibleItems())
+	if len(cols) == 0 {
+		return
+	}
+	m.gridCol = max(0, min(m.gridCol+delta, len(cols)-1))
+	if m.gridCol < frozen {
+		return
+	}
+	if m.gridCol < frozen+m.gridOffset {
+		m.gridOffset = m.gridCol - frozen
+	}
+	for m.gridOffset < m.gridCol-frozen {
+		shown, _ := gridLayout(cols, frozen, m.gridOffset, m.gridWidth())
+		if shown[len(shown)-1] >= m.gridCol {
+			break
+		}
+		m.gridOffset++
+	}
+}
+
+// gridAttr is the attribute under the cell cursor.
+func (m *model) gridAttr() string {
+	cols, _ := m.gridColumns(m.visibleItems())
+	if m.gridCol < len(cols) {
+		return cols[m.gridCol].attr
+	}
+	return ""
+}
+
+// jumpToAttribute leaves the grid and puts the inspector cursor on attr.
+func (m *model) jumpToAttribute(attr string) {
+	m.gridMode = false
+	m.activePane = 1
+	m.inspectorCursor = 0
+	for i, r := range m.inspectorRows() {
+		if r.path == attr {
+			m.inspectorCursor = i
+			break
+		}
+	}
+	m.moveInspector(0)
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSummarizeValue(t *testing.T) {
+	cases := []struct {
+		v    interface{}
+		want string
+	}{
+		{nil, ""},
+		{"plain", "plain"},
+		{"two\nlines", "two lines"},
+		{42.0, "42"},
+		{map[string]interface{}{"a": 1.0, "b": 2.0}, "{2 attrs}"},
+		{[]interface{}{1.0, "x", true}, "[3 items]"},
+		{[]string{"a", "b"}, "<SS 2>"},
+		{[]float64{1}, "<NS 1>"},
+		{[][]byte{{1}, {2}}, "<BS 2>"},
+		{[]byte("abc"), "<B 3B>"},
+	}
+	for _, c := range cases {
+		if got := summarizeValue(c.v); got != c.want {
+			t.Errorf("summarizeValue(%#v) = %q, want %q", c.v, got, c.want)
+		}
+	}
+}
+
+func TestGridColumns(t *testing.T) {
+	s := newSession()
+	s.items = []Item{
+		{"pk": "user#1", "sk": "profile", "tags": []string{"a", "b"}, "bio": "a biography far longer than a grid cell"},
+		{"pk": "user#2", "sk": "order#1", "n": 7.0},
+	}
+	m := model{tables: []Table{{Name: "t", PK: "pk", SK: "sk"}}, session: s}
+
+	cols, frozen := m.gridColumns([]int{0, 1})
+	if frozen != 2 {
+		t.Errorf("frozen = %d, want 2 key columns", frozen)
+	}
+	var got []string
+	var widths []int
+	for _, c := range cols {
+		got = append(got, c.attr)
+		widths = append(widths, c.width)
+	}
+	if want := []string{"pk", "sk", "bio", "n", "tags"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("columns = %v, want %v", got, want)
+	}
+	// bio is clamped, n is padded to the minimum, tags fits its summary
+	if want := []int{6, 7, maxGridColumnWidth, minColumnWidth, 6}; !reflect.DeepEqual(widths, want) {
+		t.Errorf("widths = %v, want %v", widths, want)
+	}
+}
+
+func TestGridLayout(t *testing.T) {
+	cols := []itemColumn{{width: 5}, {width: 5}, {width: 10}, {width: 10}, {width: 10}}
+
+	cases := []struct {
+		offset, width int
+		shown         []int
+		hiddenRight   bool
+	}{
+		{0, 100, []int{0, 1, 2, 3, 4}, false},
+		{0, 30, []int{0, 1, 2}, true},
+		{1, 30, []int{0, 1, 3}, true},
+		{2, 30, []int{0, 1, 4}, false},
+		{0, 10, []int{0, 1}, true}, // Frozen columns are always shown
+	}
+	for _, c := range cases {
+		shown, right := gridLayout(cols, 2, c.offset, c.width)
+		if !reflect.DeepEqual(shown, c.shown) || right != c.hiddenRight {
+			t.Errorf("gridLayout(offset %d, width %d) = %v, %v; want %v, %v", c.offset, c.width, shown, right, c.shown, c.hiddenRight)
+		}
+	}
+}
+
+func TestMoveGridColScrolls(t *testing.T) {
+	s := newSession()
+	s.items = []Item{{"id": "a", "b": "bbbbbbbbbb", "c": "cccccccccc", "d": "dddddddddd"}}
+	m := model{tables: []Table{{Name: "t", PK: "id"}}, session: s, width: 6 + 30}
+
+	// id (4) + b (10) fit in 30 with c (10); d needs a scroll
+	m.moveGridCol(3)
+	if m.gridCol != 3 || m.gridOffset != 1 {
+		t.Errorf("after moving to d: col %d offset %d, want 3 and 1", m.gridCol, m.gridOffset)
+	}
+	m.moveGridCol(-2)
+	if m.gridCol != 1 || m.gridOffset != 0 {
+		t.Errorf("after moving back to b: col %d offset %d, want 1 and 0", m.gridCol, m.gridOffset)
+	}
+	m.moveGridCol(-5)
+	if m.gridCol != 0 || m.gridOffset != 0 {
+		t.Errorf("after moving past the start: col %d offset %d, want 0 and 0", m.gridCol, m.gridOffset)
+	}
+}
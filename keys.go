@@ -23,6 +23,24 @@ type keyMap struct {
 	LoadMore key.Binding
 	Refresh key.Binding
 	Theme   key.Binding
+	AuditLog key.Binding
+	Filter   key.Binding
+	Columns  key.Binding
+	Sort     key.Binding
+	Grid     key.Binding
+	EditCell key.Binding
+	Copy     key.Binding
+	Select   key.Binding
+	Export   key.Binding
+	BulkSet  key.Binding
+	Tabs     key.Binding
+	Schema   key.Binding
+	Stream   key.Binding
+	AutoRefresh  key.Binding
+	Profile      key.Binding
+	Conversation key.Binding
+	Explain      key.Binding
+	SavedQueries key.Binding
 }
 
 func (k keyMap) ShortHelp() []key.Binding {
@@ -31,8 +49,8 @@ func (k keyMap) ShortHelp() []key.Binding {
 
 func (k keyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
-		{k.Up, k.Down, k.Enter, k.LoadMore, k.Refresh, k.Theme},
-		{k.Back, k.Slash, k.Help, k.Quit, k.Edit, k.Save, k.Add, k.Delete},
+		{k.Up, k.Down, k.Enter, k.LoadMore, k.Refresh, k.Theme, k.AuditLog},
+		{k.Back, k.Slash, k.Filter, k.Columns, k.Sort, k.Grid, k.EditCell, k.Copy, k.Select, k.Export, k.BulkSet, k.Tabs, k.Schema, k.Stream, k.AutoRefresh, k.Profile, k.Conversation, k.Explain, k.SavedQueries, k.Help, k.Quit, k.Edit, k.Save, k.Add, k.Delete},
 	}
 }
 
@@ -101,4 +119,76 @@ var keys = keyMap{
 		key.WithKeys("t"),
 		key.WithHelp("t", "toggle theme"),
 	),
+	AuditLog: key.NewBinding(
+		key.WithKeys("L"),
+		key.WithHelp("L", "audit log"),
+	),
+	Filter: key.NewBinding(
+		key.WithKeys("f"),
+		key.WithHelp("f", "filter tables / items"),
+	),
+	Columns: key.NewBinding(
+		key.WithKeys("c"),
+		key.WithHelp("c", "choose columns"),
+	),
+	Sort: key.NewBinding(
+		key.WithKeys("o"),
+		key.WithHelp("o/O", "sort / reverse"),
+	),
+	Grid: key.NewBinding(
+		key.WithKeys("g"),
+		key.WithHelp("g", "grid view"),
+	),
+	EditCell: key.NewBinding(
+		key.WithKeys("i"),
+		key.WithHelp("i", "edit cell"),
+	),
+	Copy: key.NewBinding(
+		key.WithKeys("y", "Y"),
+		key.WithHelp("y", "copy item / node"),
+	),
+	Select: key.NewBinding(
+		key.WithKeys(" ", "V", "*"),
+		key.WithHelp("space/V/*", "select / range / all"),
+	),
+	Export: key.NewBinding(
+		key.WithKeys("x"),
+		key.WithHelp("x", "export"),
+	),
+	BulkSet: key.NewBinding(
+		key.WithKeys("b"),
+		key.WithHelp("b", "set on selected"),
+	),
+	Tabs: key.NewBinding(
+		key.WithKeys("tab", "ctrl+t", "ctrl+w"),
+		key.WithHelp("tab/^t/^w", "next / new / close tab"),
+	),
+	Schema: key.NewBinding(
+		key.WithKeys("n", "d", "i", "x", "s", "b"),
+		key.WithHelp("n/d/i/x/s/b", "create / delete table, add / remove index, settings, backups"),
+	),
+	Stream: key.NewBinding(
+		key.WithKeys("w"),
+		key.WithHelp("w", "tail the table's stream"),
+	),
+	AutoRefresh: key.NewBinding(
+		key.WithKeys("R"),
+		key.WithHelp("R", "auto-refresh off / 5s / 15s / 30s / 1m"),
+	),
+	Profile: key.NewBinding(
+		key.WithKeys("m"),
+		key.WithHelp("m", "profile table attributes"),
+	),
+	Conversation: key.NewBinding(
+		key.WithKeys("C"),
+		key.WithHelp("C", "clear AI conversation"),
+	),
+	Explain: key.NewBinding(
+		key.WithKeys("I"),
+		key.WithHelp("I", "explain results (AI)"),
+	),
+	SavedQueries: key.NewBinding(
+		key.WithKeys("Q"),
+		key.WithHelp("Q", "saved queries"),
+	),
 }
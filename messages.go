@@ -8,25 +8,144 @@ type tablesLoadedMsg struct {
 	tables []TableDetails
 	region string
 	accountId string
+	capacity Capacity
 }
 type itemsLoadedMsg struct {
 	items    []map[string]interface{}
 	nextKey  map[string]types.AttributeValue
 	isAppend bool
+	capacity Capacity
+	notice   string // Shown once the items are in, e.g. writes that were left out
 }
 type sqlGeneratedMsg struct {
 	result LLMResult
 	err    error
 }
+type explainedMsg struct {
+	result *explanation
+	err    error
+}
+type generatedEditedMsg struct {
+	doc string // The edited document; empty when the editor saved nothing
+	err error
+}
 type editorFinishedMsg struct {
 	newItem Item
 	err     error
 	isNew   bool
 }
-type itemSavedMsg struct{ err error }
-type itemDeletedMsg struct{ err error }
+type itemSavedMsg struct {
+	err      error
+	capacity Capacity
+}
+type itemDeletedMsg struct {
+	err      error
+	capacity Capacity
+}
 type errMsg error
 
 type bulkDiscoveryLoadedMsg struct {
-	items []map[string]interface{}
-}
\ No newline at end of file
+	items    []map[string]interface{}
+	capacity Capacity
+}
+
+type auditLoadedMsg struct {
+	entries []AuditEntry
+	err     error
+}
+type batchDoneMsg struct {
+	action   string
+	done     []int // Indices into m.items the batch got through
+	set      map[string]interface{}
+	capacity Capacity
+	err      error
+}
+
+type exportedMsg struct {
+	path  string
+	count int
+	err   error
+}
+
+// schemaChangedMsg reports that DynamoDB accepted a table or index change.
+type schemaChangedMsg struct {
+	action string // One of the DDL audit actions
+	table  string
+	index  string
+	spec     TableSpec // Create table only
+	source   string    // Restore only: the table restored from
+	settings []string  // Table settings that were changed
+	err      error
+}
+
+type profileLoadedMsg struct {
+	profile  *TableProfile
+	saveErr  error    // The profile was built but couldn't be cached
+	err      error
+	capacity Capacity // What a failed sample consumed; a profile carries its own
+}
+
+type backupsLoadedMsg struct {
+	backups []Backup
+	window  PITRWindow
+	err     error
+}
+
+type settingsLoadedMsg struct {
+	table    string
+	settings TableSettings
+	err      error
+}
+
+// tableStatusMsg is one poll of a table that is being created, updated or
+// deleted. gone is set once the table no longer exists.
+type tableStatusMsg struct {
+	name    string
+	details TableDetails
+	gone    bool
+	err     error
+}
+
+// streamRecordsMsg carries the records read by one poll of a stream tail and
+// the position to read from next.
+type streamRecordsMsg struct {
+	tail    *streamTail
+	pos     StreamPosition
+	records []StreamRecord
+	err     error
+}
+
+// refreshTickMsg is due when a tab's auto-refresh interval has passed. gen
+// tells a stale loop from the current one.
+type refreshTickMsg struct {
+	session *session
+	gen     int
+}
+
+// refreshedMsg carries what an auto-refresh fetched for a tab.
+type refreshedMsg struct {
+	session  *session
+	gen      int
+	items    []map[string]interface{}
+	nextKey  map[string]types.AttributeValue
+	capacity Capacity
+	err      error
+}
+
+// changesExpiredMsg clears the change marks of one refresh.
+type changesExpiredMsg struct {
+	session *session
+	gen     int
+}
+
+// aggregatedMsg carries the summary table of an aggregate plan's read.
+type aggregatedMsg struct {
+	result   *AggregateResult
+	capacity Capacity
+	err      error
+}
+
+type clipboardMsg struct {
+	what string
+	err  error
+}
@@ -23,6 +23,19 @@ const (
 	viewDeleteConfirmation
 	viewSqlConfirmation
 	viewBulkConfirmation
+	viewAuditLog
+	viewColumnPicker
+	viewSortPicker
+	viewBatchSet
+	viewBatchConfirmation
+	viewSchemaForm
+	viewDropConfirm
+	viewBackups
+	viewStream
+	viewProfile
+	viewAggregate
+	viewExplain
+	viewSavedQueries
 )
 
 // --- Model ---
@@ -36,23 +49,29 @@ type Table struct {
 	Region    string
 	ItemCount int64
 	GSIs      []string
+	GSIStatus map[string]string
 	Status    string
+	Restoring bool
+	BillingMode string // PROVISIONED or PAY_PER_REQUEST
+	SizeBytes   int64
+	ReadCapacity int64
 }
 
 type Item map[string]interface{}
 
 type model struct {
+	*session            // Active tab; see session.go for the per-table state
+	sessions      []*session
+	activeSession int
 	aws         *AWS // Shared AWS client
 	view        currentView
 	width       int
 	height      int
 	loading     bool
 	tables      []Table
-	items       []Item
 	tableCursor int
-	itemCursor  int
-	modifiedItems map[int]bool
-	newItems      map[int]bool // Tracks items that were newly created but not yet saved/synced
+	tableFilter    textinput.Model // Type-to-filter over the table names
+	tableFiltering bool
 	spinner     spinner.Model
 	input       textinput.Model
 	inputMode   bool
@@ -60,18 +79,49 @@ type model struct {
 	keys        keyMap
 	viewport    viewport.Model
 	sqlViewport viewport.Model
-	activePane  int
 	statusMessage string
+	notice        string // One-off feedback shown in the status bar until the next key press
+	copyPending   bool   // y was pressed on an item; the next key picks the copy format
+	batchAction   string
+	batchSet      map[string]interface{} // Attributes a bulk set applies
+	batchInput    textinput.Model
+	batchErr      error
+	schemaForm    *schemaForm     // Create table / add index form
+	drop          *dropRequest    // Table or index waiting for its name to be typed
+	polling       map[string]bool // Tables whose status is being polled
+	backups       []Backup        // Backups of the selected table, newest first
+	backupCursor  int
+	backupWindow  PITRWindow
+	profiles      map[string]*TableProfile // Table profiles by table name, loaded from the disk cache on first use
+	profileCursor int
+	aggregate       *AggregateResult // Summary table of the last aggregate plan
+	aggregateCursor int
+	explanation     *explanation // Bedrock's summary of the loaded items
+	savedQueries    []SavedQuery // All saved queries while the picker is open
+	savedCursor     int
 	err         error
-	llmResult   LLMResult
+	genEdit      *generatedEdit // Inline editor of the SQL confirmation; nil when closed
+	rejectedEdit string         // Last edit that failed the checks, reopened by the next edit
 	pendingPlanItems []Item
+	computed         *computedWrite // Per-item UPDATEs of a compute plan, waiting for the bulk confirmation
 	bulkActionPending bool
 	isScanWarning bool
-	isCustomQuery bool
-	lastEvaluatedKey map[string]types.AttributeValue
+	scanPhrase    string // Must be typed to run a scan above Config.ScanConfirmRCU; empty when not required
+	confirmInput  textinput.Model
 	previousView currentView
+	lastCapacity    Capacity // Consumed by the most recent operation
+	sessionCapacity Capacity // Running total since startup
+	auditEntries   []AuditEntry
+	auditCursor    int
+	auditFilter    textinput.Model
+	auditFiltering bool
+	columnChoices  []columnChoice
+	columnCursor   int
+	sortChoices    []string
+	sortCursor     int
 	Region string
 	AccountId string
+	config Config
 }
 
 func initialModel(api *AWS) model {
@@ -85,22 +135,46 @@ func initialModel(api *AWS) model {
 	ti.CharLimit = 156
 	ti.Width = 50
 
+	af := textinput.New()
+	af.Placeholder = "Filter by table, action, statement, outcome..."
+	af.Prompt = "filter: "
+	af.CharLimit = 156
+
+	tf := textinput.New()
+	tf.Placeholder = "Filter tables..."
+	tf.Prompt = "filter: "
+	tf.CharLimit = 156
+
+	ci := textinput.New()
+	ci.Prompt = "❯ "
+	ci.CharLimit = 300
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		cfg = defaultConfig()
+	}
+
 	h := help.New()
 	h.Styles.ShortKey.Foreground(lipgloss.Color("#7D56F4")) // Primary
 	h.Styles.ShortDesc.Foreground(lipgloss.Color("#626262")) // TextDim
 	h.Styles.FullKey.Foreground(lipgloss.Color("#7D56F4"))
 	h.Styles.FullDesc.Foreground(lipgloss.Color("#626262"))
 
+	first := newSession()
 	return model{
+		session:       first,
+		sessions:      []*session{first},
 		aws:           api,
 		view:          viewLoading,
 		loading:       true,
 		tables:        []Table{},
-		items:         []Item{},
-		modifiedItems: make(map[int]bool),
-		newItems:      make(map[int]bool),
+		tableFilter:   tf,
 		spinner:       s,
 		input:         ti,
+		auditFilter:   af,
+		confirmInput:  ci,
+		config:        cfg,
+		profiles:      make(map[string]*TableProfile),
 		help:          h,
 		keys:          keys,
 		viewport:      viewport.New(0, 0),
@@ -0,0 +1,325 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	defaultProfileSampleSize = 1000
+	maxProfileExamples       = 3
+	maxDistinctTracked       = 1000 // Cardinality counting stops here
+	promptProfileAttributes  = 40   // Most common attributes passed to Bedrock
+)
+
+// AttributeProfile describes one attribute path seen in a sample. Nested map
+// attributes are dotted ("address.city") and list elements end in "[]".
+type AttributeProfile struct {
+	Path     string         `json:"path"`
+	Types    map[string]int `json:"types"`    // DynamoDB type -> sampled items holding it
+	Count    int            `json:"count"`    // Sampled items where the path is present
+	Distinct int            `json:"distinct"` // Distinct values, capped at maxDistinctTracked
+	Examples []string       `json:"examples"`
+}
+
+// TableProfile is the result of sampling a table, cached per account, region
+// and table under ~/.config/dynotui/profiles.
+type TableProfile struct {
+	Table      string             `json:"table"`
+	Account    string             `json:"account"`
+	Region     string             `json:"region"`
+	SampledAt  time.Time          `json:"sampled_at"`
+	Items      int                `json:"items"`
+	Segments   int                `json:"segments"`
+	Capacity   Capacity           `json:"capacity"` // What the sample consumed
+	Attributes []AttributeProfile `json:"attributes"`
+}
+
+// attributeStats accumulates one path while the sample is walked.
+type attributeStats struct {
+	AttributeProfile
+	values map[string]bool
+}
+
+// buildProfile walks every attribute path of the sampled items. Presence and
+// types count each item once per path, even when a list holds the path many
+// times.
+func buildProfile(items []map[string]interface{}) []AttributeProfile {
+	stats := make(map[string]*attributeStats)
+	for _, item := range items {
+		seen := make(map[string]map[string]bool)
+		var walk func(path string, v interface{})
+		walk = func(path string, v interface{}) {
+			s, ok := stats[path]
+			if !ok {
+				s = &attributeStats{AttributeProfile: AttributeProfile{Path: path, Types: make(map[string]int)}, values: make(map[string]bool)}
+				stats[path] = s
+			}
+			if seen[path] == nil {
+				seen[path] = make(map[string]bool)
+				s.Count++
+			}
+			typ := dynamoType(v)
+			if !seen[path][typ] {
+				seen[path][typ] = true
+				s.Types[typ]++
+			}
+
+			// Maps and lists are described by their children instead
+			value := profileValue(v)
+			if typ != "M" && typ != "L" && !s.values[value] && len(s.values) < maxDistinctTracked {
+				s.values[value] = true
+				if len(s.Examples) < maxProfileExamples {
+					s.Examples = append(s.Examples, truncateText(value, 40))
+				}
+			}
+
+			switch t := v.(type) {
+			case map[string]interface{}:
+				for k, child := range t {
+					walk(path+"."+k, child)
+				}
+			case []interface{}:
+				for _, child := range t {
+					walk(path+"[]", child)
+				}
+			}
+		}
+		for k, v := range item {
+			walk(k, v)
+		}
+	}
+
+	out := make([]AttributeProfile, 0, len(stats))
+	for _, s := range stats {
+		s.Distinct = len(s.values)
+		out = append(out, s.AttributeProfile)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Path < out[j].Path })
+	return out
+}
+
+// profileValue renders a value for counting and examples, with strings
+// quoted the way PartiQL writes them.
+func profileValue(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return "'" + s + "'"
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return cellText(v)
+	}
+	return string(b)
+}
+
+// typeList names the types of a path, most common first.
+func (a AttributeProfile) typeList() string {
+	types := make([]string, 0, len(a.Types))
+	for t := range a.Types {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		if a.Types[types[i]] != a.Types[types[j]] {
+			return a.Types[types[i]] > a.Types[types[j]]
+		}
+		return types[i] < types[j]
+	})
+	return strings.Join(types, "|")
+}
+
+// cardinality estimates the distinct values of a path from the sample.
+func (a AttributeProfile) cardinality() string {
+	switch {
+	case a.Distinct == 0:
+		return "-"
+	case a.Distinct >= maxDistinctTracked:
+		return fmt.Sprintf("≥%d", maxDistinctTracked)
+	case a.Distinct == a.Count && a.Count > 1:
+		return "unique"
+	}
+	return fmt.Sprintf("%d", a.Distinct)
+}
+
+func (p *TableProfile) presence(a AttributeProfile) float64 {
+	if p.Items == 0 {
+		return 0
+	}
+	return 100 * float64(a.Count) / float64(p.Items)
+}
+
+// promptText describes the most common attributes for the Bedrock prompt.
+func (p *TableProfile) promptText() string {
+	attrs := append([]AttributeProfile{}, p.Attributes...)
+	sort.SliceStable(attrs, func(i, j int) bool { return attrs[i].Count > attrs[j].Count })
+	if len(attrs) > promptProfileAttributes {
+		attrs = attrs[:promptProfileAttributes]
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Attributes seen in a sample of %d items (path: types, share of items, distinct values, examples):\n", p.Items)
+	for _, a := range attrs {
+		fmt.Fprintf(&b, "- %s: %s, %.0f%%", a.Path, a.typeList(), p.presence(a))
+		if a.Distinct > 0 {
+			fmt.Fprintf(&b, ", distinct: %s, e.g. %s", a.cardinality(), strings.Join(a.Examples, ", "))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func profilePath(account, region, table string) (string, error) {
+	configDir, err := getConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "profiles", account+"-"+region, table+".json"), nil
+}
+
+// LoadProfile reads the cached profile of a table; it returns nil when the
+// table was never profiled.
+func LoadProfile(account, region, table string) (*TableProfile, error) {
+	path, err := profilePath(account, region, table)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var p TableProfile
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func SaveProfile(p *TableProfile) error {
+	path, err := profilePath(p.Account, p.Region, p.Table)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// profileFor returns the profile of a table from memory or the disk cache,
+// or nil when there is none.
+func (m *model) profileFor(table string) *TableProfile {
+	if p, ok := m.profiles[table]; ok {
+		return p
+	}
+	p, err := LoadProfile(m.AccountId, m.Region, table)
+	if err != nil {
+		m.notice = fmt.Sprintf("Couldn't read the cached profile of %s: %v", table, err)
+	}
+	if p != nil {
+		m.profiles[table] = p
+	}
+	return p
+}
+
+// openProfile shows the profile of the table under the cursor, sampling it
+// first when there is no cached profile or resample is set.
+func (m *model) openProfile(resample bool) tea.Cmd {
+	t := m.tables[m.tableCursor]
+	if m.view != viewProfile {
+		m.previousView = m.view
+	}
+	if p := m.profileFor(t.Name); p != nil && !resample {
+		m.view = viewProfile
+		m.profileCursor = 0
+		return nil
+	}
+	size := m.config.ProfileSampleSize
+	if size <= 0 {
+		size = defaultProfileSampleSize
+	}
+	segments := max(m.config.ProfileSegments, 1)
+	m.loading = true
+	m.view = viewLoading
+	m.statusMessage = fmt.Sprintf("Sampling up to %d items of %s...", size, t.Name)
+	return profileTableCmd(m.aws, m.AccountId, m.Region, t.Name, size, segments)
+}
+
+func (m *model) updateProfile(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	p := m.profiles[m.tables[m.tableCursor].Name]
+	switch msg.String() {
+	case "esc", "q":
+		m.view = m.previousView
+	case "up", "k":
+		m.profileCursor = max(m.profileCursor-1, 0)
+	case "down", "j":
+		m.profileCursor = max(min(m.profileCursor+1, len(p.Attributes)-1), 0)
+	case "ctrl+d":
+		m.profileCursor = max(min(m.profileCursor+10, len(p.Attributes)-1), 0)
+	case "ctrl+u":
+		m.profileCursor = max(m.profileCursor-10, 0)
+	case "r":
+		return m, m.openProfile(true)
+	}
+	return m, nil
+}
+
+// renderProfile draws the attribute statistics of the selected table.
+func (m model) renderProfile() string {
+	t := m.tables[m.tableCursor]
+	p := m.profiles[t.Name]
+	header := m.renderHeader("Profile: " + t.Name)
+	dim := lipgloss.NewStyle().Foreground(textDim)
+
+	how := "scan from the start of the table"
+	if p.Segments > 1 {
+		how = fmt.Sprintf("%d-segment scan", p.Segments)
+	}
+	summary := fmt.Sprintf("%d items sampled (%s) on %s · %.1f RCU · %d attribute paths",
+		p.Items, how, p.SampledAt.Local().Format("2006-01-02 15:04"), p.Capacity.ReadUnits, len(p.Attributes))
+
+	pathW := 30
+	exampleW := max(m.width-pathW-34, 10)
+	row := func(path, types, present, distinct, examples string) string {
+		return fmt.Sprintf("%-*s %-10s %8s %9s  %s", pathW, truncateText(path, pathW), types, present, distinct, truncateText(examples, exampleW))
+	}
+	lines := []string{
+		dim.Render(summary),
+		"",
+		listHeaderStyle.Width(m.width - 4).Render(row("ATTRIBUTE", "TYPES", "PRESENT", "DISTINCT", "EXAMPLES")),
+	}
+	if len(p.Attributes) == 0 {
+		lines = append(lines, itemRowStyle.Render("The sample was empty."))
+	}
+	start, end := windowRange(len(p.Attributes), m.profileCursor, max(m.height-12, 1))
+	for i := start; i < end; i++ {
+		a := p.Attributes[i]
+		path := a.Path
+		if a.Path == t.PK || a.Path == t.SK {
+			path += " (key)"
+		}
+		line := row(path, a.typeList(), fmt.Sprintf("%.0f%%", p.presence(a)), a.cardinality(), strings.Join(a.Examples, ", "))
+		if i == m.profileCursor {
+			lines = append(lines, listSelectedStyle.Width(m.width-4).Render(line))
+		} else {
+			lines = append(lines, listItemStyle.Render(line))
+		}
+	}
+	lines = append(lines, "", dim.Render("r re-sample · esc back · the profile is cached on disk and sent with AI queries on this table"))
+
+	return lipgloss.JoinVertical(lipgloss.Left, header, m.renderTabs(), lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
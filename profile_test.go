@@ -0,0 +1,47 @@
+package main
+
+import "testing"
+
+func TestBuildProfile(t *testing.T) {
+	items := []map[string]interface{}{
+		{"id": "a", "age": 30.0, "address": map[string]interface{}{"city": "Oslo"}, "tags": []interface{}{"x", "y", "x"}},
+		{"id": "b", "age": "31", "status": "active"},
+		{"id": "c", "status": "active", "address": map[string]interface{}{"city": "Rome"}},
+	}
+	byPath := make(map[string]AttributeProfile)
+	for _, a := range buildProfile(items) {
+		byPath[a.Path] = a
+	}
+
+	cases := []struct {
+		path        string
+		count       int
+		types       string
+		cardinality string
+	}{
+		{"id", 3, "S", "unique"},
+		{"age", 2, "N|S", "unique"},
+		{"status", 2, "S", "1"},
+		{"address", 2, "M", "-"},
+		{"address.city", 2, "S", "unique"},
+		{"tags", 1, "L", "-"},
+		{"tags[]", 1, "S", "2"}, // Counted once per item, however often the list repeats it
+	}
+	for _, c := range cases {
+		a, ok := byPath[c.path]
+		if !ok {
+			t.Errorf("%s: missing", c.path)
+			continue
+		}
+		if a.Count != c.count || a.typeList() != c.types || a.cardinality() != c.cardinality {
+			t.Errorf("%s: count %d, types %s, cardinality %s; want %d, %s, %s",
+				c.path, a.Count, a.typeList(), a.cardinality(), c.count, c.types, c.cardinality)
+		}
+	}
+	if ex := byPath["status"].Examples; len(ex) != 1 || ex[0] != "'active'" {
+		t.Errorf("status examples = %v", ex)
+	}
+	if len(byPath) != len(cases) {
+		t.Errorf("got %d paths, want %d", len(byPath), len(cases))
+	}
+}
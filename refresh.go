@@ -0,0 +1,206 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// refreshIntervals are cycled with R; zero turns auto-refresh off.
+var refreshIntervals = []time.Duration{0, 5 * time.Second, 15 * time.Second, 30 * time.Second, time.Minute}
+
+// changeHighlight is how long rows stay marked after a refresh changed them.
+const changeHighlight = 3 * time.Second
+
+// Row changes found by a refresh
+const (
+	changeAdded   byte = '+'
+	changeChanged byte = '~'
+	changeRemoved byte = '-'
+)
+
+// itemKeyText identifies an item by its primary key values.
+func itemKeyText(item Item, t Table) string {
+	if t.SK == "" {
+		return fmt.Sprintf("%T:%v", item[t.PK], item[t.PK])
+	}
+	return fmt.Sprintf("%T:%v\x00%T:%v", item[t.PK], item[t.PK], item[t.SK], item[t.SK])
+}
+
+// refreshCost estimates what one refresh of s reads from t: its query
+// statements, where one without a key condition is a full scan, or the scan
+// pages it has loaded.
+func (s *session) refreshCost(t Table) Capacity {
+	if s.isCustomQuery {
+		return estimateStatements(t, s.query)
+	}
+	return estimateScanPages(t, s.pages)
+}
+
+// perMinute is the read capacity of refreshing every d for a minute.
+func perMinute(c Capacity, every time.Duration) float64 {
+	return c.ReadUnits * float64(time.Minute) / float64(every)
+}
+
+// refreshAllowed reports whether refreshing every d stays within the scan
+// confirmation limit, both for one refresh and per minute.
+func (m *model) refreshAllowed(c Capacity, every time.Duration) bool {
+	limit := m.config.ScanConfirmRCU
+	return limit < 0 || (c.ReadUnits <= limit && perMinute(c, every) <= limit)
+}
+
+// cycleRefresh steps the active tab to the next auto-refresh interval and
+// starts a new refresh loop; the previous loop ends on its next tick.
+// Intervals that would read more than scan_confirm_rcu, in one refresh or
+// per minute, are skipped.
+func (m *model) cycleRefresh() tea.Cmd {
+	if m.isCustomQuery && len(m.query) == 0 {
+		m.notice = "This result can't be re-run, so it can't auto-refresh"
+		return nil
+	}
+	cost := m.refreshCost(m.tables[m.tableCursor])
+	next := 0
+	for i, d := range refreshIntervals {
+		if d == m.refreshEvery {
+			next = (i + 1) % len(refreshIntervals)
+		}
+	}
+	skipped := false
+	for next != 0 && !m.refreshAllowed(cost, refreshIntervals[next]) {
+		next = (next + 1) % len(refreshIntervals)
+		skipped = true
+	}
+	m.refreshEvery = refreshIntervals[next]
+	m.refreshRCU = 0
+	m.refreshGen++
+	switch {
+	case m.refreshEvery == 0 && skipped:
+		m.notice = fmt.Sprintf("Auto-refresh off: one refresh reads ≈%.0f RCU, above your %.0f RCU scan_confirm_rcu", cost.ReadUnits, m.config.ScanConfirmRCU)
+		return nil
+	case m.refreshEvery == 0:
+		m.notice = "Auto-refresh off"
+		return nil
+	}
+	m.refreshRCU = perMinute(cost, m.refreshEvery)
+	m.notice = fmt.Sprintf("Auto-refresh every %s, ≈%.0f RCU/min", m.refreshEvery, m.refreshRCU)
+	if skipped {
+		m.notice += fmt.Sprintf(" (faster intervals exceed your %.0f RCU scan_confirm_rcu)", m.config.ScanConfirmRCU)
+	}
+	return refreshTickCmd(m.session, m.refreshGen, m.refreshEvery)
+}
+
+// stopRefresh turns auto-refresh off and forgets the change marks.
+func (s *session) stopRefresh() {
+	s.refreshEvery = 0
+	s.refreshGen++
+	s.changes = make(map[int]byte)
+}
+
+// refreshBadge shows the auto-refresh interval in the items header.
+func (s *session) refreshBadge() string {
+	if s.refreshEvery == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" · ⟳ %s ≈%.0f RCU/min", s.refreshEvery, s.refreshRCU)
+}
+
+// refreshBusy reports whether a refresh of s has to wait: applying it now
+// would shift the items under an open dialog, edit or load.
+func (m *model) refreshBusy(s *session) bool {
+	if s != m.session {
+		return false
+	}
+	return m.loading || m.cellEdit != nil || (m.view != viewTableItems && m.view != viewStream)
+}
+
+// applyRefresh merges freshly fetched items into the active session by
+// primary key. Unchanged items are left as they are, changed and added ones
+// are marked, and items that are gone stay as removed rows until the marks
+// expire. Items with unsaved edits are never overwritten or removed. The
+// cursor stays on its item.
+func (m *model) applyRefresh(fresh []Item, t Table) (added, changed, removed int) {
+	m.dropRemovedRows()
+	m.changes = make(map[int]byte)
+
+	old := make(map[string]int, len(m.items))
+	for i, item := range m.items {
+		old[itemKeyText(item, t)] = i
+	}
+
+	seen := make(map[int]bool, len(fresh))
+	order := make([]int, 0, len(m.items)+len(fresh))
+	for _, f := range fresh {
+		i, ok := old[itemKeyText(f, t)]
+		if ok && seen[i] {
+			continue
+		}
+		switch {
+		case !ok:
+			m.items = append(m.items, f)
+			i = len(m.items) - 1
+			m.changes[i] = changeAdded
+			added++
+		case m.modifiedItems[i]:
+		case !reflect.DeepEqual(m.items[i], f):
+			m.items[i] = f
+			m.changes[i] = changeChanged
+			changed++
+		}
+		seen[i] = true
+		order = append(order, i)
+	}
+
+	// Items that are gone keep their place after the item they followed
+	after := make(map[int][]int)
+	prev := -1
+	for i := range len(m.items) - added {
+		if seen[i] {
+			prev = i
+			continue
+		}
+		if !m.modifiedItems[i] {
+			m.changes[i] = changeRemoved
+			removed++
+		}
+		after[prev] = append(after[prev], i)
+	}
+	perm := append([]int{}, after[-1]...)
+	for _, i := range order {
+		perm = append(perm, i)
+		perm = append(perm, after[i]...)
+	}
+
+	m.permuteItems(perm)
+	m.sortItems()
+	m.snapCursorToFilter()
+	return added, changed, removed
+}
+
+// dropRemovedRows takes the rows a refresh marked as removed out of the list.
+func (m *model) dropRemovedRows() {
+	var gone []int
+	for i, c := range m.changes {
+		if c == changeRemoved {
+			gone = append(gone, i)
+		}
+	}
+	if len(gone) > 0 {
+		m.removeItems(gone)
+	}
+}
+
+// changeStyle colors the key cells of a row a refresh changed.
+func changeStyle(style lipgloss.Style, c byte) lipgloss.Style {
+	switch c {
+	case changeAdded:
+		return style.Foreground(secondary).Bold(true)
+	case changeChanged:
+		return style.Foreground(warning).Bold(true)
+	case changeRemoved:
+		return style.Foreground(alert).Strikethrough(true)
+	}
+	return style
+}
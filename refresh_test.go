@@ -0,0 +1,101 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestApplyRefresh(t *testing.T) {
+	table := Table{Name: "t", PK: "id"}
+	m := model{
+		tables: []Table{table},
+		session: &session{
+			table:         "t",
+			items:         []Item{{"id": "a", "n": 1.0}, {"id": "b", "n": 1.0}, {"id": "c", "n": 1.0}, {"id": "d", "n": 1.0}},
+			modifiedItems: map[int]bool{3: true},
+			newItems:      map[int]bool{},
+			editedAttrs:   map[int]map[string]bool{},
+			selected:      map[int]bool{},
+			changes:       map[int]byte{},
+			visualAnchor:  -1,
+			itemCursor:    2,
+		},
+	}
+
+	// b is gone, c changed, e is new and d has local edits the refresh must keep
+	fresh := []Item{{"id": "a", "n": 1.0}, {"id": "c", "n": 2.0}, {"id": "e", "n": 1.0}}
+	added, changed, removed := m.applyRefresh(fresh, table)
+	if added != 1 || changed != 1 || removed != 1 {
+		t.Errorf("added, changed, removed = %d, %d, %d; want 1, 1, 1", added, changed, removed)
+	}
+
+	got := ""
+	for i, item := range m.items {
+		mark := m.changes[i]
+		if mark == 0 {
+			mark = ' '
+		}
+		got += string(mark) + item["id"].(string) + " "
+	}
+	if want := " a -b ~c  d +e "; got != want {
+		t.Errorf("rows = %q, want %q", got, want)
+	}
+	if m.items[m.itemCursor]["id"] != "c" {
+		t.Errorf("cursor on %v, want c", m.items[m.itemCursor]["id"])
+	}
+	if !m.modifiedItems[3] || m.items[3]["id"] != "d" {
+		t.Errorf("modified flag did not follow d: %v", m.modifiedItems)
+	}
+
+	// The removed row goes away once the marks expire
+	m.dropRemovedRows()
+	if len(m.items) != 4 || m.items[1]["id"] != "c" || m.items[m.itemCursor]["id"] != "c" {
+		t.Errorf("after expiry: items %v, cursor %d", m.items, m.itemCursor)
+	}
+}
+
+func TestCycleRefreshStaysUnderScanLimit(t *testing.T) {
+	// Each item is 1 KB, so one refresh of a loaded page reads ≈122.5 RCU
+	s := newSession()
+	s.table = "t"
+	s.pages = 1
+	m := model{
+		tables:  []Table{{Name: "t", PK: "id", ItemCount: 10000, SizeBytes: 10_000_000}},
+		session: s,
+		config:  Config{ScanConfirmRCU: 500},
+	}
+
+	// 5s would read ≈1470 RCU a minute, so the first step is 15s
+	m.cycleRefresh()
+	if m.refreshEvery != 15*time.Second {
+		t.Fatalf("first step = %s, want 15s", m.refreshEvery)
+	}
+	if got, want := m.refreshBadge(), " · ⟳ 15s ≈490 RCU/min"; got != want {
+		t.Errorf("badge = %q, want %q", got, want)
+	}
+
+	// A query without a key condition is priced as a full scan
+	m.refreshEvery = 0
+	m.isCustomQuery = true
+	m.query = []string{`SELECT * FROM "t" WHERE "status" = 'new'`}
+	m.cycleRefresh()
+	if m.refreshEvery != 0 || !strings.Contains(m.notice, "above your 500 RCU") {
+		t.Errorf("full-scan query: every %s notice %q, want off over the limit", m.refreshEvery, m.notice)
+	}
+
+	// Zero confirms every scan, so nothing refreshes on its own
+	m.isCustomQuery, m.query = false, nil
+	m.config.ScanConfirmRCU = 0
+	m.cycleRefresh()
+	if m.refreshEvery != 0 {
+		t.Errorf("with scan_confirm_rcu 0: every %s, want off", m.refreshEvery)
+	}
+
+	// A negative limit turns the gate off
+	m.config.ScanConfirmRCU = -1
+	m.cycleRefresh()
+	if m.refreshEvery != 5*time.Second {
+		t.Errorf("with the gate off: every %s, want 5s", m.refreshEvery)
+	}
+}
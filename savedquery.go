@@ -0,0 +1,509 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Saved query forms
+const (
+	formSaveQuery   = "save_query"
+	formQueryParams = "query_params"
+)
+
+// Fields of the save query form
+const (
+	sqName = iota
+	sqScope
+)
+
+// SavedQuery is a named question, or the statements or plan generated for
+// one, kept in queries.json next to config.json. Result runs as saved,
+// without asking Bedrock again; a query with only a Question is re-asked.
+type SavedQuery struct {
+	Name     string     `json:"name"`
+	Scope    string     `json:"scope"` // Table name, or a pattern such as orders-* (see path.Match)
+	Question string     `json:"question,omitempty"`
+	Result   *LLMResult `json:"result,omitempty"` // The table's name is stored as {{table}}
+	Saved    time.Time  `json:"saved"`
+}
+
+// queryForm is the saved query a save or parameter form works on.
+type queryForm struct {
+	query SavedQuery
+	back  currentView // previousView of the form's caller, restored when the form closes
+}
+
+// paramPattern finds {{name}} placeholders. {{PK}}, {{SK}} and {{table}} are
+// filled in by DynoTUI; any other name is asked for when the query runs.
+var paramPattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+func savedQueriesPath() (string, error) {
+	configDir, err := getConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "queries.json"), nil
+}
+
+// LoadSavedQueries reads every saved query; there are none until the first
+// one is saved.
+func LoadSavedQueries() ([]SavedQuery, error) {
+	path, err := savedQueriesPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var qs []SavedQuery
+	if err := json.Unmarshal(data, &qs); err != nil {
+		return nil, err
+	}
+	return qs, nil
+}
+
+func SaveSavedQueries(qs []SavedQuery) error {
+	path, err := savedQueriesPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(qs, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// appliesTo reports whether the query is saved for table.
+func (q SavedQuery) appliesTo(table string) bool {
+	ok, _ := path.Match(q.Scope, table)
+	return ok || q.Scope == table
+}
+
+// kind says what running the query does.
+func (q SavedQuery) kind() string {
+	switch {
+	case q.Result == nil:
+		return "question"
+	case q.Result.Mode == "plan" && q.Result.Plan != nil:
+		return "plan"
+	}
+	return "sql"
+}
+
+// text is the query as listed in the picker.
+func (q SavedQuery) text() string {
+	if q.Result == nil {
+		return q.Question
+	}
+	return strings.TrimPrefix(generatedText(*q.Result), "SQL: ")
+}
+
+// partiql lists pointers to every PartiQL string of a result.
+func partiql(r *LLMResult) []*string {
+	var out []*string
+	for i := range r.Statements {
+		out = append(out, &r.Statements[i])
+	}
+	if p := r.Plan; p != nil {
+		out = append(out, &p.Read.Partiql)
+		if p.Write != nil {
+			out = append(out, &p.Write.PerItem.PartiqlTemplate)
+		}
+	}
+	return out
+}
+
+// copyResult deep-copies a result so a saved query and the model's result
+// never share a plan.
+func copyResult(r LLMResult) *LLMResult {
+	data, _ := json.Marshal(r)
+	var c LLMResult
+	json.Unmarshal(data, &c)
+	return &c
+}
+
+// newSavedQuery keeps question and, when r is set, what was generated for
+// it, with the table's name turned into {{table}} so the query also runs on
+// the other tables of its scope. The name is replaced where it is quoted and
+// where it follows FROM, UPDATE or INTO unquoted.
+func newSavedQuery(question string, r *LLMResult, table string) SavedQuery {
+	q := SavedQuery{Scope: table, Question: question}
+	if r != nil {
+		q.Result = copyResult(*r)
+		bare := regexp.MustCompile(`(?i)\b(FROM|UPDATE|INTO)(\s+)` + regexp.QuoteMeta(table) + `($|[^A-Za-z0-9_.\-])`)
+		for _, s := range partiql(q.Result) {
+			*s = strings.ReplaceAll(*s, `"`+table+`"`, `"{{table}}"`)
+			*s = bare.ReplaceAllString(*s, `$1$2"{{table}}"$3`)
+		}
+	}
+	return q
+}
+
+// params returns the names of the values the query asks for, in order of
+// appearance.
+func (q SavedQuery) params() []string {
+	texts := []string{q.Question}
+	if q.Result != nil {
+		for _, s := range partiql(q.Result) {
+			texts = append(texts, *s)
+		}
+		if p := q.Result.Plan; p != nil && p.Write != nil {
+			for _, s := range p.Write.PerItem.Set {
+				texts = append(texts, s.Expr)
+			}
+		}
+	}
+	seen := map[string]bool{"PK": true, "SK": true, "table": true}
+	var names []string
+	for _, text := range texts {
+		for _, match := range paramPattern.FindAllStringSubmatch(text, -1) {
+			if !seen[match[1]] {
+				seen[match[1]] = true
+				names = append(names, match[1])
+			}
+		}
+	}
+	return names
+}
+
+// numberLiteral is a value that may stand outside quotes in PartiQL and
+// expressions.
+var numberLiteral = regexp.MustCompile(`^-?(\d+\.?\d*|\.\d+)([eE][+-]?\d+)?$`)
+
+// fill returns a copy of the query for table with its parameters replaced
+// by values. In PartiQL and expressions a value is escaped for where its
+// placeholder stands, so it can't end the literal or name around it: single
+// quotes are doubled inside '...', double quotes inside "...", and a value
+// outside quotes must be a number. The question takes values as typed.
+func (q SavedQuery) fill(table string, values map[string]string) (SavedQuery, error) {
+	q.Question = paramPattern.ReplaceAllStringFunc(q.Question, func(match string) string {
+		name := paramPattern.FindStringSubmatch(match)[1]
+		if name == "table" {
+			return table
+		}
+		if v, ok := values[name]; ok {
+			return v
+		}
+		return match
+	})
+	if q.Result == nil {
+		return q, nil
+	}
+	q.Result = copyResult(*q.Result)
+	texts := partiql(q.Result)
+	if p := q.Result.Plan; p != nil && p.Write != nil {
+		for i := range p.Write.PerItem.Set {
+			texts = append(texts, &p.Write.PerItem.Set[i].Expr)
+		}
+	}
+	for _, s := range texts {
+		filled, err := fillQuoted(*s, table, values)
+		if err != nil {
+			return q, err
+		}
+		*s = filled
+	}
+	return q, nil
+}
+
+// fillQuoted replaces the parameters of one PartiQL statement or expression,
+// escaping each value for the quotes its placeholder stands in.
+func fillQuoted(s, table string, values map[string]string) (string, error) {
+	var b strings.Builder
+	var quote byte // The quote the text is in, or 0 outside quotes
+	last := 0
+	for _, m := range paramPattern.FindAllStringSubmatchIndex(s, -1) {
+		for i := last; i < m[0]; i++ {
+			switch c := s[i]; {
+			case quote == 0 && (c == '\'' || c == '"'):
+				quote = c
+			case c == quote:
+				quote = 0 // A doubled quote closes and reopens, so it comes out even
+			}
+		}
+		b.WriteString(s[last:m[0]])
+		last = m[1]
+
+		name := s[m[2]:m[3]]
+		v, ok := values[name]
+		switch {
+		case name == "table":
+			v = table
+		case !ok:
+			b.WriteString(s[m[0]:m[1]]) // {{PK}} and {{SK}} are filled per item
+			continue
+		}
+		switch {
+		case quote != 0:
+			q := string(quote)
+			b.WriteString(strings.ReplaceAll(v, q, q+q))
+		case name == "table":
+			b.WriteString(quoteIdent(v))
+		case numberLiteral.MatchString(strings.TrimSpace(v)):
+			b.WriteString(strings.TrimSpace(v))
+		default:
+			return "", fmt.Errorf("%s: %q is not a number; put {{%s}} in quotes to use it as text", name, v, name)
+		}
+	}
+	b.WriteString(s[last:])
+	return b.String(), nil
+}
+
+// openSaveQuery asks for a name and scope under which to save a question,
+// and what was generated for it when r is set.
+func (m *model) openSaveQuery(question string, r *LLMResult) tea.Cmd {
+	t := m.tables[m.tableCursor]
+	q := newSavedQuery(question, r, t.Name)
+	info := []string{
+		"Saves the question; running it asks Bedrock again.",
+		"A {{name}} in the question is asked for on each run.",
+	}
+	if r != nil {
+		info = []string{
+			"Saves the statements as reviewed; running them doesn't call Bedrock.",
+			"To ask for a value on each run, edit (e) it into {{name}} before saving.",
+		}
+	}
+	back := m.previousView
+	f := &schemaForm{
+		kind:   formSaveQuery,
+		title:  "Save query",
+		submit: "save",
+		info:   info,
+		fields: []formField{
+			textField("Name", "", truncateText(question, 40)),
+			textField("Table or pattern", "e.g. orders-*", t.Name),
+		},
+		query: &queryForm{query: q, back: back},
+	}
+	return m.openSchemaForm(f)
+}
+
+// submitSaveQuery stores the query, replacing one of the same name and scope.
+func (m *model) submitSaveQuery() tea.Cmd {
+	f := m.schemaForm
+	q := f.query.query
+	q.Name, q.Scope = f.fields[sqName].value(), f.fields[sqScope].value()
+	if q.Name == "" || q.Scope == "" {
+		f.err = fmt.Errorf("a name and a table or pattern are required")
+		return nil
+	}
+	if _, err := path.Match(q.Scope, ""); err != nil {
+		f.err = fmt.Errorf("%q is not a valid pattern", q.Scope)
+		return nil
+	}
+	qs, err := LoadSavedQueries()
+	if err != nil {
+		f.err = fmt.Errorf("couldn't read the saved queries: %w", err)
+		return nil
+	}
+	q.Saved = time.Now()
+	replaced := false
+	for i := range qs {
+		if qs[i].Name == q.Name && qs[i].Scope == q.Scope {
+			qs[i], replaced = q, true
+		}
+	}
+	if !replaced {
+		qs = append(qs, q)
+	}
+	if err := SaveSavedQueries(qs); err != nil {
+		f.err = err
+		return nil
+	}
+	m.closeQueryForm()
+	m.notice = fmt.Sprintf("Saved %q for %s; Q lists saved queries", q.Name, q.Scope)
+	if replaced {
+		m.notice = fmt.Sprintf("Replaced the saved query %q for %s", q.Name, q.Scope)
+	}
+	return nil
+}
+
+// closeQueryForm goes back to the view the form was opened from.
+func (m *model) closeQueryForm() {
+	back := m.schemaForm.query.back
+	m.schemaForm = nil
+	m.view, m.previousView = m.previousView, back
+}
+
+// openSavedQueries lists the queries saved for the selected table.
+func (m *model) openSavedQueries() {
+	qs, err := LoadSavedQueries()
+	if err != nil {
+		m.notice = fmt.Sprintf("Couldn't read the saved queries: %v", err)
+		return
+	}
+	m.savedQueries = qs
+	m.savedCursor = 0
+	m.previousView = m.view
+	m.view = viewSavedQueries
+}
+
+// savedMatches returns the indexes of the saved queries of the selected table.
+func (m *model) savedMatches() []int {
+	t := m.tables[m.tableCursor]
+	var out []int
+	for i, q := range m.savedQueries {
+		if q.appliesTo(t.Name) {
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
+func (m *model) updateSavedQueries(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	matches := m.savedMatches()
+	last := max(len(matches)-1, 0)
+	switch msg.String() {
+	case "esc", "q":
+		m.view = m.previousView
+		m.savedQueries = nil
+	case "up", "k":
+		m.savedCursor = max(m.savedCursor-1, 0)
+	case "down", "j":
+		m.savedCursor = min(m.savedCursor+1, last)
+	case "enter":
+		if len(matches) > 0 {
+			return m, m.runSaved(m.savedQueries[matches[m.savedCursor]])
+		}
+	case "d":
+		if len(matches) > 0 {
+			i := matches[m.savedCursor]
+			name := m.savedQueries[i].Name
+			qs := append(append([]SavedQuery{}, m.savedQueries[:i]...), m.savedQueries[i+1:]...)
+			if err := SaveSavedQueries(qs); err != nil {
+				m.notice = fmt.Sprintf("Couldn't delete %q: %v", name, err)
+				return m, nil
+			}
+			m.savedQueries = qs
+			m.savedCursor = min(m.savedCursor, max(len(matches)-2, 0))
+			m.notice = fmt.Sprintf("Deleted the saved query %q", name)
+		}
+	}
+	return m, nil
+}
+
+// runSaved runs a saved query on the selected table, asking for its
+// parameters first when it has any.
+func (m *model) runSaved(q SavedQuery) tea.Cmd {
+	back := m.previousView
+	names := q.params()
+	if len(names) == 0 {
+		m.view = back
+		filled, err := q.fill(m.tables[m.tableCursor].Name, nil)
+		if err != nil {
+			m.notice = fmt.Sprintf("Couldn't run %q: %v", q.Name, err)
+			return nil
+		}
+		return m.execSaved(filled)
+	}
+	f := &schemaForm{
+		kind:   formQueryParams,
+		title:  "Run " + q.Name,
+		submit: "run",
+		info:   []string{truncateText(q.text(), 70)},
+		query:  &queryForm{query: q, back: back},
+	}
+	for _, name := range names {
+		f.fields = append(f.fields, textField(name, "", ""))
+	}
+	return m.openSchemaForm(f)
+}
+
+// submitQueryParams runs the saved query with the values typed in the form.
+func (m *model) submitQueryParams() tea.Cmd {
+	f := m.schemaForm
+	values := make(map[string]string)
+	for _, field := range f.fields {
+		values[field.label] = field.value()
+	}
+	q, err := f.query.query.fill(m.tables[m.tableCursor].Name, values)
+	if err != nil {
+		f.err = err
+		return nil
+	}
+	m.schemaForm = nil
+	m.previousView = f.query.back
+	m.view = f.query.back
+	return m.execSaved(q)
+}
+
+// execSaved puts a filled saved result up for confirmation as if Bedrock had
+// just returned it, or asks its question again when only the question was
+// saved.
+func (m *model) execSaved(q SavedQuery) tea.Cmd {
+	t := m.tables[m.tableCursor]
+	m.savedQueries = nil
+	if q.Result == nil {
+		return m.askBedrock(q.Question)
+	}
+	m.lastQuestion = q.Question
+	if m.lastQuestion == "" {
+		m.lastQuestion = fmt.Sprintf("saved query %q", q.Name)
+	}
+	m.llmResult = *q.Result
+	m.llmEdited, m.rejectedEdit, m.genEdit = false, "", nil
+	m.addTurn(t.Name, m.lastQuestion, m.llmResult)
+	if err := m.reviewGenerated(); err != nil {
+		m.settleTurn("rejected: "+err.Error(), true)
+		m.err = err
+		m.view = viewError
+		return nil
+	}
+	m.notice = fmt.Sprintf("Saved query %q, run as saved without asking Bedrock", q.Name)
+	return nil
+}
+
+// renderSavedQueries draws the saved query picker of the selected table.
+func (m model) renderSavedQueries() string {
+	t := m.tables[m.tableCursor]
+	header := m.renderHeader("Saved Queries: " + t.Name)
+	dim := lipgloss.NewStyle().Foreground(textDim)
+	matches := m.savedMatches()
+
+	nameW, scopeW := 24, 18
+	textW := max(m.width-nameW-scopeW-20, 10)
+	row := func(name, scope, kind, text string) string {
+		return fmt.Sprintf("%-*s %-*s %-8s %s", nameW, truncateText(name, nameW), scopeW, truncateText(scope, scopeW), kind, truncateText(text, textW))
+	}
+	lines := []string{
+		dim.Render(fmt.Sprintf("%d saved queries apply to this table · plans and statements run without calling Bedrock", len(matches))),
+		"",
+		listHeaderStyle.Width(m.width - 4).Render(row("NAME", "TABLE", "KIND", "QUERY")),
+	}
+	if len(matches) == 0 {
+		lines = append(lines, itemRowStyle.Render("Nothing saved yet: press s in the SQL confirmation, or ctrl+s in the / bar."))
+	}
+	start, end := windowRange(len(matches), m.savedCursor, max(m.height-12, 1))
+	for i := start; i < end; i++ {
+		q := m.savedQueries[matches[i]]
+		line := row(q.Name, q.Scope, q.kind(), strings.Join(strings.Fields(q.text()), " "))
+		if i == m.savedCursor {
+			lines = append(lines, listSelectedStyle.Width(m.width-4).Render(line))
+		} else {
+			lines = append(lines, listItemStyle.Render(line))
+		}
+	}
+	lines = append(lines, "", dim.Render("enter run · d delete · esc back"))
+
+	return lipgloss.JoinVertical(lipgloss.Left, header, m.renderTabs(), lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
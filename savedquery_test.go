@@ -0,0 +1,104 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSavedQueryFill(t *testing.T) {
+	r := &LLMResult{Mode: "plan", Plan: &PlanBlock{
+		Operation: "scan_then_write",
+		Read:      ReadBlock{Partiql: `SELECT * FROM "orders-prod" WHERE "status" = '{{status}}' AND "total" > {{ min }}`},
+		Write:     &WriteBlock{Action: "update"},
+	}}
+	r.Plan.Write.PerItem.PartiqlTemplate = `UPDATE "orders-prod" SET "note" = '{{note}}' WHERE "id" = {{PK}}`
+	q := newSavedQuery("orders by {{status}}", r, "orders-prod")
+	q.Scope = "orders-*"
+
+	if got := q.Result.Plan.Read.Partiql; got != `SELECT * FROM "{{table}}" WHERE "status" = '{{status}}' AND "total" > {{ min }}` {
+		t.Errorf("saved read = %s", got)
+	}
+	if r.Plan.Read.Partiql != `SELECT * FROM "orders-prod" WHERE "status" = '{{status}}' AND "total" > {{ min }}` {
+		t.Error("saving changed the model's result")
+	}
+	if got, want := q.params(), []string{"status", "min", "note"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("params = %v, want %v", got, want)
+	}
+
+	filled, err := q.fill("orders-dev", map[string]string{"status": "it's open", "min": "10", "note": "checked"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := filled.Result.Plan.Read.Partiql; got != `SELECT * FROM "orders-dev" WHERE "status" = 'it''s open' AND "total" > 10` {
+		t.Errorf("filled read = %s", got)
+	}
+	if got := filled.Result.Plan.Write.PerItem.PartiqlTemplate; got != `UPDATE "orders-dev" SET "note" = 'checked' WHERE "id" = {{PK}}` {
+		t.Errorf("filled template = %s", got)
+	}
+	if filled.Question != "orders by it's open" {
+		t.Errorf("filled question = %q", filled.Question)
+	}
+	if q.Result.Plan.Read.Partiql == filled.Result.Plan.Read.Partiql {
+		t.Error("filling changed the saved query")
+	}
+}
+
+func TestSavedQueryFillEscapesForItsQuotes(t *testing.T) {
+	q := SavedQuery{Result: &LLMResult{Mode: "sql", Statements: []string{
+		`SELECT "{{attr}}" FROM {{table}} WHERE "a" = '{{v}}' AND "n" > {{n}} AND "it''s {{v}}" = 'x'`,
+	}}}
+
+	filled, err := q.fill("orders", map[string]string{"attr": `x" FROM "secrets`, "v": "' OR '1'='1", "n": " 1.5e3 "})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `SELECT "x"" FROM ""secrets" FROM "orders" WHERE "a" = ''' OR ''1''=''1' AND "n" > 1.5e3 AND "it''s ' OR '1'='1" = 'x'`
+	if got := filled.Result.Statements[0]; got != want {
+		t.Errorf("filled = %s\nwant     %s", got, want)
+	}
+
+	// Outside quotes only a number may stand
+	for _, n := range []string{"1 OR 1=1", "Inf", "0x10", "", "1e5e5"} {
+		if _, err := q.fill("orders", map[string]string{"attr": "a", "v": "b", "n": n}); err == nil {
+			t.Errorf("unquoted %q: no error", n)
+		}
+	}
+}
+
+func TestNewSavedQueryUnquotedTable(t *testing.T) {
+	r := &LLMResult{Mode: "sql", Statements: []string{
+		`SELECT * FROM orders WHERE "id" = 'orders'`,
+		`update orders SET "n" = 1 WHERE "id" = 'a'`,
+		`INSERT INTO orders VALUE {'id': 'b'}`,
+		`SELECT * FROM orders_archive`,
+		`SELECT * FROM orders.by_status`,
+	}}
+	q := newSavedQuery("", r, "orders")
+	want := []string{
+		`SELECT * FROM "{{table}}" WHERE "id" = 'orders'`,
+		`update "{{table}}" SET "n" = 1 WHERE "id" = 'a'`,
+		`INSERT INTO "{{table}}" VALUE {'id': 'b'}`,
+		`SELECT * FROM orders_archive`,
+		`SELECT * FROM orders.by_status`,
+	}
+	if !reflect.DeepEqual(q.Result.Statements, want) {
+		t.Errorf("saved = %q, want %q", q.Result.Statements, want)
+	}
+}
+
+func TestSavedQueryAppliesTo(t *testing.T) {
+	cases := []struct {
+		scope, table string
+		want         bool
+	}{
+		{"orders", "orders", true},
+		{"orders-*", "orders-prod", true},
+		{"orders-*", "users-prod", false},
+		{"*", "anything", true},
+	}
+	for _, c := range cases {
+		if got := (SavedQuery{Scope: c.scope}).appliesTo(c.table); got != c.want {
+			t.Errorf("%s on %s = %v", c.scope, c.table, got)
+		}
+	}
+}
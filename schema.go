@@ -0,0 +1,610 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Schema forms
+const (
+	formCreateTable = "create_table"
+	formCreateIndex = "create_index"
+	formSettings    = "settings"
+)
+
+var keyTypes = []string{"S", "N", "B"}
+
+// formField is a text input, or a choice between options when options is set.
+type formField struct {
+	label   string
+	hint    string
+	input   textinput.Model
+	options []string
+	choice  int
+}
+
+func (f formField) value() string {
+	if f.options != nil {
+		return f.options[f.choice]
+	}
+	return strings.TrimSpace(f.input.Value())
+}
+
+// schemaForm collects a table or index definition.
+type schemaForm struct {
+	kind     string
+	table    string // Table the form changes, fixed when it opens; empty for create table
+	title    string
+	submit   string   // Verb shown for ctrl+s
+	info     []string // Read-only lines under the title
+	fields   []formField
+	cursor   int
+	err      error
+	settings *TableSettings // Settings form: the values loaded from DynamoDB
+	backup   *Backup        // Restore form: the backup to restore; nil for a point-in-time restore
+	query    *queryForm     // Saved query forms: the query being saved or run
+}
+
+func textField(label, hint, value string) formField {
+	ti := textinput.New()
+	ti.Prompt = ""
+	ti.CharLimit = 255
+	ti.Width = 40
+	ti.SetValue(value)
+	return formField{label: label, hint: hint, input: ti}
+}
+
+func choiceField(label string, options ...string) formField {
+	return formField{label: label, options: options}
+}
+
+// Fields of the create table form
+const (
+	ctName = iota
+	ctPK
+	ctPKType
+	ctSK
+	ctSKType
+	ctBilling
+	ctRead
+	ctWrite
+	ctGSIs
+	ctLSIs
+)
+
+func newCreateTableForm() *schemaForm {
+	return &schemaForm{
+		kind:   formCreateTable,
+		title:  "Create table",
+		submit: "create",
+		fields: []formField{
+			textField("Table name", "", ""),
+			textField("Partition key", "", ""),
+			choiceField("Partition key type", keyTypes...),
+			textField("Sort key", "optional", ""),
+			choiceField("Sort key type", keyTypes...),
+			choiceField("Billing mode", "On-demand", "Provisioned"),
+			textField("Read capacity", "provisioned only", "5"),
+			textField("Write capacity", "provisioned only", "5"),
+			textField("GSIs", "name=pk:S,sk:N; ...", ""),
+			textField("LSIs", "name=sk:N; ...", ""),
+		},
+	}
+}
+
+// Fields of the add index form
+const (
+	ciName = iota
+	ciPK
+	ciPKType
+	ciSK
+	ciSKType
+)
+
+func newIndexForm(t Table) *schemaForm {
+	return &schemaForm{
+		kind:   formCreateIndex,
+		table:  t.Name,
+		title:  "Add global secondary index to " + t.Name,
+		submit: "add",
+		fields: []formField{
+			textField("Index name", "", ""),
+			textField("Partition key", "", ""),
+			choiceField("Partition key type", keyTypes...),
+			textField("Sort key", "optional", ""),
+			choiceField("Sort key type", keyTypes...),
+		},
+	}
+}
+
+// parseKey reads "attr:TYPE", where the type defaults to S.
+func parseKey(s string) (name, typ string, err error) {
+	name, typ, _ = strings.Cut(strings.TrimSpace(s), ":")
+	name, typ = strings.TrimSpace(name), strings.ToUpper(strings.TrimSpace(typ))
+	if typ == "" {
+		typ = "S"
+	}
+	if name == "" {
+		return "", "", fmt.Errorf("missing key attribute in %q", s)
+	}
+	if typ != "S" && typ != "N" && typ != "B" {
+		return "", "", fmt.Errorf("key type of %s must be S, N or B, not %s", name, typ)
+	}
+	return name, typ, nil
+}
+
+// parseIndexSpecs reads the compact index notation of the create table form:
+// "byEmail=email:S; byDay=day:S,ts:N". Local indexes only take a sort key:
+// "byTs=ts:N".
+func parseIndexSpecs(s string, local bool) ([]IndexSpec, error) {
+	var out []IndexSpec
+	for _, part := range strings.Split(s, ";") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		name, keys, ok := strings.Cut(part, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("expected name=keys in %q", strings.TrimSpace(part))
+		}
+		parts := strings.Split(keys, ",")
+		idx := IndexSpec{Name: name}
+		var err error
+		switch {
+		case local && len(parts) == 1:
+			idx.SK, idx.SKType, err = parseKey(parts[0])
+		case local:
+			return nil, fmt.Errorf("local index %s takes only a sort key", name)
+		case len(parts) > 2:
+			return nil, fmt.Errorf("index %s has more than two keys", name)
+		default:
+			idx.PK, idx.PKType, err = parseKey(parts[0])
+			if err == nil && len(parts) == 2 {
+				idx.SK, idx.SKType, err = parseKey(parts[1])
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, idx)
+	}
+	return out, nil
+}
+
+// tableSpec validates the create table form.
+func (f *schemaForm) tableSpec() (TableSpec, error) {
+	v := func(i int) string { return f.fields[i].value() }
+	spec := TableSpec{
+		Name:     v(ctName),
+		PK:       v(ctPK),
+		PKType:   v(ctPKType),
+		SK:       v(ctSK),
+		SKType:   v(ctSKType),
+		OnDemand: v(ctBilling) == "On-demand",
+	}
+	if spec.Name == "" || spec.PK == "" {
+		return spec, fmt.Errorf("table name and partition key are required")
+	}
+	if spec.SK == "" {
+		spec.SKType = ""
+	}
+	if !spec.OnDemand {
+		var err error
+		if spec.ReadCapacity, err = strconv.ParseInt(v(ctRead), 10, 64); err != nil || spec.ReadCapacity < 1 {
+			return spec, fmt.Errorf("read capacity must be a positive number")
+		}
+		if spec.WriteCapacity, err = strconv.ParseInt(v(ctWrite), 10, 64); err != nil || spec.WriteCapacity < 1 {
+			return spec, fmt.Errorf("write capacity must be a positive number")
+		}
+	}
+	var err error
+	if spec.GSIs, err = parseIndexSpecs(v(ctGSIs), false); err != nil {
+		return spec, err
+	}
+	if spec.LSIs, err = parseIndexSpecs(v(ctLSIs), true); err != nil {
+		return spec, err
+	}
+	if len(spec.LSIs) > 0 && spec.SK == "" {
+		return spec, fmt.Errorf("local indexes need a table with a sort key")
+	}
+	return spec, nil
+}
+
+// indexSpec validates the add index form.
+func (f *schemaForm) indexSpec() (IndexSpec, error) {
+	v := func(i int) string { return f.fields[i].value() }
+	idx := IndexSpec{Name: v(ciName), PK: v(ciPK), PKType: v(ciPKType), SK: v(ciSK), SKType: v(ciSKType)}
+	if idx.Name == "" || idx.PK == "" {
+		return idx, fmt.Errorf("index name and partition key are required")
+	}
+	if idx.SK == "" {
+		idx.SKType = ""
+	}
+	return idx, nil
+}
+
+// focus moves the form cursor, focusing the text input under it.
+func (f *schemaForm) focus(i int) tea.Cmd {
+	f.fields[f.cursor].input.Blur()
+	f.cursor = (i + len(f.fields)) % len(f.fields)
+	if f.fields[f.cursor].options != nil {
+		return nil
+	}
+	f.fields[f.cursor].input.Focus()
+	return textinput.Blink
+}
+
+func (m *model) openSchemaForm(f *schemaForm) tea.Cmd {
+	m.schemaForm = f
+	m.previousView = m.view
+	m.view = viewSchemaForm
+	f.cursor = 0
+	return f.focus(0)
+}
+
+func (m *model) updateSchemaForm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	f := m.schemaForm
+	field := &f.fields[f.cursor]
+	switch msg.String() {
+	case "esc":
+		if f.query != nil {
+			m.closeQueryForm()
+			return m, nil
+		}
+		m.schemaForm = nil
+		m.view = m.previousView
+		return m, nil
+	case "up", "shift+tab":
+		return m, f.focus(f.cursor - 1)
+	case "down", "tab":
+		return m, f.focus(f.cursor + 1)
+	case "enter":
+		if f.cursor < len(f.fields)-1 {
+			return m, f.focus(f.cursor + 1)
+		}
+		return m, m.submitSchemaForm()
+	case "ctrl+s":
+		return m, m.submitSchemaForm()
+	}
+
+	if field.options != nil {
+		switch msg.String() {
+		case "left", "h":
+			field.choice = (field.choice + len(field.options) - 1) % len(field.options)
+		case "right", "l", " ":
+			field.choice = (field.choice + 1) % len(field.options)
+		}
+		return m, nil
+	}
+	var cmd tea.Cmd
+	field.input, cmd = field.input.Update(msg)
+	return m, cmd
+}
+
+// submitSchemaForm validates the form and starts the schema change.
+func (m *model) submitSchemaForm() tea.Cmd {
+	f := m.schemaForm
+	switch f.kind {
+	case formCreateTable:
+		spec, err := f.tableSpec()
+		if err != nil {
+			f.err = err
+			return nil
+		}
+		audit := m.newAuditEntry(auditCreateTable)
+		audit.Table = spec.Name
+		m.statusMessage = fmt.Sprintf("Creating table %s...", spec.Name)
+		m.schemaForm, m.loading, m.view = nil, true, viewLoading
+		return createTableCmd(m.aws, spec, audit)
+	case formCreateIndex:
+		idx, err := f.indexSpec()
+		if err != nil {
+			f.err = err
+			return nil
+		}
+		ti := m.tableIndex(f.table)
+		if ti < 0 {
+			f.err = fmt.Errorf("table %s no longer exists", f.table)
+			return nil
+		}
+		t := m.tables[ti]
+		audit := m.newAuditEntry(auditCreateIndex)
+		audit.Table = t.Name
+		audit.Index = idx.Name
+		m.statusMessage = fmt.Sprintf("Adding index %s to %s...", idx.Name, t.Name)
+		m.schemaForm, m.loading, m.view = nil, true, viewLoading
+		return createIndexCmd(m.aws, t, idx, audit)
+	case formSettings:
+		return m.submitSettings()
+	case formBackup, formRestore:
+		return m.submitBackupForm()
+	case formSaveQuery:
+		return m.submitSaveQuery()
+	case formQueryParams:
+		return m.submitQueryParams()
+	}
+	return nil
+}
+
+// dropRequest is a table, or one of its indexes, waiting for its name to be
+// typed as confirmation.
+type dropRequest struct {
+	table   string
+	indexes []string // Indexes that may be removed; nil when the table itself is dropped
+	input   textinput.Model
+}
+
+// typed returns the index named in the input, or the table when that is
+// what is being dropped. ok is false until a valid name is typed.
+func (d *dropRequest) typed() (index string, ok bool) {
+	v := strings.TrimSpace(d.input.Value())
+	if d.indexes == nil {
+		return "", v == d.table
+	}
+	for _, idx := range d.indexes {
+		if idx == v {
+			return idx, true
+		}
+	}
+	return "", false
+}
+
+// openDrop asks for the table name to be typed, or for the name of the index
+// to remove when indexes is set.
+func (m *model) openDrop(indexes []string) tea.Cmd {
+	ti := textinput.New()
+	ti.Prompt = "❯ "
+	ti.CharLimit = 255
+	ti.Width = 40
+	ti.Focus()
+	m.drop = &dropRequest{table: m.tables[m.tableCursor].Name, indexes: indexes, input: ti}
+	m.view = viewDropConfirm
+	return textinput.Blink
+}
+
+func (m *model) updateDrop(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	d := m.drop
+	switch msg.String() {
+	case "esc":
+		m.drop = nil
+		m.view = viewTableList
+		return m, nil
+	case "enter":
+		index, ok := d.typed()
+		if !ok {
+			return m, nil
+		}
+		m.drop = nil
+		m.loading, m.view = true, viewLoading
+		if index != "" {
+			audit := m.newAuditEntry(auditDeleteIndex)
+			audit.Table = d.table
+			audit.Index = index
+			m.statusMessage = fmt.Sprintf("Removing index %s from %s...", index, d.table)
+			return m, deleteIndexCmd(m.aws, d.table, index, audit)
+		}
+		audit := m.newAuditEntry(auditDeleteTable)
+		audit.Table = d.table
+		m.statusMessage = fmt.Sprintf("Deleting table %s...", d.table)
+		return m, deleteTableCmd(m.aws, d.table, audit)
+	}
+	var cmd tea.Cmd
+	d.input, cmd = d.input.Update(msg)
+	return m, cmd
+}
+
+// tableBusy reports whether a table or one of its indexes is still changing,
+// so its status keeps being polled.
+func tableBusy(t Table) bool {
+	transitional := func(s string) bool {
+		return s == "CREATING" || s == "UPDATING" || s == "DELETING"
+	}
+	if transitional(t.Status) {
+		return true
+	}
+	for _, s := range t.GSIStatus {
+		if transitional(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// tableStatusLabel is the table list badge of a busy table: its own status,
+// or the status of the index that is changing.
+func tableStatusLabel(t Table) string {
+	if t.Restoring && t.Status == "CREATING" {
+		return "RESTORING"
+	}
+	if t.Status != "ACTIVE" {
+		return t.Status
+	}
+	for _, idx := range t.GSIs {
+		if s := t.GSIStatus[idx]; s != "ACTIVE" && s != "" {
+			return "INDEX " + s
+		}
+	}
+	return t.Status
+}
+
+// pollTable starts polling the status of a table unless it already is.
+func (m *model) pollTable(name string) tea.Cmd {
+	if m.polling[name] {
+		return nil
+	}
+	if m.polling == nil {
+		m.polling = make(map[string]bool)
+	}
+	m.polling[name] = true
+	return pollTableCmd(m.aws, name)
+}
+
+// applySchemaChange shows a schema change that was just accepted in the
+// table list and starts polling the table until it settles.
+func (m *model) applySchemaChange(msg schemaChangedMsg) tea.Cmd {
+	i := m.tableIndex(msg.table)
+	switch msg.action {
+	case auditCreateTable:
+		if i < 0 {
+			t := Table{Name: msg.spec.Name, PK: msg.spec.PK, PKType: msg.spec.PKType, SK: msg.spec.SK,
+				SKType: msg.spec.SKType, Region: m.Region, Status: "CREATING", GSIStatus: map[string]string{}}
+			for _, idx := range msg.spec.GSIs {
+				t.GSIs = append(t.GSIs, idx.Name)
+				t.GSIStatus[idx.Name] = "CREATING"
+			}
+			m.tables = append(m.tables, t)
+			sort.SliceStable(m.tables, func(a, b int) bool { return m.tables[a].Name < m.tables[b].Name })
+			i = m.tableIndex(msg.table)
+		}
+		m.tableCursor = i
+	case auditDeleteTable:
+		if i >= 0 {
+			m.tables[i].Status = "DELETING"
+		}
+	case auditCreateIndex:
+		if i >= 0 {
+			if m.tables[i].GSIStatus == nil {
+				m.tables[i].GSIStatus = make(map[string]string)
+			}
+			m.tables[i].GSIs = append(m.tables[i].GSIs, msg.index)
+			m.tables[i].GSIStatus[msg.index] = "CREATING"
+		}
+	case auditDeleteIndex:
+		if i >= 0 && m.tables[i].GSIStatus != nil {
+			m.tables[i].GSIStatus[msg.index] = "DELETING"
+		}
+	case auditRestoreTable:
+		if i < 0 {
+			t := Table{Name: msg.table, Region: m.Region}
+			if src := m.tableIndex(msg.source); src >= 0 {
+				t = m.tables[src]
+				t.Name, t.ItemCount, t.SizeBytes = msg.table, 0, 0
+				t.GSIStatus = nil
+			}
+			t.Status, t.Restoring = "CREATING", true
+			m.tables = append(m.tables, t)
+			sort.SliceStable(m.tables, func(a, b int) bool { return m.tables[a].Name < m.tables[b].Name })
+			i = m.tableIndex(msg.table)
+		}
+		m.tableCursor = i
+	case auditUpdateSettings:
+		// Only a stream change leaves the table UPDATING; the poll finds out
+	}
+	return m.pollTable(msg.table)
+}
+
+// applyTableStatus updates a polled table, dropping it from the list once it
+// is gone, and polls again while it is still changing.
+func (m *model) applyTableStatus(msg tableStatusMsg) tea.Cmd {
+	delete(m.polling, msg.name)
+	i := m.tableIndex(msg.name)
+	if i < 0 {
+		return nil
+	}
+	if msg.err != nil {
+		m.notice = fmt.Sprintf("Stopped watching %s: %v", msg.name, msg.err)
+		return nil
+	}
+	if msg.gone {
+		cursor := ""
+		if m.tableCursor < len(m.tables) {
+			cursor = m.tables[m.tableCursor].Name
+		}
+		m.tables = append(m.tables[:i], m.tables[i+1:]...)
+		if j := m.tableIndex(cursor); j >= 0 {
+			m.tableCursor = j
+		} else {
+			m.tableCursor = max(0, min(i, len(m.tables)-1))
+		}
+		m.snapTableCursor(false)
+		m.notice = fmt.Sprintf("Table %s deleted", msg.name)
+		return nil
+	}
+
+	t := &m.tables[i]
+	d := msg.details
+	t.Status, t.Restoring, t.GSIs, t.GSIStatus = d.Status, d.Restoring, d.GSIs, d.GSIStatus
+	t.BillingMode, t.ReadCapacity, t.SizeBytes = d.BillingMode, d.ReadCapacity, d.SizeBytes
+	if tableBusy(*t) {
+		return m.pollTable(msg.name)
+	}
+	return nil
+}
+
+// tableIndex finds a table in m.tables by name, or returns -1.
+func (m *model) tableIndex(name string) int {
+	for i, t := range m.tables {
+		if t.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// renderSchemaForm draws the create table / add index form.
+func (m model) renderSchemaForm() string {
+	f := m.schemaForm
+	labelStyle := lipgloss.NewStyle().Foreground(textDim).Width(24)
+	activeLabel := lipgloss.NewStyle().Foreground(primary).Bold(true).Width(24)
+	hintStyle := lipgloss.NewStyle().Foreground(subtle)
+
+	lines := []string{lipgloss.NewStyle().Bold(true).Render(f.title)}
+	for _, l := range f.info {
+		lines = append(lines, hintStyle.Render(l))
+	}
+	lines = append(lines, "")
+	for i, field := range f.fields {
+		label := labelStyle.Render(field.label)
+		if i == f.cursor {
+			label = activeLabel.Render(field.label)
+		}
+		var value string
+		if field.options != nil {
+			parts := make([]string, len(field.options))
+			for o, opt := range field.options {
+				if o == field.choice {
+					parts[o] = lipgloss.NewStyle().Foreground(secondary).Bold(true).Render("[" + opt + "]")
+				} else {
+					parts[o] = hintStyle.Render(" " + opt + " ")
+				}
+			}
+			value = strings.Join(parts, " ")
+		} else {
+			value = field.input.View()
+			if field.hint != "" && field.input.Value() == "" && i != f.cursor {
+				value = hintStyle.Render(field.hint)
+			}
+		}
+		lines = append(lines, label+" "+value)
+	}
+	if f.err != nil {
+		lines = append(lines, "", lipgloss.NewStyle().Foreground(alert).Render(f.err.Error()))
+	}
+	lines = append(lines, "", hintStyle.Render(fmt.Sprintf("(tab/↑↓ move, ←→ choose, enter next, ctrl+s %s, esc cancel)", f.submit)))
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// renderDrop asks for the table or index name to be typed.
+func (m model) renderDrop() string {
+	d := m.drop
+	question := fmt.Sprintf("DELETE table %s and all of its items?", d.table)
+	prompt := "This action cannot be undone. Type " + d.table + " to confirm."
+	if d.indexes != nil {
+		question = fmt.Sprintf("Remove a global secondary index from %s?", d.table)
+		prompt = "Type the name of the index to remove: " + strings.Join(d.indexes, ", ")
+	}
+	return lipgloss.JoinVertical(lipgloss.Left,
+		lipgloss.NewStyle().Bold(true).Render(question),
+		"",
+		lipgloss.NewStyle().Foreground(warning).Render(prompt),
+		d.input.View(),
+		"",
+		lipgloss.NewStyle().Foreground(subtle).Render("(enter to confirm, esc to cancel)"),
+	)
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// fakeDynamo is a DynamoDB endpoint that fails every call. It records the
+// table each call named, so a test can run a command and check its target.
+func fakeDynamo(t *testing.T) (*AWS, *[]string) {
+	var tables []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var in struct{ TableName string }
+		json.NewDecoder(r.Body).Decode(&in)
+		tables = append(tables, in.TableName)
+		w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"__type":"com.amazonaws.dynamodb.v20120810#ValidationException","message":"fake"}`))
+	}))
+	t.Cleanup(srv.Close)
+	client := dynamodb.New(dynamodb.Options{
+		Region:           "us-east-1",
+		BaseEndpoint:     aws.String(srv.URL),
+		Credentials:      aws.AnonymousCredentials{},
+		RetryMaxAttempts: 1,
+	})
+	return &AWS{Dynamo: client}, &tables
+}
+
+// lastAudited runs cmd and returns the table its audit entry was written for.
+func lastAudited(t *testing.T, cmd tea.Cmd) string {
+	t.Helper()
+	cmd()
+	entries, err := LoadAuditLog()
+	if err != nil || len(entries) == 0 {
+		t.Fatalf("audit log: %d entries, %v", len(entries), err)
+	}
+	return entries[0].Table
+}
+
+func TestParseIndexSpecs(t *testing.T) {
+	got, err := parseIndexSpecs(" byEmail=email; byDay = day:s, ts:N ;", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []IndexSpec{
+		{Name: "byEmail", PK: "email", PKType: "S"},
+		{Name: "byDay", PK: "day", PKType: "S", SK: "ts", SKType: "N"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	local, err := parseIndexSpecs("byTs=ts:N", true)
+	if err != nil || len(local) != 1 || local[0].SK != "ts" || local[0].PK != "" {
+		t.Errorf("local = %+v, %v", local, err)
+	}
+
+	for _, bad := range []string{"byEmail", "x=a:Q", "x=a,b,c"} {
+		if _, err := parseIndexSpecs(bad, false); err == nil {
+			t.Errorf("%q: expected an error", bad)
+		}
+	}
+	if _, err := parseIndexSpecs("x=a,b", true); err == nil {
+		t.Error("local index with two keys: expected an error")
+	}
+}
+
+func TestAttributeDefinitionsRejectsTypeClash(t *testing.T) {
+	defs, err := attributeDefinitions([][2]string{{"id", "S"}, {"", ""}, {"id", "S"}, {"ts", "N"}})
+	if err != nil || len(defs) != 2 {
+		t.Errorf("defs = %v, %v", defs, err)
+	}
+	if _, err := attributeDefinitions([][2]string{{"id", "S"}, {"id", "N"}}); err == nil {
+		t.Error("expected an error for id as S and N")
+	}
+}
+
+func TestIndexFormKeepsItsTable(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	api, called := fakeDynamo(t)
+	m := initialModel(api)
+	m.tables = []Table{{Name: "orders", PK: "id"}, {Name: "users", PK: "id"}}
+	m.openSchemaForm(newIndexForm(m.tables[0]))
+	m.schemaForm.fields[ciName].input.SetValue("byEmail")
+	m.schemaForm.fields[ciPK].input.SetValue("email")
+
+	// A refresh re-sorts the list under the open form
+	m.tables[0], m.tables[1] = m.tables[1], m.tables[0]
+	cmd := m.submitSchemaForm()
+	if cmd == nil {
+		t.Fatalf("submit returned no command: %v", m.schemaForm.err)
+	}
+	if want := "Adding index byEmail to orders..."; m.statusMessage != want {
+		t.Errorf("status = %q, want %q", m.statusMessage, want)
+	}
+	if audited := lastAudited(t, cmd); audited != "orders" || !reflect.DeepEqual(*called, []string{"orders"}) {
+		t.Errorf("called %v, audited %q; want orders", *called, audited)
+	}
+
+	m.openSchemaForm(newIndexForm(Table{Name: "gone", PK: "id"}))
+	m.schemaForm.fields[ciName].input.SetValue("byEmail")
+	m.schemaForm.fields[ciPK].input.SetValue("email")
+	if cmd := m.submitSchemaForm(); cmd != nil || m.schemaForm.err == nil {
+		t.Error("adding an index to a deleted table should fail in the form")
+	}
+}
+
+func TestDropAuditsItsTable(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	api, called := fakeDynamo(t)
+	m := initialModel(api)
+	m.tables = []Table{{Name: "orders", PK: "id", GSIs: []string{"byEmail"}}, {Name: "users", PK: "id"}}
+
+	for _, indexes := range [][]string{{"byEmail"}, nil} {
+		*called = nil
+		m.tableCursor = 0
+		m.openDrop(indexes)
+		if indexes != nil {
+			m.drop.input.SetValue(indexes[0])
+		} else {
+			m.drop.input.SetValue("orders")
+		}
+		m.tableCursor = 1 // The list moved under the open prompt
+		_, cmd := m.updateDrop(tea.KeyMsg{Type: tea.KeyEnter})
+		if cmd == nil {
+			t.Fatalf("drop %v returned no command", indexes)
+		}
+		if audited := lastAudited(t, cmd); audited != "orders" || !reflect.DeepEqual(*called, []string{"orders"}) {
+			t.Errorf("drop %v: called %v, audited %q; want orders", indexes, *called, audited)
+		}
+	}
+}
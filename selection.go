@@ -0,0 +1,249 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Batch actions confirmed in viewBatchConfirmation.
+const (
+	batchDelete = "delete"
+	batchSet    = "set"
+)
+
+// selectedIndices returns the marked items plus the pending visual range, as
+// sorted indices into m.items. Only visible items count, so a filter applied
+// after marking narrows the batch instead of acting on hidden rows.
+func (m *model) selectedIndices() []int {
+	visible := m.visibleItems()
+	inRange := make(map[int]bool)
+	if m.visualAnchor >= 0 {
+		from, to := m.visiblePos(visible), -1
+		for pos, idx := range visible {
+			if idx == m.visualAnchor {
+				to = pos
+			}
+		}
+		if from >= 0 && to >= 0 {
+			for pos := min(from, to); pos <= max(from, to); pos++ {
+				inRange[visible[pos]] = true
+			}
+		}
+	}
+
+	var out []int
+	for _, idx := range visible {
+		if m.selected[idx] || inRange[idx] {
+			out = append(out, idx)
+		}
+	}
+	return out
+}
+
+// isSelected reports whether item i is part of the current selection.
+func (m *model) isSelected(i int, selection []int) bool {
+	n := sort.SearchInts(selection, i)
+	return n < len(selection) && selection[n] == i
+}
+
+// toggleSelect marks or unmarks the item under the cursor and moves down.
+func (m *model) toggleSelect() {
+	if m.selected[m.itemCursor] {
+		delete(m.selected, m.itemCursor)
+	} else {
+		m.selected[m.itemCursor] = true
+	}
+	m.moveCursor(1)
+}
+
+// toggleVisual starts a range selection at the cursor, or adds the range to
+// the marked items when one is already open.
+func (m *model) toggleVisual() {
+	if m.visualAnchor < 0 {
+		m.visualAnchor = m.itemCursor
+		return
+	}
+	for _, idx := range m.selectedIndices() {
+		m.selected[idx] = true
+	}
+	m.visualAnchor = -1
+}
+
+// selectAllFiltered marks every visible item, or unmarks them all when they
+// are already marked.
+func (m *model) selectAllFiltered() {
+	visible := m.visibleItems()
+	all := true
+	for _, idx := range visible {
+		if !m.selected[idx] {
+			all = false
+			break
+		}
+	}
+	for _, idx := range visible {
+		if all {
+			delete(m.selected, idx)
+		} else {
+			m.selected[idx] = true
+		}
+	}
+	m.visualAnchor = -1
+}
+
+func (m *model) clearSelection() {
+	m.selected = make(map[int]bool)
+	m.visualAnchor = -1
+}
+
+// removeItems drops the given indices from m.items, carrying the
+// index-keyed maps along. The cursor stays on its item, or moves to the next
+// remaining one when its item was removed.
+func (m *model) removeItems(drop []int) {
+	gone := make(map[int]bool, len(drop))
+	for _, i := range drop {
+		gone[i] = true
+	}
+	perm := make([]int, 0, len(m.items))
+	for i := range m.items {
+		if !gone[i] {
+			perm = append(perm, i)
+		}
+	}
+	if len(perm) == 0 {
+		m.permuteItems(perm)
+		m.itemCursor = 0
+		return
+	}
+
+	pos := len(perm) - 1
+	for p, i := range perm {
+		if i >= m.itemCursor {
+			pos = p
+			break
+		}
+	}
+	m.itemCursor = perm[pos]
+	m.permuteItems(perm)
+}
+
+// parseAssignment reads the "attr = value" typed for a bulk set. The value is
+// JSON when it parses as JSON (numbers, booleans, null, quoted strings, lists
+// and maps) and a plain string otherwise.
+func parseAssignment(s string) (string, interface{}, error) {
+	attr, raw, ok := strings.Cut(s, "=")
+	attr, raw = strings.TrimSpace(attr), strings.TrimSpace(raw)
+	if !ok || attr == "" {
+		return "", nil, fmt.Errorf("expected attribute = value")
+	}
+	var v interface{}
+	if err := json.Unmarshal([]byte(raw), &v); err != nil {
+		v = raw
+	}
+	return attr, v, nil
+}
+
+// openBatchSet prompts for the attribute to set on the selected items.
+func (m *model) openBatchSet() tea.Cmd {
+	m.batchInput = textinput.New()
+	m.batchInput.Placeholder = `status = "archived"`
+	m.batchInput.Prompt = "❯ "
+	m.batchInput.Width = max(20, m.width/3)
+	m.batchInput.Focus()
+	m.batchErr = nil
+	m.previousView = m.view
+	m.view = viewBatchSet
+	return textinput.Blink
+}
+
+func (m *model) updateBatchSet(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.view = viewTableItems
+		return m, nil
+	case "enter":
+		attr, v, err := parseAssignment(m.batchInput.Value())
+		if err == nil {
+			t := m.tables[m.tableCursor]
+			if attr == t.PK || attr == t.SK {
+				err = fmt.Errorf("key attribute %s can't be changed in place", attr)
+			}
+		}
+		if err != nil {
+			m.batchErr = err
+			return m, nil
+		}
+		m.batchAction = batchSet
+		m.batchSet = map[string]interface{}{attr: v}
+		m.view = viewBatchConfirmation
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.batchInput, cmd = m.batchInput.Update(msg)
+	return m, cmd
+}
+
+func (m *model) updateBatchConfirmation(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "y", "Y", "enter":
+		indices := m.selectedIndices()
+		t := m.tables[m.tableCursor]
+		keys := make([]map[string]interface{}, len(indices))
+		for i, idx := range indices {
+			keys[i] = itemKey(m.items[idx], t)
+		}
+
+		m.loading = true
+		m.view = viewLoading
+		if m.batchAction == batchDelete {
+			m.statusMessage = fmt.Sprintf("Deleting %d items...", len(indices))
+			audit := m.newAuditEntry(auditBatchDelete)
+			audit.Keys = keys
+			return m, batchDeleteCmd(m.aws, t.Name, keys, indices, audit)
+		}
+		m.statusMessage = fmt.Sprintf("Updating %d items...", len(indices))
+		audit := m.newAuditEntry(auditBatchUpdate)
+		audit.Keys = keys
+		return m, batchSetCmd(m.aws, t.Name, keys, indices, m.batchSet, audit)
+	case "n", "N", "esc":
+		m.view = viewTableItems
+	}
+	return m, nil
+}
+
+// applyBatchResult updates the loaded items after a batch finished. Items
+// the batch got through are removed or changed even when it failed part way.
+func (m *model) applyBatchResult(msg batchDoneMsg) {
+	switch msg.action {
+	case batchDelete:
+		m.removeItems(msg.done)
+		m.tables[m.tableCursor].ItemCount = max(m.tables[m.tableCursor].ItemCount-int64(len(msg.done)), 0)
+	case batchSet:
+		for _, idx := range msg.done {
+			for attr, v := range msg.set {
+				m.items[idx][attr] = v
+			}
+		}
+	}
+	m.clearSelection()
+	m.snapCursorToFilter()
+}
+
+// exportPath names the export file for table in the working directory.
+func exportPath(table string) string {
+	return fmt.Sprintf("%s-export-%s.json", table, time.Now().Format("20060102-150405"))
+}
+
+func writeExport(path string, items []Item) error {
+	b, err := json.MarshalIndent(items, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(b, '\n'), 0o644)
+}
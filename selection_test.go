@@ -0,0 +1,38 @@
+package main
+
+import "testing"
+
+func TestRemoveItemsKeepsSelection(t *testing.T) {
+	m := model{
+		tables: []Table{{Name: "t", PK: "id"}},
+		session: &session{
+			items:         []Item{{"id": "a"}, {"id": "b"}, {"id": "c"}, {"id": "d"}},
+			modifiedItems: map[int]bool{3: true},
+			newItems:      map[int]bool{},
+			editedAttrs:   map[int]map[string]bool{},
+			selected:      map[int]bool{0: true, 2: true},
+			visualAnchor:  -1,
+			itemCursor:    1,
+		},
+	}
+
+	m.removeItems([]int{1})
+
+	if m.items[m.itemCursor]["id"] != "c" {
+		t.Errorf("cursor on %v, want the next item c", m.items[m.itemCursor]["id"])
+	}
+	if got := m.selectedIndices(); len(got) != 2 || m.items[got[0]]["id"] != "a" || m.items[got[1]]["id"] != "c" {
+		t.Errorf("selection = %v", got)
+	}
+	if !m.modifiedItems[2] || m.items[2]["id"] != "d" {
+		t.Errorf("modified flag did not follow d: %v", m.modifiedItems)
+	}
+
+	// A visual range runs from the anchor to the cursor
+	m.clearSelection()
+	m.visualAnchor = 0
+	m.itemCursor = 2
+	if got := m.selectedIndices(); len(got) != 3 {
+		t.Errorf("visual range = %v, want 3 items", got)
+	}
+}
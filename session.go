@@ -0,0 +1,175 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// session is one tab: a table (or query result) with its loaded items and all
+// of the browsing state that goes with them. model embeds the active session,
+// so m.items and friends always refer to the tab on screen.
+type session struct {
+	table            string // Table the items came from; empty while picking a table
+	items            []Item
+	itemCursor       int
+	modifiedItems    map[int]bool
+	newItems         map[int]bool            // Tracks items that were newly created but not yet saved/synced
+	editedAttrs      map[int]map[string]bool // Attributes changed inline in the grid, saved as a targeted update
+	selected         map[int]bool            // Items marked for a batch action
+	visualAnchor     int                     // Where the open visual range started; -1 when none
+	activePane       int
+	isCustomQuery    bool
+	query            []string // Statements behind a query result, re-run by auto-refresh
+	lastEvaluatedKey map[string]types.AttributeValue
+	pages            int             // Scan pages loaded, re-fetched by auto-refresh
+	itemFilter       textinput.Model // Client-side filter over the loaded items
+	filtering        bool            // Filter input has focus
+	filterAllAttrs   bool            // Match any attribute, not just key values
+	columnOffset     int             // Horizontal scroll of the item list columns
+	sortAttr         string          // Attribute the loaded items are sorted by; empty keeps scan order
+	sortDesc         bool
+	gridMode         bool            // Full-width spreadsheet grid instead of the list + inspector
+	gridCol          int             // Column of the cell cursor in the grid
+	gridOffset       int             // Horizontal scroll of the grid's unfrozen columns
+	cellEdit         *cellEditor     // Inline editor on the grid cell under the cursor; nil when not editing
+	inspectorCursor  int             // Row of the tree inspector under the cursor
+	inspectorOffset  int             // Inspector scroll position, restored when switching back to the tab
+	collapsed        map[string]bool // Document paths collapsed in the tree inspector
+	stream           *streamTail     // Live tail of the table's stream; nil when not tailing
+	refreshEvery     time.Duration   // Auto-refresh interval; zero when off
+	refreshRCU       float64         // Estimated read capacity auto-refresh uses per minute
+	refreshGen       int             // Bumped to end the running refresh loop
+	changes          map[int]byte    // Rows the last refresh added, changed or removed
+	changesGen       int             // Bumped by every refresh so only the latest marks expire
+
+	conversation    []conversationTurn // Earlier / questions on this table, sent with follow-ups
+	awaitingOutcome bool               // The last turn's result hasn't come back yet
+	lastQuestion    string             // Last natural-language question sent to Bedrock (for the audit log)
+	llmResult       LLMResult
+	llmEdited       bool // llmResult was edited by hand before running
+}
+
+func newSession() *session {
+	fi := textinput.New()
+	fi.Placeholder = "Filter loaded items..."
+	fi.Prompt = "filter: "
+	fi.CharLimit = 156
+
+	return &session{
+		items:         []Item{},
+		modifiedItems: make(map[int]bool),
+		newItems:      make(map[int]bool),
+		editedAttrs:   make(map[int]map[string]bool),
+		selected:      make(map[int]bool),
+		visualAnchor:  -1,
+		itemFilter:    fi,
+		collapsed:     make(map[string]bool),
+		changes:       make(map[int]byte),
+	}
+}
+
+// label is the tab title.
+func (s *session) label() string {
+	if s.table == "" {
+		return "tables"
+	}
+	if s.isCustomQuery {
+		return s.table + " · query"
+	}
+	return s.table
+}
+
+// hasUnsaved reports whether the tab holds edits that were never saved.
+func (s *session) hasUnsaved() bool {
+	return len(s.modifiedItems) > 0
+}
+
+// discardEdits forgets the unsaved edits of the tab, for when its items are
+// dropped or reloaded.
+func (s *session) discardEdits() {
+	s.modifiedItems = make(map[int]bool)
+	s.newItems = make(map[int]bool)
+	s.editedAttrs = make(map[int]map[string]bool)
+}
+
+// switchSession makes tab i the active one and restores its view.
+func (m *model) switchSession(i int) {
+	m.inspectorOffset = m.viewport.YOffset
+	m.activeSession = i
+	m.session = m.sessions[i]
+	m.copyPending = false
+
+	m.view = viewTableList
+	if m.table != "" {
+		for ti, t := range m.tables {
+			if t.Name == m.table {
+				m.tableCursor = ti
+				m.view = viewTableItems
+			}
+		}
+	}
+	if m.view == viewTableItems {
+		m.updateViewport()
+		m.viewport.SetYOffset(m.inspectorOffset)
+	}
+}
+
+// hasSession reports whether s is still one of the open tabs.
+func (m *model) hasSession(s *session) bool {
+	for _, open := range m.sessions {
+		if open == s {
+			return true
+		}
+	}
+	return false
+}
+
+// openSession adds an empty tab after the current one and switches to it so a
+// table can be picked for it.
+func (m *model) openSession() {
+	i := m.activeSession + 1
+	m.sessions = append(m.sessions[:i], append([]*session{newSession()}, m.sessions[i:]...)...)
+	m.switchSession(i)
+}
+
+// closeSession closes the active tab. The last tab can't be closed.
+func (m *model) closeSession() {
+	if len(m.sessions) < 2 {
+		return
+	}
+	i := m.activeSession
+	m.sessions = append(m.sessions[:i], m.sessions[i+1:]...)
+	m.switchSession(min(i, len(m.sessions)-1))
+}
+
+// renderTabs draws the tab bar, or a blank spacer line with a single tab so
+// the layout below does not move.
+func (m model) renderTabs() string {
+	if len(m.sessions) < 2 {
+		return "\n"
+	}
+	active := lipgloss.NewStyle().Foreground(textLight).Background(primary).Bold(true).Padding(0, 1)
+	inactive := lipgloss.NewStyle().Foreground(textDim).Padding(0, 1)
+
+	tabs := make([]string, len(m.sessions))
+	for i, s := range m.sessions {
+		label := fmt.Sprintf("%d %s", i+1, s.label())
+		if s.hasUnsaved() {
+			label += " *"
+		}
+		if s.stream != nil && s.stream.err == nil {
+			label += " ●"
+		}
+		if i == m.activeSession {
+			tabs[i] = active.Render(label)
+		} else {
+			tabs[i] = inactive.Render(label)
+		}
+	}
+	return lipgloss.NewStyle().MaxWidth(m.width).Render(strings.Join(tabs, " ")) + "\n"
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// rowsOf returns the "id" of every row in a map keyed by item index.
+func rowsOf(items []Item, set map[int]bool) map[string]bool {
+	out := make(map[string]bool, len(set))
+	for i := range set {
+		out[items[i]["id"].(string)] = true
+	}
+	return out
+}
+
+func TestSortKeepsSessionMapsOnTheirRows(t *testing.T) {
+	s := newSession()
+	s.table = "orders"
+	s.items = []Item{{"id": "c", "n": 3.0}, {"id": "a", "n": 1.0}, {"id": "b", "n": 2.0}, {"id": "d", "n": 0.0}}
+	s.modifiedItems[0] = true
+	s.editedAttrs[0] = map[string]bool{"n": true}
+	s.selected[0], s.selected[2] = true, true
+	s.changes[1] = changeAdded
+	s.changes[2] = changeChanged
+	s.visualAnchor = 2
+	s.itemCursor = 0
+	m := model{tables: []Table{{Name: "orders", PK: "id"}}, sessions: []*session{s}, session: s}
+
+	m.sortAttr = "n"
+	m.sortItems()
+
+	order := ""
+	for _, item := range s.items {
+		order += item["id"].(string)
+	}
+	if order != "dabc" {
+		t.Fatalf("order = %s, want dabc", order)
+	}
+	if got := rowsOf(s.items, s.modifiedItems); !got["c"] || len(got) != 1 {
+		t.Errorf("modifiedItems on %v, want c", got)
+	}
+	if s.editedAttrs[3] == nil || !s.editedAttrs[3]["n"] || len(s.editedAttrs) != 1 {
+		t.Errorf("editedAttrs = %v, want n on c (3)", s.editedAttrs)
+	}
+	if got := rowsOf(s.items, s.selected); !got["c"] || !got["b"] || len(got) != 2 {
+		t.Errorf("selected on %v, want b and c", got)
+	}
+	if s.changes[1] != changeAdded || s.changes[2] != changeChanged || len(s.changes) != 2 {
+		t.Errorf("changes = %v, want a (1) added and b (2) changed", s.changes)
+	}
+	if s.items[s.visualAnchor]["id"] != "b" {
+		t.Errorf("visual anchor on %v, want b", s.items[s.visualAnchor]["id"])
+	}
+	if s.items[s.itemCursor]["id"] != "c" {
+		t.Errorf("cursor on %v, want c", s.items[s.itemCursor]["id"])
+	}
+
+	// Removing rows shifts every map with them
+	m.removeItems([]int{0, 1})
+	if got := rowsOf(s.items, s.selected); !got["c"] || !got["b"] || len(got) != 2 {
+		t.Errorf("selected after removing d and a on %v, want b and c", got)
+	}
+	if s.changes[0] != changeChanged || len(s.changes) != 1 {
+		t.Errorf("changes after removing a = %v, want b (0) changed", s.changes)
+	}
+}
+
+func TestSwitchingSessionsKeepsEachTabsState(t *testing.T) {
+	m := model{tables: []Table{{Name: "orders", PK: "id"}, {Name: "users", PK: "id"}}}
+	orders := newSession()
+	orders.table = "orders"
+	orders.items = []Item{{"id": "o1"}, {"id": "o2"}}
+	orders.selected[1] = true
+	orders.modifiedItems[0] = true
+	orders.itemFilter.SetValue("o2")
+	orders.lastQuestion = "orders over $100"
+	orders.llmEdited = true
+	m.sessions = []*session{orders}
+	m.session = orders
+
+	m.openSession()
+	if m.activeSession != 1 || m.view != viewTableList {
+		t.Fatalf("new tab: active %d view %v, want 1 on the table list", m.activeSession, m.view)
+	}
+	users := m.session
+	users.table = "users"
+	users.items = []Item{{"id": "u1"}, {"id": "u2"}, {"id": "u3"}}
+	m.tableCursor = 1
+	m.sortAttr, m.sortDesc = "id", true
+	m.sortItems()
+	users.selected[0] = true
+	users.lastQuestion = "users who signed up today"
+
+	m.switchSession(0)
+	if e := m.newAuditEntry(auditStatement); e.Question != "orders over $100" || !e.Edited {
+		t.Errorf("orders audit question %q edited %v, want its own", e.Question, e.Edited)
+	}
+	if m.tableCursor != 0 || m.view != viewTableItems {
+		t.Errorf("back on orders: table %d view %v, want 0 and the item view", m.tableCursor, m.view)
+	}
+	if got := rowsOf(m.items, m.selected); !got["o2"] || len(got) != 1 {
+		t.Errorf("orders selection on %v, want o2", got)
+	}
+	if got := rowsOf(m.items, m.modifiedItems); !got["o1"] || len(got) != 1 {
+		t.Errorf("orders modified on %v, want o1", got)
+	}
+	if m.itemFilter.Value() != "o2" || m.sortAttr != "" {
+		t.Errorf("orders filter %q sort %q, want o2 and unsorted", m.itemFilter.Value(), m.sortAttr)
+	}
+
+	m.switchSession(1)
+	if m.items[0]["id"] != "u3" {
+		t.Errorf("users order starts with %v, want u3", m.items[0]["id"])
+	}
+	if got := rowsOf(m.items, m.selected); !got["u3"] || len(got) != 1 {
+		t.Errorf("users selection on %v, want u3", got)
+	}
+
+	m.closeSession()
+	if len(m.sessions) != 1 || m.session != orders {
+		t.Errorf("after closing users: %d tabs, active is orders %v", len(m.sessions), m.session == orders)
+	}
+}
+
+func TestLeavingTheItemsDropsTheirEdits(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	m := initialModel(nil)
+	m.tables = []Table{{Name: "orders", PK: "id"}}
+	m.openSession()
+	m.table = "orders"
+	m.items = []Item{{"id": "o1"}, {"id": "o2"}}
+	m.modifiedItems[0], m.newItems[1] = true, true
+	m.editedAttrs[0] = map[string]bool{"n": true}
+	m.view = viewTableItems
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+	if m.view != viewTableList || m.hasUnsaved() || len(m.newItems) != 0 || len(m.editedAttrs) != 0 {
+		t.Fatalf("after q: view %v, modified %v, new %v, edited %v", m.view, m.modifiedItems, m.newItems, m.editedAttrs)
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyCtrlW})
+	if len(m.sessions) != 1 {
+		t.Errorf("ctrl+w left %d tabs, want the emptied one closed", len(m.sessions))
+	}
+}
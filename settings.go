@@ -0,0 +1,175 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Settings names, as recorded in the audit log.
+const (
+	settingTTL                = "ttl"
+	settingStream             = "stream"
+	settingPITR               = "pitr"
+	settingDeletionProtection = "deletion_protection"
+	settingTags               = "tags"
+)
+
+var streamViewTypes = []string{"Off", "KEYS_ONLY", "NEW_IMAGE", "OLD_IMAGE", "NEW_AND_OLD_IMAGES"}
+
+// Fields of the settings form
+const (
+	stTTL = iota
+	stStream
+	stPITR
+	stDeletionProtection
+	stTags
+)
+
+// parseTags reads "key=value, key2=value2". Values may be empty.
+func parseTags(s string) (map[string]string, error) {
+	tags := make(map[string]string)
+	for _, part := range strings.Split(s, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		k, v, _ := strings.Cut(part, "=")
+		k = strings.TrimSpace(k)
+		if k == "" {
+			return nil, fmt.Errorf("tag %q has no key", strings.TrimSpace(part))
+		}
+		tags[k] = strings.TrimSpace(v)
+	}
+	return tags, nil
+}
+
+// formatTags is the inverse of parseTags, sorted by key.
+func formatTags(tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = k + "=" + tags[k]
+	}
+	return strings.Join(parts, ", ")
+}
+
+func onOff(on bool) int {
+	if on {
+		return 1
+	}
+	return 0
+}
+
+func newSettingsForm(table string, s TableSettings) *schemaForm {
+	stream := choiceField("Stream", streamViewTypes...)
+	for i, v := range streamViewTypes {
+		if v == s.StreamViewType {
+			stream.choice = i
+		}
+	}
+	pitr := choiceField("Point-in-time recovery", "Off", "On")
+	pitr.choice = onOff(s.PITR)
+	protection := choiceField("Deletion protection", "Off", "On")
+	protection.choice = onOff(s.DeletionProtection)
+
+	ttlState := "TTL off"
+	if s.TTLAttribute != "" {
+		ttlState = fmt.Sprintf("TTL %s on %s", s.TTLStatus, s.TTLAttribute)
+	}
+	loaded := s
+	return &schemaForm{
+		kind:     formSettings,
+		table:    table,
+		title:    "Settings: " + table,
+		submit:   "save",
+		info:     []string{s.ARN, ttlState},
+		settings: &loaded,
+		fields: []formField{
+			textField("TTL attribute", "empty turns TTL off", s.TTLAttribute),
+			stream,
+			pitr,
+			protection,
+			textField("Tags", "key=value, ...", formatTags(s.Tags)),
+		},
+	}
+}
+
+// tableSettings reads the settings form back into TableSettings.
+func (f *schemaForm) tableSettings() (TableSettings, error) {
+	s := *f.settings
+	s.TTLAttribute = f.fields[stTTL].value()
+	s.StreamViewType = f.fields[stStream].value()
+	if s.StreamViewType == "Off" {
+		s.StreamViewType = ""
+	}
+	s.PITR = f.fields[stPITR].value() == "On"
+	s.DeletionProtection = f.fields[stDeletionProtection].value() == "On"
+	tags, err := parseTags(f.fields[stTags].value())
+	if err != nil {
+		return s, err
+	}
+	s.Tags = tags
+	return s, nil
+}
+
+// changedSettings names the settings that differ between old and s.
+func changedSettings(old, s TableSettings) []string {
+	var out []string
+	if s.DeletionProtection != old.DeletionProtection {
+		out = append(out, settingDeletionProtection)
+	}
+	if s.TTLAttribute != old.TTLAttribute {
+		out = append(out, settingTTL)
+	}
+	if s.PITR != old.PITR {
+		out = append(out, settingPITR)
+	}
+	if formatTags(s.Tags) != formatTags(old.Tags) {
+		out = append(out, settingTags)
+	}
+	if s.StreamViewType != old.StreamViewType {
+		out = append(out, settingStream)
+	}
+	return out
+}
+
+// openSettings loads the settings of the table under the cursor; the form
+// opens once they arrive.
+func (m *model) openSettings() tea.Cmd {
+	t := m.tables[m.tableCursor]
+	m.loading = true
+	m.previousView = m.view
+	m.view = viewLoading
+	m.statusMessage = fmt.Sprintf("Loading settings of %s...", t.Name)
+	return loadSettingsCmd(m.aws, t.Name)
+}
+
+// submitSettings saves the settings that changed, or just closes the form
+// when nothing did.
+func (m *model) submitSettings() tea.Cmd {
+	f := m.schemaForm
+	s, err := f.tableSettings()
+	if err != nil {
+		f.err = err
+		return nil
+	}
+	changed := changedSettings(*f.settings, s)
+	if len(changed) == 0 {
+		m.schemaForm = nil
+		m.view = viewTableList
+		m.notice = "No settings changed"
+		return nil
+	}
+
+	audit := m.newAuditEntry(auditUpdateSettings)
+	audit.Table = f.table
+	m.statusMessage = fmt.Sprintf("Updating %s of %s...", strings.Join(changed, ", "), f.table)
+	m.schemaForm, m.loading, m.view = nil, true, viewLoading
+	return saveSettingsCmd(m.aws, f.table, *f.settings, s, audit)
+}
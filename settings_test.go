@@ -0,0 +1,69 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTags(t *testing.T) {
+	tags, err := parseTags(" env = prod, team=data,empty=, ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"env": "prod", "team": "data", "empty": ""}
+	if !reflect.DeepEqual(tags, want) {
+		t.Errorf("tags = %v, want %v", tags, want)
+	}
+	if got := formatTags(tags); got != "empty=, env=prod, team=data" {
+		t.Errorf("formatTags = %q", got)
+	}
+	if _, err := parseTags("=x"); err == nil {
+		t.Error("expected an error for a tag without a key")
+	}
+}
+
+func TestSettingsFormRoundTrip(t *testing.T) {
+	loaded := TableSettings{TTLAttribute: "expiresAt", TTLStatus: "ENABLED", StreamViewType: "NEW_IMAGE", Tags: map[string]string{"env": "prod"}}
+	f := newSettingsForm("users", loaded)
+
+	s, err := f.tableSettings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed := changedSettings(loaded, s); len(changed) != 0 {
+		t.Errorf("untouched form changed %v", changed)
+	}
+
+	f.fields[stStream].choice = 0 // Off
+	f.fields[stDeletionProtection].choice = 1
+	f.fields[stTags].input.SetValue("env=dev")
+	s, _ = f.tableSettings()
+	want := []string{settingDeletionProtection, settingTags, settingStream}
+	if changed := changedSettings(loaded, s); !reflect.DeepEqual(changed, want) {
+		t.Errorf("changed = %v, want %v", changed, want)
+	}
+	if s.StreamViewType != "" || !s.DeletionProtection {
+		t.Errorf("settings = %+v", s)
+	}
+}
+
+func TestSettingsFormKeepsItsTable(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	api, called := fakeDynamo(t)
+	m := initialModel(api)
+	m.tables = []Table{{Name: "users"}, {Name: "orders"}}
+	m.openSchemaForm(newSettingsForm("users", TableSettings{}))
+	m.schemaForm.fields[stDeletionProtection].choice = 1
+
+	m.tableCursor = 1 // The list moved under the open form
+	cmd := m.submitSchemaForm()
+	if cmd == nil {
+		t.Fatalf("submit returned no command: %v", m.schemaForm.err)
+	}
+	if want := "Updating " + settingDeletionProtection + " of users..."; m.statusMessage != want {
+		t.Errorf("status = %q, want %q", m.statusMessage, want)
+	}
+	if audited := lastAudited(t, cmd); audited != "users" || !reflect.DeepEqual(*called, []string{"users"}) {
+		t.Errorf("called %v, audited %q; want users", *called, audited)
+	}
+}
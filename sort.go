@@ -0,0 +1,184 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Type ranks used when two items hold different types in the sort attribute.
+// Missing attributes are handled separately and always sort last.
+const (
+	rankBool = iota
+	rankNumber
+	rankString
+	rankOther
+)
+
+func sortRank(v interface{}) int {
+	switch v.(type) {
+	case bool:
+		return rankBool
+	case float64, float32, int, int32, int64:
+		return rankNumber
+	case string:
+		return rankString
+	default:
+		return rankOther
+	}
+}
+
+func toFloat(v interface{}) float64 {
+	switch n := v.(type) {
+	case float64:
+		return n
+	case float32:
+		return float64(n)
+	case int:
+		return float64(n)
+	case int32:
+		return float64(n)
+	case int64:
+		return float64(n)
+	}
+	return 0
+}
+
+// compareValues orders two present attribute values: numbers numerically,
+// strings lexically, false before true, and mixed types by type rank.
+// Lists, maps and sets fall back to comparing their printed form.
+func compareValues(a, b interface{}) int {
+	ra, rb := sortRank(a), sortRank(b)
+	if ra != rb {
+		return ra - rb
+	}
+	switch ra {
+	case rankBool:
+		ab, bb := a.(bool), b.(bool)
+		switch {
+		case ab == bb:
+			return 0
+		case !ab:
+			return -1
+		default:
+			return 1
+		}
+	case rankNumber:
+		fa, fb := toFloat(a), toFloat(b)
+		switch {
+		case fa < fb:
+			return -1
+		case fa > fb:
+			return 1
+		}
+		return 0
+	case rankString:
+		return strings.Compare(a.(string), b.(string))
+	}
+	return strings.Compare(fmt.Sprintf("%v", a), fmt.Sprintf("%v", b))
+}
+
+func sortArrow(desc bool) string {
+	if desc {
+		return " ↓"
+	}
+	return " ↑"
+}
+
+// sortItems orders m.items by m.sortAttr. Items missing the attribute (or
+// holding NULL) stay at the bottom in either direction, and the sort is
+// stable so equal values keep their scan order.
+func (m *model) sortItems() {
+	if m.sortAttr == "" || len(m.items) < 2 {
+		return
+	}
+	perm := make([]int, len(m.items))
+	for i := range perm {
+		perm[i] = i
+	}
+	attr, desc := m.sortAttr, m.sortDesc
+	sort.SliceStable(perm, func(i, j int) bool {
+		a, b := m.items[perm[i]][attr], m.items[perm[j]][attr]
+		if a == nil || b == nil {
+			return a != nil && b == nil
+		}
+		c := compareValues(a, b)
+		if desc {
+			return c > 0
+		}
+		return c < 0
+	})
+	m.permuteItems(perm)
+}
+
+// permuteItems reorders m.items so that new index i holds the item that was at
+// perm[i], carrying the cursor and every index-keyed map along with it.
+func (m *model) permuteItems(perm []int) {
+	newPos := make(map[int]int, len(perm))
+	items := make([]Item, len(perm))
+	for dst, src := range perm {
+		items[dst] = m.items[src]
+		newPos[src] = dst
+	}
+
+	remap := func(in map[int]bool) map[int]bool {
+		out := make(map[int]bool, len(in))
+		for k, v := range in {
+			if dst, ok := newPos[k]; ok {
+				out[dst] = v
+			}
+		}
+		return out
+	}
+
+	edited := make(map[int]map[string]bool, len(m.editedAttrs))
+	for k, v := range m.editedAttrs {
+		if dst, ok := newPos[k]; ok {
+			edited[dst] = v
+		}
+	}
+
+	m.items = items
+	m.modifiedItems = remap(m.modifiedItems)
+	m.newItems = remap(m.newItems)
+	m.editedAttrs = edited
+	m.selected = remap(m.selected)
+	changes := make(map[int]byte, len(m.changes))
+	for k, v := range m.changes {
+		if dst, ok := newPos[k]; ok {
+			changes[dst] = v
+		}
+	}
+	m.changes = changes
+	if dst, ok := newPos[m.visualAnchor]; ok {
+		m.visualAnchor = dst
+	} else {
+		m.visualAnchor = -1
+	}
+	if dst, ok := newPos[m.itemCursor]; ok {
+		m.itemCursor = dst
+	}
+}
+
+// loadedAttributes lists every attribute seen in the loaded items, keys first
+// and the rest by name.
+func (m *model) loadedAttributes() []string {
+	t := m.tables[m.tableCursor]
+	seen := map[string]bool{t.PK: true}
+	attrs := []string{t.PK}
+	if t.SK != "" {
+		attrs = append(attrs, t.SK)
+		seen[t.SK] = true
+	}
+	var rest []string
+	for _, item := range m.items {
+		for k := range item {
+			if !seen[k] {
+				seen[k] = true
+				rest = append(rest, k)
+			}
+		}
+	}
+	sort.Strings(rest)
+	return append(attrs, rest...)
+}
@@ -0,0 +1,51 @@
+package main
+
+import "testing"
+
+func TestSortItemsKeepsIndexMaps(t *testing.T) {
+	m := model{
+		tables: []Table{{Name: "t", PK: "id"}},
+		session: &session{
+			items: []Item{
+				{"id": "a", "n": 3.0},
+				{"id": "b"},
+				{"id": "c", "n": 1.0},
+				{"id": "d", "n": "x"},
+				{"id": "e", "n": true},
+			},
+			modifiedItems: map[int]bool{2: true},
+			newItems:      map[int]bool{1: true},
+			itemCursor:    0,
+		},
+	}
+
+	m.sortAttr = "n"
+	m.sortItems()
+
+	// booleans < numbers < strings, missing values last
+	want := []string{"e", "c", "a", "d", "b"}
+	for i, id := range want {
+		if got := m.items[i]["id"]; got != id {
+			t.Fatalf("position %d = %v, want %s (order %v)", i, got, id, m.items)
+		}
+	}
+	if m.items[m.itemCursor]["id"] != "a" {
+		t.Errorf("cursor moved to %v, want a", m.items[m.itemCursor]["id"])
+	}
+	if !m.modifiedItems[1] || len(m.modifiedItems) != 1 {
+		t.Errorf("modifiedItems = %v, want {1}", m.modifiedItems)
+	}
+	if !m.newItems[4] || len(m.newItems) != 1 {
+		t.Errorf("newItems = %v, want {4}", m.newItems)
+	}
+
+	// Descending keeps missing values at the bottom
+	m.sortDesc = true
+	m.sortItems()
+	want = []string{"d", "a", "c", "e", "b"}
+	for i, id := range want {
+		if got := m.items[i]["id"]; got != id {
+			t.Fatalf("desc position %d = %v, want %s", i, got, id)
+		}
+	}
+}
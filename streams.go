@@ -0,0 +1,423 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// The tail keeps this many records, newest first.
+const maxStreamRecords = 500
+
+// What happened to the loaded items when a stream record arrived
+const (
+	streamApplied    = "applied"
+	streamInserted   = "inserted"
+	streamRemoved    = "removed"
+	streamNotLoaded  = "not loaded"
+	streamKeptEdits  = "kept local edits"
+	streamNoNewImage = "no new image"
+	streamOtherTable = "table gone"
+)
+
+// tailRecord is a stream record with what applying it did.
+type tailRecord struct {
+	StreamRecord
+	outcome string
+}
+
+// streamTail follows the stream of a session's table. It keeps running while
+// the tab shows its items, so other people's writes show up in the list.
+type streamTail struct {
+	pos       StreamPosition
+	records   []tailRecord
+	cursor    int
+	filter    textinput.Model // Filters the records by key
+	filtering bool
+	applied   int
+	pending   []StreamRecord // Read while the tab was busy; applied once it is idle
+	err       error
+}
+
+func newStreamTail() *streamTail {
+	fi := textinput.New()
+	fi.Placeholder = "Filter by key..."
+	fi.Prompt = "filter: "
+	fi.CharLimit = 156
+	return &streamTail{filter: fi}
+}
+
+// diffLine is one attribute of a record's old / new image comparison.
+type diffLine struct {
+	attr string
+	op   byte // '+' added, '-' removed, '~' changed, ' ' unchanged
+	old  interface{}
+	new  interface{}
+}
+
+// imageDiff compares the old and new image of a record attribute by
+// attribute, in name order.
+func imageDiff(old, new map[string]interface{}) []diffLine {
+	attrs := make(map[string]bool, len(old)+len(new))
+	for k := range old {
+		attrs[k] = true
+	}
+	for k := range new {
+		attrs[k] = true
+	}
+	names := make([]string, 0, len(attrs))
+	for k := range attrs {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	lines := make([]diffLine, 0, len(names))
+	for _, k := range names {
+		o, inOld := old[k]
+		n, inNew := new[k]
+		d := diffLine{attr: k, old: o, new: n, op: ' '}
+		switch {
+		case !inOld:
+			d.op = '+'
+		case !inNew:
+			d.op = '-'
+		case !reflect.DeepEqual(o, n):
+			d.op = '~'
+		}
+		lines = append(lines, d)
+	}
+	return lines
+}
+
+// streamKeyText renders the key of a record as "pk / sk".
+func streamKeyText(keys map[string]interface{}, t Table) string {
+	text := cellText(keys[t.PK])
+	if t.SK != "" {
+		text += " / " + cellText(keys[t.SK])
+	}
+	return text
+}
+
+// findItem returns the index of the loaded item with the given key, or -1.
+func (m *model) findItem(keys map[string]interface{}, t Table) int {
+	for i, item := range m.items {
+		if reflect.DeepEqual(item[t.PK], keys[t.PK]) && (t.SK == "" || reflect.DeepEqual(item[t.SK], keys[t.SK])) {
+			return i
+		}
+	}
+	return -1
+}
+
+// applyStreamRecord folds one change into the loaded items of the active
+// session. Items with unsaved local edits are left alone, and a query result
+// only takes changes to items it already holds.
+func (m *model) applyStreamRecord(r StreamRecord, t Table) string {
+	i := m.findItem(r.Keys, t)
+	if i >= 0 && m.modifiedItems[i] {
+		return streamKeptEdits
+	}
+	if r.Type == "REMOVE" {
+		if i < 0 {
+			return streamNotLoaded
+		}
+		m.removeItems([]int{i})
+		return streamRemoved
+	}
+	if r.NewImage == nil {
+		return streamNoNewImage
+	}
+	if i >= 0 {
+		m.items[i] = Item(r.NewImage)
+		return streamApplied
+	}
+	if m.isCustomQuery {
+		return streamNotLoaded
+	}
+	m.items = append(m.items, Item(r.NewImage))
+	return streamInserted
+}
+
+// applyStreamRecords adds newly read records to the tail of s and applies
+// them to its items.
+func (m *model) applyStreamRecords(s *session, records []StreamRecord) {
+	active := m.session
+	m.session = s
+	defer func() { m.session = active }()
+
+	tail := s.stream
+	ti := m.tableIndex(s.table)
+	changed := false
+	added := make([]tailRecord, 0, len(records))
+	for _, r := range records {
+		rec := tailRecord{StreamRecord: r, outcome: streamOtherTable}
+		if ti >= 0 {
+			rec.outcome = m.applyStreamRecord(r, m.tables[ti])
+		}
+		switch rec.outcome {
+		case streamApplied, streamInserted, streamRemoved:
+			tail.applied++
+			changed = true
+		}
+		added = append(added, rec)
+	}
+
+	// Newest first; a cursor that isn't on the newest record stays on its record
+	for i, j := 0, len(added)-1; i < j; i, j = i+1, j-1 {
+		added[i], added[j] = added[j], added[i]
+	}
+	if tail.cursor > 0 {
+		tail.cursor += len(added)
+	}
+	tail.records = append(added, tail.records...)
+	if len(tail.records) > maxStreamRecords {
+		tail.records = tail.records[:maxStreamRecords]
+	}
+	tail.cursor = max(min(tail.cursor, len(tail.records)-1), 0)
+
+	if changed && s.sortAttr != "" {
+		m.sortItems()
+	}
+	if changed && active == s {
+		m.updateViewport()
+	}
+}
+
+// startStream starts tailing the stream of the active session's table, or
+// shows the tail when it is already running.
+func (m *model) startStream() tea.Cmd {
+	m.view = viewStream
+	if m.stream != nil && m.stream.err == nil {
+		return nil
+	}
+	m.stream = newStreamTail()
+	return readStreamCmd(m.aws, m.table, m.stream, StreamPosition{}, false)
+}
+
+// visibleRecords returns the indices of the tail records matching the key
+// filter.
+func (m *model) visibleRecords() []int {
+	tail := m.stream
+	var t Table
+	if ti := m.tableIndex(m.table); ti >= 0 {
+		t = m.tables[ti]
+	}
+	var out []int
+	for i, r := range tail.records {
+		if ok, _ := matchItem(Item(r.Keys), t, tail.filter.Value(), false); ok {
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
+// moveRecordCursor moves the record cursor by delta through the records that
+// pass the filter.
+func (m *model) moveRecordCursor(delta int) {
+	visible := m.visibleRecords()
+	if len(visible) == 0 {
+		return
+	}
+	pos := 0
+	for p, i := range visible {
+		if i >= m.stream.cursor {
+			pos = p
+			break
+		}
+	}
+	m.stream.cursor = visible[max(0, min(pos+delta, len(visible)-1))]
+}
+
+func (m *model) updateStream(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	tail := m.stream
+	if tail.filtering {
+		switch msg.String() {
+		case "enter":
+			tail.filtering = false
+			tail.filter.Blur()
+		case "esc":
+			tail.filtering = false
+			tail.filter.Blur()
+			tail.filter.SetValue("")
+		default:
+			var cmd tea.Cmd
+			tail.filter, cmd = tail.filter.Update(msg)
+			m.moveRecordCursor(0)
+			return m, cmd
+		}
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "q":
+		m.view = viewTableItems
+	case "esc":
+		if tail.filter.Value() != "" {
+			tail.filter.SetValue("")
+		} else {
+			m.view = viewTableItems
+		}
+	case "f", "/":
+		tail.filtering = true
+		tail.filter.Focus()
+		return m, textinput.Blink
+	case "up", "k":
+		m.moveRecordCursor(-1)
+	case "down", "j":
+		m.moveRecordCursor(1)
+	case "g", "home":
+		tail.cursor = 0
+		m.moveRecordCursor(0)
+	case "c":
+		tail.records = nil
+		tail.cursor = 0
+	case "r":
+		if tail.err != nil {
+			return m, m.startStream()
+		}
+	case "x":
+		m.stream = nil
+		m.view = viewTableItems
+		m.notice = "Stopped tailing the stream of " + m.table
+	}
+	return m, nil
+}
+
+// diffValue renders a value on one line for the diff.
+func diffValue(v interface{}) string {
+	switch v.(type) {
+	case string, float64, bool, nil:
+		return cellText(v)
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+// renderStream draws the tail: the records on top, newest first, and the
+// image diff of the selected record below.
+func (m model) renderStream() string {
+	tail := m.stream
+	header := m.renderHeader("Stream: " + m.table)
+	dim := lipgloss.NewStyle().Foreground(textDim)
+	var t Table
+	if ti := m.tableIndex(m.table); ti >= 0 {
+		t = m.tables[ti]
+	}
+
+	state := lipgloss.NewStyle().Foreground(secondary).Render("● live")
+	if tail.err != nil {
+		state = lipgloss.NewStyle().Foreground(alert).Render("● stopped: " + tail.err.Error())
+	} else if !tail.pos.Started {
+		state = dim.Render("○ connecting...")
+	}
+	status := fmt.Sprintf("%s  %s · %d records · %d applied to the loaded items", state,
+		dim.Render(tail.pos.ViewType), len(tail.records), tail.applied)
+	if tail.filtering {
+		status = tail.filter.View()
+	} else if tail.filter.Value() != "" {
+		status += dim.Render("  filter: " + tail.filter.Value())
+	}
+
+	visible := m.visibleRecords()
+	row := func(when, typ, key, outcome string) string {
+		return fmt.Sprintf("%-8s  %-6s  %-40s  %s", when, typ, truncateText(key, 40), outcome)
+	}
+	lines := []string{
+		status,
+		"",
+		listHeaderStyle.Width(m.width - 4).Render(row("TIME", "EVENT", "KEY", "ITEMS")),
+	}
+	if len(visible) == 0 {
+		msg := "Waiting for changes..."
+		if len(tail.records) > 0 {
+			msg = "No records match the filter."
+		}
+		lines = append(lines, itemRowStyle.Render(dim.Render(msg)))
+	}
+
+	listHeight := max((m.height-10)/2, 1)
+	cursorPos := 0
+	for p, i := range visible {
+		if i == tail.cursor {
+			cursorPos = p
+		}
+	}
+	start, end := windowRange(len(visible), cursorPos, listHeight)
+	for _, i := range visible[start:end] {
+		r := tail.records[i]
+		line := row(r.Time.Local().Format("15:04:05"), r.Type, streamKeyText(r.Keys, t), r.outcome)
+		if i == tail.cursor {
+			lines = append(lines, listSelectedStyle.Width(m.width-4).Render(line))
+		} else {
+			lines = append(lines, listItemStyle.Render(line))
+		}
+	}
+
+	lines = append(lines, "")
+	if tail.cursor < len(tail.records) && len(visible) > 0 {
+		lines = append(lines, m.renderRecordDiff(tail.records[tail.cursor], max(m.height-listHeight-12, 1))...)
+	}
+	hint := "f filter by key · c clear · x stop tailing · esc back to the items (the tail keeps running)"
+	if tail.err != nil {
+		hint = "r restart · x close · esc back to the items"
+	}
+	lines = append(lines, "", dim.Render(hint))
+
+	return lipgloss.JoinVertical(lipgloss.Left, header, m.renderTabs(), lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
+
+// renderRecordDiff shows what a record changed, at most height lines.
+func (m model) renderRecordDiff(r tailRecord, height int) []string {
+	added := lipgloss.NewStyle().Foreground(secondary)
+	removed := lipgloss.NewStyle().Foreground(alert)
+	changed := lipgloss.NewStyle().Foreground(warning)
+	dim := lipgloss.NewStyle().Foreground(textDim)
+	width := max(m.width-6, 20)
+
+	if r.OldImage == nil && r.NewImage == nil {
+		return []string{dim.Render("The stream only carries keys (KEYS_ONLY); there are no images to compare.")}
+	}
+	var lines []string
+	unchanged := 0
+	for _, d := range imageDiff(r.OldImage, r.NewImage) {
+		var line string
+		switch d.op {
+		case '+':
+			line = added.Render(truncateText(fmt.Sprintf("+ %s: %s", d.attr, diffValue(d.new)), width))
+		case '-':
+			line = removed.Render(truncateText(fmt.Sprintf("- %s: %s", d.attr, diffValue(d.old)), width))
+		case '~':
+			line = changed.Render(truncateText(fmt.Sprintf("~ %s: %s → %s", d.attr, diffValue(d.old), diffValue(d.new)), width))
+		default:
+			unchanged++
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if r.Type == "MODIFY" && (r.OldImage == nil || r.NewImage == nil) {
+		lines = append(lines, dim.Render("The stream carries only one image, so every attribute is shown as added or removed."))
+	}
+	if unchanged > 0 {
+		lines = append(lines, dim.Render(fmt.Sprintf("  %d unchanged attributes", unchanged)))
+	}
+	if len(lines) > height {
+		more := len(lines) - height + 1
+		lines = append(lines[:height-1], dim.Render(fmt.Sprintf("  … %d more", more)))
+	}
+	return lines
+}
+
+// streamBadge marks the items header while a tail is running.
+func (s *session) streamBadge() string {
+	switch {
+	case s.stream == nil:
+		return ""
+	case s.stream.err != nil:
+		return " · stream stopped"
+	}
+	return " · ● tailing"
+}
@@ -0,0 +1,89 @@
+package main
+
+import "testing"
+
+func TestImageDiff(t *testing.T) {
+	old := map[string]interface{}{"id": "a", "name": "Ann", "age": 3.0}
+	new := map[string]interface{}{"id": "a", "name": "Anna", "tags": []interface{}{"x"}}
+
+	got := ""
+	for _, d := range imageDiff(old, new) {
+		got += string(d.op) + d.attr + " "
+	}
+	if want := "-age  id ~name +tags "; got != want {
+		t.Errorf("diff = %q, want %q", got, want)
+	}
+}
+
+func TestApplyStreamRecord(t *testing.T) {
+	table := Table{Name: "t", PK: "id", SK: "v"}
+	m := model{
+		tables: []Table{table},
+		session: &session{
+			table:         "t",
+			items:         []Item{{"id": "a", "v": 1.0}, {"id": "b", "v": 1.0}, {"id": "c", "v": 1.0}},
+			modifiedItems: map[int]bool{2: true},
+			newItems:      map[int]bool{},
+			editedAttrs:   map[int]map[string]bool{},
+			selected:      map[int]bool{},
+			visualAnchor:  -1,
+		},
+	}
+	key := func(id string) map[string]interface{} { return map[string]interface{}{"id": id, "v": 1.0} }
+
+	records := []struct {
+		rec  StreamRecord
+		want string
+	}{
+		{StreamRecord{Type: "MODIFY", Keys: key("a"), NewImage: map[string]interface{}{"id": "a", "v": 1.0, "n": 2.0}}, streamApplied},
+		{StreamRecord{Type: "MODIFY", Keys: key("c"), NewImage: map[string]interface{}{"id": "c", "v": 1.0}}, streamKeptEdits},
+		{StreamRecord{Type: "MODIFY", Keys: key("b")}, streamNoNewImage},
+		{StreamRecord{Type: "REMOVE", Keys: key("b")}, streamRemoved},
+		{StreamRecord{Type: "REMOVE", Keys: key("z")}, streamNotLoaded},
+		{StreamRecord{Type: "INSERT", Keys: key("d"), NewImage: key("d")}, streamInserted},
+	}
+	for _, r := range records {
+		if got := m.applyStreamRecord(r.rec, table); got != r.want {
+			t.Errorf("%s %v: %s, want %s", r.rec.Type, r.rec.Keys["id"], got, r.want)
+		}
+	}
+
+	var ids []interface{}
+	for _, item := range m.items {
+		ids = append(ids, item["id"])
+	}
+	if len(ids) != 3 || ids[0] != "a" || ids[1] != "c" || ids[2] != "d" || m.items[0]["n"] != 2.0 {
+		t.Errorf("items = %v", m.items)
+	}
+	if !m.modifiedItems[1] {
+		t.Errorf("modified flag did not follow c: %v", m.modifiedItems)
+	}
+
+	// A query result only takes changes to the items it holds
+	m.isCustomQuery = true
+	if got := m.applyStreamRecord(StreamRecord{Type: "INSERT", Keys: key("e"), NewImage: key("e")}, table); got != streamNotLoaded {
+		t.Errorf("insert into a query result: %s", got)
+	}
+}
+
+func TestStreamRecordsWaitWhileBusy(t *testing.T) {
+	table := Table{Name: "t", PK: "id"}
+	s := newSession()
+	s.table = "t"
+	s.items = []Item{{"id": "a"}, {"id": "b"}}
+	s.itemCursor = 0
+	s.stream = newStreamTail()
+	m := &model{tables: []Table{table}, sessions: []*session{s}, session: s, view: viewDeleteConfirmation}
+
+	// Removing a while its delete is being confirmed would move the cursor onto b
+	m.Update(streamRecordsMsg{tail: s.stream, records: []StreamRecord{{Type: "REMOVE", Keys: map[string]interface{}{"id": "a"}}}})
+	if len(m.items) != 2 || m.items[m.itemCursor]["id"] != "a" || len(s.stream.pending) != 1 {
+		t.Fatalf("applied while busy: items %v, cursor %d, pending %d", m.items, m.itemCursor, len(s.stream.pending))
+	}
+
+	m.view = viewTableItems
+	m.Update(streamRecordsMsg{tail: s.stream})
+	if len(m.items) != 1 || m.items[0]["id"] != "b" || len(s.stream.pending) != 0 {
+		t.Errorf("after the dialog closed: items %v, pending %d", m.items, len(s.stream.pending))
+	}
+}
@@ -239,8 +239,13 @@ func NextTheme() string {
 	}
 	SetTheme(next)
 
-	// Save the new preference
-	_ = SaveConfig(Config{Theme: next})
+	// Save the new preference, keeping the rest of the config intact
+	cfg, err := LoadConfig()
+	if err != nil {
+		cfg = defaultConfig()
+	}
+	cfg.Theme = next
+	_ = SaveConfig(cfg)
 
 	return next
 }
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// tableListRow is one line of the table list: a table, or a group heading
+// when table is -1.
+type tableListRow struct {
+	table  int
+	header string
+}
+
+// tablePrefix is the environment-style prefix of a table name, such as
+// "prod-" in "prod-orders", or "" when the name has none.
+func tablePrefix(name string) string {
+	i := strings.IndexAny(name, "-_.")
+	if i <= 0 || i == len(name)-1 {
+		return ""
+	}
+	return name[:i+1]
+}
+
+func (m *model) isFavorite(name string) bool {
+	for _, f := range m.config.Favorites {
+		if f == name {
+			return true
+		}
+	}
+	return false
+}
+
+// tableListRows lays out the table list: tables matching the filter with
+// favorites pinned to the top, then the rest, optionally grouped under their
+// name prefix. m.tableCursor stays an index into m.tables.
+func (m *model) tableListRows() []tableListRow {
+	pattern := m.tableFilter.Value()
+	var favorites, rest []int
+	for i, t := range m.tables {
+		if _, ok := fuzzyMatch(pattern, t.Name); !ok {
+			continue
+		}
+		if m.isFavorite(t.Name) {
+			favorites = append(favorites, i)
+		} else {
+			rest = append(rest, i)
+		}
+	}
+
+	var rows []tableListRow
+	if len(favorites) > 0 && m.config.GroupTables {
+		rows = append(rows, tableListRow{table: -1, header: "★ favorites"})
+	}
+	for _, i := range favorites {
+		rows = append(rows, tableListRow{table: i})
+	}
+	if !m.config.GroupTables {
+		for _, i := range rest {
+			rows = append(rows, tableListRow{table: i})
+		}
+		return rows
+	}
+
+	groups := make(map[string][]int)
+	var prefixes []string
+	for _, i := range rest {
+		p := tablePrefix(m.tables[i].Name)
+		if _, ok := groups[p]; !ok {
+			prefixes = append(prefixes, p)
+		}
+		groups[p] = append(groups[p], i)
+	}
+	// Named groups alphabetically, tables without a prefix last
+	sort.Slice(prefixes, func(a, b int) bool {
+		if prefixes[a] == "" || prefixes[b] == "" {
+			return prefixes[b] == ""
+		}
+		return prefixes[a] < prefixes[b]
+	})
+	for _, p := range prefixes {
+		header := p + "*"
+		if p == "" {
+			header = "other"
+		}
+		rows = append(rows, tableListRow{table: -1, header: fmt.Sprintf("%s (%d)", header, len(groups[p]))})
+		for _, i := range groups[p] {
+			rows = append(rows, tableListRow{table: i})
+		}
+	}
+	return rows
+}
+
+// visibleTables returns the table indices of rows, in list order.
+func visibleTables(rows []tableListRow) []int {
+	var out []int
+	for _, r := range rows {
+		if r.table >= 0 {
+			out = append(out, r.table)
+		}
+	}
+	return out
+}
+
+// tableRowPos returns the row holding m.tableCursor, or -1 when that table is
+// filtered out.
+func (m *model) tableRowPos(rows []tableListRow) int {
+	for pos, r := range rows {
+		if r.table >= 0 && r.table == m.tableCursor {
+			return pos
+		}
+	}
+	return -1
+}
+
+// moveTableCursor moves the table cursor by delta tables, skipping headings.
+func (m *model) moveTableCursor(delta int) {
+	visible := visibleTables(m.tableListRows())
+	if len(visible) == 0 {
+		return
+	}
+	pos := 0
+	for p, i := range visible {
+		if i == m.tableCursor {
+			pos = max(0, min(p+delta, len(visible)-1))
+			break
+		}
+	}
+	m.tableCursor = visible[pos]
+}
+
+// snapTableCursor puts the cursor on the first listed table when its table
+// is no longer listed, or always when first is set.
+func (m *model) snapTableCursor(first bool) {
+	rows := m.tableListRows()
+	visible := visibleTables(rows)
+	if len(visible) > 0 && (first || m.tableRowPos(rows) < 0) {
+		m.tableCursor = visible[0]
+	}
+}
+
+func (m *model) clearTableFilter() {
+	m.tableFilter.SetValue("")
+	m.tableFilter.Blur()
+	m.tableFiltering = false
+}
+
+// toggleFavorite pins or unpins the table under the cursor and saves the
+// favorites to the config.
+func (m *model) toggleFavorite() error {
+	name := m.tables[m.tableCursor].Name
+	if m.isFavorite(name) {
+		var kept []string
+		for _, f := range m.config.Favorites {
+			if f != name {
+				kept = append(kept, f)
+			}
+		}
+		m.config.Favorites = kept
+	} else {
+		m.config.Favorites = append(m.config.Favorites, name)
+	}
+	return SaveConfig(m.config)
+}
+
+// toggleGrouping switches prefix grouping on or off and saves the choice.
+func (m *model) toggleGrouping() error {
+	m.config.GroupTables = !m.config.GroupTables
+	return SaveConfig(m.config)
+}
+
+// renderTableFilterBar shows the table filter input, or the applied filter
+// with its match count. It is empty when no filter is active.
+func (m model) renderTableFilterBar(matched int) string {
+	if !m.tableFiltering && m.tableFilter.Value() == "" {
+		return ""
+	}
+	info := lipgloss.NewStyle().Foreground(textDim).Render(fmt.Sprintf(" %d/%d", matched, len(m.tables)))
+	if m.tableFiltering {
+		return m.tableFilter.View() + info
+	}
+	return lipgloss.NewStyle().Foreground(secondary).Render("filter: "+m.tableFilter.Value()) + info
+}
@@ -0,0 +1,54 @@
+package main
+
+import "testing"
+
+func TestTableListRows(t *testing.T) {
+	m := model{
+		tables: []Table{
+			{Name: "dev-orders"}, {Name: "prod-orders"}, {Name: "audit"},
+			{Name: "prod-users"}, {Name: "dev-users"},
+		},
+		config: Config{Favorites: []string{"prod-users"}},
+	}
+
+	var names []string
+	for _, r := range m.tableListRows() {
+		names = append(names, m.tables[r.table].Name)
+	}
+	want := []string{"prod-users", "dev-orders", "prod-orders", "audit", "dev-users"}
+	if len(names) != len(want) {
+		t.Fatalf("rows = %v, want %v", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Fatalf("rows = %v, want %v", names, want)
+		}
+	}
+
+	m.config.GroupTables = true
+	var got []string
+	for _, r := range m.tableListRows() {
+		if r.table < 0 {
+			got = append(got, "#"+r.header)
+		} else {
+			got = append(got, m.tables[r.table].Name)
+		}
+	}
+	want = []string{"#★ favorites", "prod-users", "#dev-* (2)", "dev-orders", "dev-users",
+		"#prod-* (1)", "prod-orders", "#other (1)", "audit"}
+	if len(got) != len(want) {
+		t.Fatalf("grouped rows = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("grouped rows = %v, want %v", got, want)
+		}
+	}
+
+	// The cursor skips headings and stays on tables
+	m.tableCursor = 3
+	m.moveTableCursor(1)
+	if m.tables[m.tableCursor].Name != "dev-orders" {
+		t.Errorf("cursor on %s, want dev-orders", m.tables[m.tableCursor].Name)
+	}
+}
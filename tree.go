@@ -0,0 +1,281 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// treeRow is one visible line of the item inspector.
+type treeRow struct {
+	path      string // DynamoDB document path, e.g. address.lines[0]
+	key       string // Attribute name, or [i] for list and set elements
+	value     interface{}
+	depth     int
+	container bool
+}
+
+// dynamoType names the DynamoDB type of a value as returned by
+// attributevalue.UnmarshalMap.
+func dynamoType(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return "S"
+	case bool:
+		return "BOOL"
+	case float64, float32, int, int32, int64:
+		return "N"
+	case []byte:
+		return "B"
+	case []string:
+		return "SS"
+	case []float64:
+		return "NS"
+	case [][]byte:
+		return "BS"
+	case map[string]interface{}:
+		return "M"
+	}
+	return "L"
+}
+
+// treeChildren returns the child keys and values of a map, list or set, or
+// nil for scalars. Map keys are sorted so the tree is stable.
+func treeChildren(v interface{}) (keys []string, values []interface{}, ok bool) {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			values = append(values, t[k])
+		}
+		return keys, values, true
+	case []interface{}:
+		for i, e := range t {
+			keys, values = append(keys, fmt.Sprintf("[%d]", i)), append(values, e)
+		}
+		return keys, values, true
+	case []string:
+		for i, e := range t {
+			keys, values = append(keys, fmt.Sprintf("[%d]", i)), append(values, e)
+		}
+		return keys, values, true
+	case []float64:
+		for i, e := range t {
+			keys, values = append(keys, fmt.Sprintf("[%d]", i)), append(values, e)
+		}
+		return keys, values, true
+	case [][]byte:
+		for i, e := range t {
+			keys, values = append(keys, fmt.Sprintf("[%d]", i)), append(values, e)
+		}
+		return keys, values, true
+	}
+	return nil, nil, false
+}
+
+// childPath joins a parent document path and a child key.
+func childPath(parent, key string) string {
+	if strings.HasPrefix(key, "[") || parent == "" {
+		return parent + key
+	}
+	return parent + "." + key
+}
+
+// itemTree flattens an item into inspector rows, skipping the children of
+// collapsed paths. Key attributes come first.
+func itemTree(item Item, t Table, collapsed map[string]bool) []treeRow {
+	var attrs []string
+	for k := range item {
+		if k != t.PK && k != t.SK {
+			attrs = append(attrs, k)
+		}
+	}
+	sort.Strings(attrs)
+	if t.SK != "" {
+		if _, ok := item[t.SK]; ok {
+			attrs = append([]string{t.SK}, attrs...)
+		}
+	}
+	if _, ok := item[t.PK]; ok {
+		attrs = append([]string{t.PK}, attrs...)
+	}
+
+	var rows []treeRow
+	var walk func(path, key string, v interface{}, depth int)
+	walk = func(path, key string, v interface{}, depth int) {
+		keys, values, container := treeChildren(v)
+		rows = append(rows, treeRow{path: path, key: key, value: v, depth: depth, container: container})
+		if !container || collapsed[path] {
+			return
+		}
+		for i, k := range keys {
+			walk(childPath(path, k), k, values[i], depth+1)
+		}
+	}
+	for _, attr := range attrs {
+		walk(attr, attr, item[attr], 0)
+	}
+	return rows
+}
+
+// nodeText is what copying a node puts on the clipboard: strings and numbers
+// as-is, everything else as indented JSON.
+func nodeText(v interface{}) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case float64, bool:
+		return fmt.Sprintf("%v", t)
+	}
+	b, _ := json.MarshalIndent(v, "", "  ")
+	return string(b)
+}
+
+// renderTree draws the inspector rows with the cursor row highlighted when
+// the inspector has focus.
+func renderTree(rows []treeRow, cursor int, focused bool, collapsed map[string]bool, width int) string {
+	keyStyle := lipgloss.NewStyle().Foreground(primary)
+	typeStyle := lipgloss.NewStyle().Foreground(textDim)
+	markerStyle := lipgloss.NewStyle().Foreground(subtle)
+	valueStyles := map[string]lipgloss.Style{
+		"S":    lipgloss.NewStyle().Foreground(secondary),
+		"N":    lipgloss.NewStyle().Foreground(lipgloss.Color("#F5C25D")),
+		"BOOL": lipgloss.NewStyle().Foreground(alert),
+		"NULL": lipgloss.NewStyle().Foreground(lipgloss.Color("250")),
+	}
+	summaryStyle := lipgloss.NewStyle().Foreground(textDim).Italic(true)
+	cursorStyle := lipgloss.NewStyle().Background(lipgloss.Color("237"))
+
+	lines := make([]string, len(rows))
+	for i, r := range rows {
+		marker := "  "
+		if r.container {
+			marker = "▾ "
+			if collapsed[r.path] {
+				marker = "▸ "
+			}
+		}
+		typ := dynamoType(r.value)
+		prefix := strings.Repeat("  ", r.depth) + marker + r.key + " " + typ + " "
+
+		var value string
+		switch {
+		case r.container && collapsed[r.path]:
+			value = summaryStyle.Render(summarizeValue(r.value))
+		case r.container:
+			value = summaryStyle.Render(fmt.Sprintf("(%d)", treeLen(r.value)))
+		default:
+			text := cellText(r.value)
+			switch r.value.(type) {
+			case string:
+				text = fmt.Sprintf("%q", r.value)
+			case nil:
+				text = "null"
+			case []byte:
+				text = summarizeValue(r.value)
+			}
+			style, ok := valueStyles[typ]
+			if !ok {
+				style = summaryStyle
+			}
+			value = style.Render(truncateText(text, max(width-lipgloss.Width(prefix), 1)))
+		}
+
+		line := strings.Repeat("  ", r.depth) + markerStyle.Render(marker) +
+			keyStyle.Render(r.key) + " " + typeStyle.Render(typ) + " " + value
+		if focused && i == cursor {
+			line = cursorStyle.Width(width).Render(line)
+		}
+		lines[i] = line
+	}
+	return strings.Join(lines, "\n")
+}
+
+func treeLen(v interface{}) int {
+	keys, _, _ := treeChildren(v)
+	return len(keys)
+}
+
+// inspectorRows is the tree of the selected item.
+func (m *model) inspectorRows() []treeRow {
+	if len(m.items) == 0 || len(m.tables) == 0 {
+		return nil
+	}
+	return itemTree(m.items[m.itemCursor], m.tables[m.tableCursor], m.collapsed)
+}
+
+// moveInspector moves the inspector cursor and scrolls it into view.
+func (m *model) moveInspector(delta int) {
+	rows := m.inspectorRows()
+	m.inspectorCursor = max(0, min(m.inspectorCursor+delta, len(rows)-1))
+	m.updateViewport()
+	if m.inspectorCursor < m.viewport.YOffset {
+		m.viewport.SetYOffset(m.inspectorCursor)
+	} else if m.inspectorCursor >= m.viewport.YOffset+m.viewport.Height {
+		m.viewport.SetYOffset(m.inspectorCursor - m.viewport.Height + 1)
+	}
+}
+
+// toggleNode expands or collapses the container under the inspector cursor.
+func (m *model) toggleNode() {
+	rows := m.inspectorRows()
+	if m.inspectorCursor >= len(rows) || !rows[m.inspectorCursor].container {
+		return
+	}
+	path := rows[m.inspectorCursor].path
+	if m.collapsed[path] {
+		delete(m.collapsed, path)
+	} else {
+		m.collapsed[path] = true
+	}
+	m.updateViewport()
+}
+
+// setAllCollapsed collapses every container of the selected item, or expands
+// everything when collapse is false. The cursor stays on the top-level
+// attribute it was inside.
+func (m *model) setAllCollapsed(collapse bool) {
+	rows := m.inspectorRows()
+	top := ""
+	for i := min(m.inspectorCursor, len(rows)-1); i >= 0; i-- {
+		if rows[i].depth == 0 {
+			top = rows[i].path
+			break
+		}
+	}
+
+	m.collapsed = make(map[string]bool)
+	if collapse {
+		for _, r := range rows {
+			if r.container {
+				m.collapsed[r.path] = true
+			}
+		}
+	}
+
+	m.inspectorCursor = 0
+	for i, r := range m.inspectorRows() {
+		if r.path == top {
+			m.inspectorCursor = i
+			break
+		}
+	}
+	m.moveInspector(0)
+}
+
+// selectedNode is the inspector row under the cursor.
+func (m *model) selectedNode() (treeRow, bool) {
+	rows := m.inspectorRows()
+	if m.inspectorCursor >= len(rows) {
+		return treeRow{}, false
+	}
+	return rows[m.inspectorCursor], true
+}
@@ -0,0 +1,39 @@
+package main
+
+import "testing"
+
+func TestItemTree(t *testing.T) {
+	table := Table{Name: "t", PK: "id", SK: "ts"}
+	item := Item{
+		"id":    "a",
+		"ts":    1.0,
+		"addr":  map[string]interface{}{"city": "Oslo", "lines": []interface{}{"x", nil}},
+		"tags":  []string{"p", "q"},
+		"flag":  true,
+		"blob":  []byte("hi"),
+		"sizes": []float64{1, 2},
+	}
+
+	rows := itemTree(item, table, map[string]bool{})
+	var paths, kinds []string
+	for _, r := range rows {
+		paths = append(paths, r.path)
+		kinds = append(kinds, dynamoType(r.value))
+	}
+	wantPaths := []string{"id", "ts", "addr", "addr.city", "addr.lines", "addr.lines[0]", "addr.lines[1]",
+		"blob", "flag", "sizes", "sizes[0]", "sizes[1]", "tags", "tags[0]", "tags[1]"}
+	wantKinds := []string{"S", "N", "M", "S", "L", "S", "NULL", "B", "BOOL", "NS", "N", "N", "SS", "S", "S"}
+	if len(paths) != len(wantPaths) {
+		t.Fatalf("paths = %v, want %v", paths, wantPaths)
+	}
+	for i := range wantPaths {
+		if paths[i] != wantPaths[i] || kinds[i] != wantKinds[i] {
+			t.Errorf("row %d = %s %s, want %s %s", i, paths[i], kinds[i], wantPaths[i], wantKinds[i])
+		}
+	}
+
+	rows = itemTree(item, table, map[string]bool{"addr": true})
+	if rows[3].path != "blob" {
+		t.Errorf("collapsed addr still shows children: row 3 = %s", rows[3].path)
+	}
+}
@@ -1,7 +1,6 @@
 package main
 
 import (
-	"encoding/json"
 	"fmt"
 	"log"
 	"context"
@@ -58,6 +57,12 @@ func isLikelyScan(sql string, pk string) bool {
 	return !match
 }
 
+// isMutationStatement reports whether a PartiQL statement writes data.
+func isMutationStatement(sql string) bool {
+	upper := strings.ToUpper(strings.TrimSpace(sql))
+	return strings.HasPrefix(upper, "INSERT") || strings.HasPrefix(upper, "UPDATE") || strings.HasPrefix(upper, "DELETE")
+}
+
 func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 
@@ -74,6 +79,7 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.tables = make([]Table, len(msg.tables))
 		m.Region = msg.region
 		m.AccountId = msg.accountId
+		m.trackCapacity(msg.capacity)
 		for i, t := range msg.tables {
 			m.tables[i] = Table{
 				Name:      t.Name,
@@ -84,14 +90,35 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				Region:    t.Region,
 				ItemCount: t.ItemCount,
 				GSIs:      t.GSIs,
+				GSIStatus: t.GSIStatus,
+				Restoring: t.Restoring,
 				Status:    t.Status,
+				BillingMode: t.BillingMode,
+				SizeBytes:   t.SizeBytes,
+				ReadCapacity: t.ReadCapacity,
 			}
 		}
-		return m, nil
+		m.snapTableCursor(true)
+		var polls []tea.Cmd
+		for _, t := range m.tables {
+			if tableBusy(t) {
+				polls = append(polls, m.pollTable(t.Name))
+			}
+		}
+		return m, tea.Batch(polls...)
 
 	case itemsLoadedMsg:
 		m.loading = false
 		m.view = viewTableItems
+		m.table = m.tables[m.tableCursor].Name
+		m.trackCapacity(msg.capacity)
+		if !msg.isAppend {
+			if m.isCustomQuery {
+				m.settleTurn(itemsOutcome("returned", msg.items, m.tables[m.tableCursor]), true)
+			} else {
+				m.settleTurn("executed; the table was re-scanned", true)
+			}
+		}
 		
 		newItems := make([]Item, len(msg.items))
 		for i, item := range msg.items {
@@ -99,19 +126,28 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 		if msg.isAppend {
+			m.pages++
 			m.items = append(m.items, newItems...)
+			m.sortItems()
 			// Don't reset cursor or pane on append, just viewport update
 			// Maybe move cursor to start of new items?
 		} else {
+			m.pages = 1
 			m.items = newItems
-			m.modifiedItems = make(map[int]bool)
-			m.newItems = make(map[int]bool)
+			m.changes = make(map[int]byte)
+			m.discardEdits()
+			m.clearSelection()
 			m.itemCursor = 0
 			m.activePane = 0
+			m.sortItems()
+			m.itemCursor = 0
 		}
 		
 		m.lastEvaluatedKey = msg.nextKey
-		m.updateViewport()
+		m.snapCursorToFilter()
+		if msg.notice != "" {
+			m.notice = msg.notice
+		}
 		return m, nil
 
 	case editorFinishedMsg:
@@ -120,6 +156,8 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.view = viewError
 		} else if msg.newItem != nil {
 			if msg.isNew {
+				// Make sure the new item is not hidden by the filter
+				m.clearItemFilter()
 				m.items = append(m.items, msg.newItem)
 				m.itemCursor = len(m.items) - 1
 				m.modifiedItems[m.itemCursor] = true
@@ -128,6 +166,8 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			} else {
 				m.items[m.itemCursor] = msg.newItem
 				m.modifiedItems[m.itemCursor] = true
+				// The whole item may have changed, so save it with a full put
+				delete(m.editedAttrs, m.itemCursor)
 				m.updateViewport()
 			}
 		}
@@ -135,6 +175,7 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case itemSavedMsg:
 		m.loading = false
+		m.trackCapacity(msg.capacity)
 		if msg.err != nil {
 			m.err = msg.err
 			m.view = viewError
@@ -148,6 +189,7 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Success! Clear modified/new flags for the saved item
 			delete(m.modifiedItems, m.itemCursor)
 			delete(m.newItems, m.itemCursor)
+			delete(m.editedAttrs, m.itemCursor)
 
 			// Deduplicate: Remove OTHER items with the same PK/SK
 			savedItem := m.items[m.itemCursor]
@@ -164,25 +206,10 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			pkVal := getKeyVal(savedItem, currentTable.PK)
 			skVal := getKeyVal(savedItem, currentTable.SK)
 
-			var newItemsList []Item
-			// We need to rebuild modifiedItems/newItems because indices will shift
-			newModifiedItems := make(map[int]bool)
-			newNewItems := make(map[int]bool)
-			
-			// We track the new index of the current cursor
-			newCursor := m.itemCursor
-			
-			// Source index vs Destination index
-			dstIdx := 0
-			
-			for srcIdx, item := range m.items {
-				if srcIdx == m.itemCursor {
+			var duplicates []int
+			for idx, item := range m.items {
+				if idx == m.itemCursor {
 					// Always keep the item we just saved
-					newItemsList = append(newItemsList, item)
-					// If it was modified (it shouldn't be now), we'd map it.
-					// newCursor tracks where this lands
-					newCursor = dstIdx
-					dstIdx++
 					continue
 				}
 
@@ -196,26 +223,12 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 
 				if match {
-					// Duplicate found! Skip it.
-					// Do NOT increment dstIdx.
-				} else {
-					// Keep this item
-					newItemsList = append(newItemsList, item)
-					// Preserve modified/new status
-					if m.modifiedItems[srcIdx] {
-						newModifiedItems[dstIdx] = true
-					}
-					if m.newItems[srcIdx] {
-						newNewItems[dstIdx] = true
-					}
-					dstIdx++
+					duplicates = append(duplicates, idx)
 				}
 			}
-
-			m.items = newItemsList
-			m.modifiedItems = newModifiedItems
-			m.newItems = newNewItems
-			m.itemCursor = newCursor
+			// removeItems keeps the cursor and the modified/new flags of the
+			// remaining items in step as indices shift
+			m.removeItems(duplicates)
 
 			m.view = viewTableItems
 			m.updateViewport()
@@ -224,6 +237,7 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case itemDeletedMsg:
 		m.loading = false
+		m.trackCapacity(msg.capacity)
 		if msg.err != nil {
 			m.err = msg.err
 			m.view = viewError
@@ -233,37 +247,204 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.tables[m.tableCursor].ItemCount--
 			}
 
-			// Remove the item from the list
+			// Remove the item from the list; the cursor moves to the next one
 			if len(m.items) > 0 {
-				m.items = append(m.items[:m.itemCursor], m.items[m.itemCursor+1:]...)
-				// Adjust cursor if necessary
-				if m.itemCursor >= len(m.items) && m.itemCursor > 0 {
-					m.itemCursor--
-				}
-				
-				// Rebuild modifiedItems and newItems
-				newModified := make(map[int]bool)
-				newNew := make(map[int]bool)
-				for k, v := range m.modifiedItems {
-					if k < m.itemCursor {
-						newModified[k] = v
-					} else if k > m.itemCursor {
-						newModified[k-1] = v
-					}
-				}
-				for k, v := range m.newItems {
-					if k < m.itemCursor {
-						newNew[k] = v
-					} else if k > m.itemCursor {
-						newNew[k-1] = v
-					}
-				}
-				m.modifiedItems = newModified
-				m.newItems = newNew
+				m.removeItems([]int{m.itemCursor})
 			}
 			m.view = viewTableItems
+			m.snapCursorToFilter()
+		}
+		return m, nil
+
+	case schemaChangedMsg:
+		m.loading = false
+		if msg.err != nil {
+			m.err = msg.err
+			m.view = viewError
+			return m, nil
+		}
+		m.view = viewTableList
+		m.notice = fmt.Sprintf("%s accepted for %s; watching its status", strings.ReplaceAll(msg.action, "_", " "), msg.table)
+		switch msg.action {
+		case auditUpdateSettings:
+			m.notice = fmt.Sprintf("Updated %s of %s", strings.Join(msg.settings, ", "), msg.table)
+		case auditCreateBackup:
+			m.notice = fmt.Sprintf("Backup of %s started", msg.table)
+			return m, m.openBackups()
+		case auditRestoreTable:
+			m.notice = fmt.Sprintf("Restoring into %s; progress is shown in the table list", msg.table)
+		}
+		return m, m.applySchemaChange(msg)
+
+	case profileLoadedMsg:
+		m.loading = false
+		if msg.err != nil {
+			m.trackCapacity(msg.capacity)
+			m.err = msg.err
+			m.view = viewError
+			return m, nil
+		}
+		m.trackCapacity(msg.profile.Capacity)
+		m.profiles[msg.profile.Table] = msg.profile
+		m.profileCursor = 0
+		m.view = viewProfile
+		if msg.saveErr != nil {
+			m.notice = fmt.Sprintf("Couldn't cache the profile: %v", msg.saveErr)
+		}
+		return m, nil
+
+	case aggregatedMsg:
+		m.loading = false
+		m.trackCapacity(msg.capacity)
+		if msg.err != nil {
+			m.settleTurn("failed: "+msg.err.Error(), true)
+			m.err = msg.err
+			m.view = viewError
+			return m, nil
+		}
+		m.settleTurn(aggregateOutcome(msg.result), true)
+		m.showAggregate(msg.result)
+		return m, nil
+
+	case backupsLoadedMsg:
+		m.loading = false
+		if msg.err != nil {
+			m.err = msg.err
+			m.view = viewError
+			return m, nil
+		}
+		m.backups, m.backupWindow = msg.backups, msg.window
+		m.backupCursor = max(min(m.backupCursor, len(m.backups)-1), 0)
+		m.view = viewBackups
+		return m, nil
+
+	case settingsLoadedMsg:
+		m.loading = false
+		if msg.err != nil {
+			m.err = msg.err
+			m.view = viewError
+			return m, nil
+		}
+		m.view = viewTableList
+		return m, m.openSchemaForm(newSettingsForm(msg.table, msg.settings))
+
+	case refreshTickMsg:
+		s := msg.session
+		if !m.hasSession(s) || s.refreshGen != msg.gen || s.refreshEvery == 0 || s.table == "" {
+			return m, nil // Turned off, restarted or the tab was closed
+		}
+		if m.refreshBusy(s) {
+			return m, refreshTickCmd(s, msg.gen, s.refreshEvery)
+		}
+		// More pages may have been loaded since auto-refresh was turned on
+		if ti := m.tableIndex(s.table); ti >= 0 {
+			cost := s.refreshCost(m.tables[ti])
+			if !m.refreshAllowed(cost, s.refreshEvery) {
+				s.stopRefresh()
+				m.notice = fmt.Sprintf("Auto-refresh of %s stopped: ≈%.0f RCU/min is above your %.0f RCU scan_confirm_rcu", s.table, perMinute(cost, s.refreshEvery), m.config.ScanConfirmRCU)
+				return m, nil
+			}
+			s.refreshRCU = perMinute(cost, s.refreshEvery)
+		}
+		var query []string
+		if s.isCustomQuery {
+			query = s.query
+		}
+		return m, refreshItemsCmd(m.aws, s, msg.gen, s.table, query, s.pages)
+
+	case refreshedMsg:
+		s := msg.session
+		if !m.hasSession(s) || s.refreshGen != msg.gen || s.refreshEvery == 0 {
+			return m, nil
+		}
+		m.trackCapacity(msg.capacity)
+		if msg.err != nil {
+			s.stopRefresh()
+			m.notice = fmt.Sprintf("Auto-refresh of %s stopped: %v", s.table, msg.err)
+			return m, nil
+		}
+		if ti := m.tableIndex(s.table); ti >= 0 && !m.refreshBusy(s) {
+			fresh := make([]Item, len(msg.items))
+			for i, item := range msg.items {
+				fresh[i] = Item(item)
+			}
+			active := m.session
+			m.session = s
+			m.applyRefresh(fresh, m.tables[ti])
+			if !s.isCustomQuery {
+				s.lastEvaluatedKey = msg.nextKey
+			}
+			if active == s {
+				m.updateViewport()
+			}
+			m.session = active
+		}
+		s.changesGen++
+		return m, tea.Batch(refreshTickCmd(s, msg.gen, s.refreshEvery), changesExpiredCmd(s, s.changesGen))
+
+	case changesExpiredMsg:
+		s := msg.session
+		if !m.hasSession(s) || s.changesGen != msg.gen {
+			return m, nil
+		}
+		if m.refreshBusy(s) {
+			return m, changesExpiredCmd(s, msg.gen)
+		}
+		active := m.session
+		m.session = s
+		m.dropRemovedRows()
+		m.changes = make(map[int]byte)
+		if active == s {
 			m.updateViewport()
 		}
+		m.session = active
+		return m, nil
+
+	case streamRecordsMsg:
+		var owner *session
+		for _, s := range m.sessions {
+			if s.stream == msg.tail {
+				owner = s
+			}
+		}
+		if owner == nil {
+			return m, nil // Tail was stopped or its tab closed
+		}
+		if msg.err != nil {
+			msg.tail.err = msg.err
+			return m, nil
+		}
+		msg.tail.pos = msg.pos
+		// Like a refresh, records wait while a dialog or batch holds row indices
+		msg.tail.pending = append(msg.tail.pending, msg.records...)
+		if !m.refreshBusy(owner) {
+			m.applyStreamRecords(owner, msg.tail.pending)
+			msg.tail.pending = nil
+		}
+		return m, readStreamCmd(m.aws, owner.table, msg.tail, msg.pos, true)
+
+	case tableStatusMsg:
+		return m, m.applyTableStatus(msg)
+
+	case batchDoneMsg:
+		m.loading = false
+		m.trackCapacity(msg.capacity)
+		m.applyBatchResult(msg)
+		if msg.err != nil {
+			m.err = fmt.Errorf("%s stopped after %d items: %w", msg.action, len(msg.done), msg.err)
+			m.view = viewError
+			return m, nil
+		}
+		m.view = viewTableItems
+		m.notice = fmt.Sprintf("Batch %s applied to %d items", msg.action, len(msg.done))
+		return m, nil
+
+	case exportedMsg:
+		if msg.err != nil {
+			m.notice = fmt.Sprintf("Export failed: %v", msg.err)
+		} else {
+			m.notice = fmt.Sprintf("Exported %d items to %s", msg.count, msg.path)
+		}
 		return m, nil
 
 	case sqlGeneratedMsg:
@@ -273,6 +454,9 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.view = viewError
 		} else {
 			m.llmResult = msg.result
+			if len(m.tables) > 0 {
+				m.addTurn(m.tables[m.tableCursor].Name, m.lastQuestion, m.llmResult)
+			}
 			
 			if m.llmResult.Mode == "refusal" {
 				m.err = fmt.Errorf("%s", m.llmResult.RefusalReason)
@@ -280,63 +464,46 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, nil
 			}
 
-			m.isScanWarning = false
+			m.llmEdited = false
+			m.rejectedEdit = ""
+			m.genEdit = nil
+			if err := m.reviewGenerated(); err != nil {
+				m.settleTurn("rejected: "+err.Error(), true)
+				m.err = err
+				m.view = viewError
+			}
+		}
+		return m, nil
 
-			if m.llmResult.Mode == "sql" {
-				if len(m.tables) > 0 {
-					currentPK := m.tables[m.tableCursor].PK
-					for _, sql := range m.llmResult.Statements {
-						if isLikelyScan(sql, currentPK) {
-							m.isScanWarning = true
-							break
-						}
-					}
-				}
-				// Prepare viewport
-				vpWidth := int(float64(m.width) * 0.7)
-				vpHeight := m.height - 14
-				if vpHeight < 5 { vpHeight = 5 } // Min height
-				
-				m.sqlViewport.Width = vpWidth
-				m.sqlViewport.Height = vpHeight
-				m.sqlViewport.SetContent(strings.Join(m.llmResult.Statements, "\n\n"))
-			} else if m.llmResult.Mode == "plan" {
-				if m.llmResult.Plan.Safety.NeedsConfirmation {
-					if m.llmResult.Plan.Safety.Reason == "full_table_scan" {
-						m.isScanWarning = true
-					}
-				}
-				// Prepare viewport for plan details
-				p := m.llmResult.Plan
-				bodyText := fmt.Sprintf("OPERATION: %s\n\n", strings.ToUpper(p.Operation))
-				bodyText += fmt.Sprintf("STEP 1 (READ):\n%s\n\n", p.Read.Partiql)
-				if p.Write != nil {
-					bodyText += fmt.Sprintf("STEP 2 (WRITE - Per Item):\n%s\n", p.Write.PerItem.PartiqlTemplate)
-				}
-				if p.Safety.NeedsConfirmation {
-					bodyText += fmt.Sprintf("\nNOTE: %s", p.Safety.Reason)
-				}
-				
-				vpWidth := int(float64(m.width) * 0.7)
-				vpHeight := m.height - 14
-				if vpHeight < 5 { vpHeight = 5 }
+	case explainedMsg:
+		m.loading = false
+		if msg.err != nil {
+			m.err = msg.err
+			m.view = viewError
+			return m, nil
+		}
+		m.explanation = msg.result
+		m.view = viewExplain
+		return m, nil
 
-				m.sqlViewport.Width = vpWidth
-				m.sqlViewport.Height = vpHeight
-				m.sqlViewport.SetContent(bodyText)
-			}
-			m.view = viewSqlConfirmation
+	case generatedEditedMsg:
+		if msg.err != nil {
+			m.notice = fmt.Sprintf("Editor failed: %v", msg.err)
+		} else if err := m.applyGeneratedEdit(msg.doc); err != nil {
+			m.notice = err.Error() + " (e reopens your edit)"
 		}
 		return m, nil
 
 	case bulkDiscoveryLoadedMsg:
 		m.loading = false
+		m.trackCapacity(msg.capacity)
 		m.pendingPlanItems = make([]Item, len(msg.items))
 		for i, item := range msg.items {
 			m.pendingPlanItems[i] = Item(item)
 		}
 		
 		log.Printf("Bulk Discovery: Found %d items", len(m.pendingPlanItems))
+		m.settleTurn(itemsOutcome("read found", msg.items, m.tables[m.tableCursor]), len(msg.items) == 0)
 		
 		if len(m.pendingPlanItems) == 0 {
 			m.err = fmt.Errorf("No matching items found for bulk action.")
@@ -344,16 +511,48 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 
+		if w := m.llmResult.Plan.Write; w != nil && w.Action == "compute" {
+			m.prepareComputed()
+			return m, nil
+		}
 		m.view = viewBulkConfirmation
 		return m, nil
 
+	case auditLoadedMsg:
+		m.loading = false
+		if msg.err != nil {
+			m.err = fmt.Errorf("failed to read audit log: %w", msg.err)
+			m.view = viewError
+			return m, nil
+		}
+		m.auditEntries = msg.entries
+		m.auditCursor = 0
+		m.view = viewAuditLog
+		return m, nil
+
+	case clipboardMsg:
+		if msg.err != nil {
+			m.notice = fmt.Sprintf("Copy failed: %v", msg.err)
+		} else {
+			m.notice = "Copied " + msg.what
+		}
+		return m, nil
+
 	case errMsg:
+		m.trackCapacity(failedCapacity(msg))
+		m.settleTurn("failed: "+msg.Error(), true)
 		m.err = msg
 		m.loading = false
 		m.view = viewError
 		return m, nil
 
 	case tea.KeyMsg:
+		m.notice = ""
+		// ctrl+c quits from every view, dialog and input
+		if msg.String() == "ctrl+c" {
+			return m, tea.Quit
+		}
+
 		if m.view == viewError {
 			// Allow any key to go back
 			m.view = viewTableList
@@ -367,7 +566,16 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.loading = true
 				m.view = viewLoading
 				m.statusMessage = "Saving item to DynamoDB..."
-				return m, saveItemCmd(m.aws, m.tables[m.tableCursor].Name, m.items[m.itemCursor])
+				t := m.tables[m.tableCursor]
+				key := itemKey(m.items[m.itemCursor], t)
+				if set, ok := m.pendingUpdate(); ok {
+					audit := m.newAuditEntry(auditUpdateItem)
+					audit.Keys = []map[string]interface{}{key}
+					return m, updateItemCmd(m.aws, t.Name, key, set, audit)
+				}
+				audit := m.newAuditEntry(auditPutItem)
+				audit.Keys = []map[string]interface{}{key}
+				return m, saveItemCmd(m.aws, t.Name, m.items[m.itemCursor], audit)
 			case "n", "N", "esc":
 				m.view = viewTableItems
 				return m, nil
@@ -383,7 +591,7 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.view = viewLoading
 				m.statusMessage = "Deleting item from DynamoDB..."
 				t := m.tables[m.tableCursor]
-				return m, deleteItemCmd(m.aws, t.Name, m.items[m.itemCursor], t.PK, t.SK)
+				return m, deleteItemCmd(m.aws, t.Name, m.items[m.itemCursor], t.PK, t.SK, m.newAuditEntry(auditDeleteItem))
 			case "n", "N", "esc":
 				m.view = viewTableItems
 				return m, nil
@@ -392,6 +600,39 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
+		if m.view == viewSqlConfirmation && m.genEdit != nil {
+			return m, m.updateGeneratedEdit(msg)
+		}
+
+		if m.view == viewSqlConfirmation && m.scanPhrase != "" {
+			// Expensive scan: y/j/k are ordinary characters here, only the exact phrase runs it
+			switch msg.String() {
+			case "up":
+				m.sqlViewport.LineUp(1)
+				return m, nil
+			case "down":
+				m.sqlViewport.LineDown(1)
+				return m, nil
+			case "esc":
+				m.scanPhrase = ""
+				m.confirmInput.Blur()
+				m.settleTurn("not run: cancelled at the confirmation", true)
+				m.view = m.previousView
+				return m, nil
+			case "ctrl+e":
+				return m, m.startGeneratedEdit()
+			case "enter":
+				if strings.TrimSpace(m.confirmInput.Value()) != m.scanPhrase {
+					return m, nil
+				}
+				m.scanPhrase = ""
+				m.confirmInput.Blur()
+				return m.executeGenerated()
+			}
+			m.confirmInput, cmd = m.confirmInput.Update(msg)
+			return m, cmd
+		}
+
 		if m.view == viewSqlConfirmation {
 			switch msg.String() {
 			case "up", "k":
@@ -407,83 +648,17 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.sqlViewport.HalfViewDown()
 				return m, nil
 			case "y", "Y", "enter":
-				m.loading = true
-				m.view = viewLoading
-				m.statusMessage = "Executing..."
-
-				// Mode: SQL
-				if m.llmResult.Mode == "sql" {
-					// Determine if it's a mutation to set the UI state correctly
-					isMutation := false
-					for _, sql := range m.llmResult.Statements {
-						upper := strings.ToUpper(strings.TrimSpace(sql))
-						if strings.HasPrefix(upper, "INSERT") || strings.HasPrefix(upper, "UPDATE") || strings.HasPrefix(upper, "DELETE") {
-							isMutation = true
-							break
-						}
-					}
-					m.isCustomQuery = !isMutation
-					
-					return m, func() tea.Msg {
-						ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
-						defer cancel()
-						
-						if len(m.llmResult.Statements) == 1 {
-							op := Operation{
-								expression: m.llmResult.Statements[0],
-								params:     []types.AttributeValue{},
-							}
-							
-							if isMutation {
-								_, err := m.aws.SqlQuery(ctx, op)
-								if err != nil { return errMsg(err) }
-								scanItems, nextKey, err := m.aws.ScanTable(ctx, m.tables[m.tableCursor].Name, nil)
-								if err != nil { return errMsg(err) }
-								return itemsLoadedMsg{items: scanItems, nextKey: nextKey, isAppend: false}
-							}
-							
-							items, err := m.aws.SqlQuery(ctx, op)
-							if err != nil { return errMsg(err) }
-							return itemsLoadedMsg{items: items, isAppend: false}
-						}
-
-						// Batch
-						items, err := m.aws.BatchSqlQuery(ctx, m.llmResult.Statements)
-						if err != nil { return errMsg(err) }
-
-						if isMutation {
-							scanItems, nextKey, err := m.aws.ScanTable(ctx, m.tables[m.tableCursor].Name, nil)
-							if err != nil { return errMsg(err) }
-							return itemsLoadedMsg{items: scanItems, nextKey: nextKey, isAppend: false}
-						}
-
-						return itemsLoadedMsg{items: items, isAppend: false}
-					}
-				} else {
-					// Mode: PLAN
-					return m, func() tea.Msg {
-						ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
-						defer cancel()
-
-						// 1. Execute READ
-						readOp := Operation{
-							expression: m.llmResult.Plan.Read.Partiql,
-						}
-						items, err := m.aws.SqlQuery(ctx, readOp)
-						if err != nil { return errMsg(err) }
-
-						// If Select, we are done
-						if m.llmResult.Plan.Operation == "select" {
-							m.isCustomQuery = true
-							return itemsLoadedMsg{items: items, isAppend: false}
-						}
+				return m.executeGenerated()
 
-						// If Scan_Then_Write, proceed to next step
-						return bulkDiscoveryLoadedMsg{items: items}
-					}
-				}
+			case "e", "ctrl+e":
+				return m, m.startGeneratedEdit()
+			case "s":
+				return m, m.openSaveQuery(m.lastQuestion, &m.llmResult)
+			case "E":
+				return m, m.editGeneratedCmd()
 
 			case "n", "N", "esc":
+				m.settleTurn("not run: cancelled at the confirmation", true)
 				m.view = m.previousView
 				return m, nil
 			default:
@@ -493,11 +668,27 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		if m.view == viewBulkConfirmation {
 			switch msg.String() {
+			case "up", "k":
+				m.sqlViewport.LineUp(1)
+				return m, nil
+			case "down", "j":
+				m.sqlViewport.LineDown(1)
+				return m, nil
+			case "ctrl+u":
+				m.sqlViewport.HalfViewUp()
+				return m, nil
+			case "ctrl+d":
+				m.sqlViewport.HalfViewDown()
+				return m, nil
 			case "y", "Y", "enter":
+				if m.computed != nil {
+					return m, m.executeComputed()
+				}
 				m.loading = true
 				m.view = viewLoading
 				m.statusMessage = "Executing Bulk Mutations..."
 
+				audit := m.newAuditEntry(auditPlanBulkWrite)
 				return m, func() tea.Msg {
 					ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 					defer cancel()
@@ -521,6 +712,7 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					}
 					
 					var queries []string
+					var keys []map[string]interface{}
 					for _, item := range m.pendingPlanItems {
 						pkVal := item[pkName]
 						if pkVal == nil {
@@ -540,32 +732,132 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 							return errMsg(err)
 						}
 						queries = append(queries, q)
+						keys = append(keys, itemKey(item, t))
 					}
 					
 					// Chunking handled by BatchSqlQuery (aws.go) which now supports > 25 items by internal chunking.
-					_, err := m.aws.BatchSqlQuery(ctx, queries)
+					_, used, err := m.aws.BatchSqlQuery(ctx, queries)
+					audit.PartiQL = queries
+					audit.Keys = keys
+					audit.finish(used, err)
 					if err != nil {
-						return errMsg(err)
+						return failed(err, used)
 					}
 					
 					// Re-scan
-					scanItems, nextKey, err := m.aws.ScanTable(ctx, m.tables[m.tableCursor].Name, nil)
-					if err != nil { return errMsg(err) }
-					return itemsLoadedMsg{items: scanItems, nextKey: nextKey, isAppend: false}
+					scanItems, nextKey, scanUsed, err := m.aws.ScanTable(ctx, m.tables[m.tableCursor].Name, nil)
+					if err != nil { return failed(err, used.plus(scanUsed)) }
+					return itemsLoadedMsg{items: scanItems, nextKey: nextKey, isAppend: false, capacity: used.plus(scanUsed)}
 				}
 
 			case "n", "N", "esc":
+				m.settleTurn("write cancelled at the bulk confirmation", true)
 				m.view = viewTableItems
 				m.pendingPlanItems = nil
+				m.computed = nil
 				return m, nil
 			}
 		}
 
-		if !m.inputMode && msg.String() == "ctrl+c" {
-			return m, tea.Quit
+		if m.view == viewAuditLog {
+			return m.updateAuditLog(msg)
+		}
+
+		if m.view == viewColumnPicker {
+			return m.updateColumnPicker(msg)
+		}
+
+		if m.view == viewSortPicker {
+			return m.updateSortPicker(msg)
+		}
+
+		if m.view == viewBatchSet {
+			return m.updateBatchSet(msg)
+		}
+
+		if m.view == viewBatchConfirmation {
+			return m.updateBatchConfirmation(msg)
+		}
+
+		if m.view == viewSchemaForm {
+			return m.updateSchemaForm(msg)
+		}
+
+		if m.view == viewDropConfirm {
+			return m.updateDrop(msg)
+		}
+
+		if m.view == viewBackups {
+			return m.updateBackups(msg)
+		}
+		if m.view == viewStream {
+			return m.updateStream(msg)
+		}
+		if m.view == viewProfile {
+			return m.updateProfile(msg)
+		}
+		if m.view == viewAggregate {
+			return m.updateAggregate(msg)
+		}
+		if m.view == viewExplain {
+			return m.updateExplain(msg)
+		}
+		if m.view == viewSavedQueries {
+			return m.updateSavedQueries(msg)
+		}
+
+		if m.view == viewTableItems && m.copyPending {
+			return m, m.copyItem(msg.String())
+		}
+
+		if m.view == viewTableItems && m.cellEdit != nil {
+			return m.updateCellEdit(msg)
+		}
+
+		if m.filtering {
+			switch msg.String() {
+			case "enter":
+				m.filtering = false
+				m.itemFilter.Blur()
+				return m, nil
+			case "esc":
+				m.clearItemFilter()
+				m.snapCursorToFilter()
+				return m, nil
+			case "tab":
+				m.filterAllAttrs = !m.filterAllAttrs
+				m.snapCursorToFilter()
+				return m, nil
+			}
+			m.itemFilter, cmd = m.itemFilter.Update(msg)
+			m.snapCursorToFilter()
+			return m, cmd
+		}
+
+		if m.tableFiltering {
+			switch msg.String() {
+			case "enter":
+				m.tableFiltering = false
+				m.tableFilter.Blur()
+				return m, nil
+			case "esc":
+				m.clearTableFilter()
+				return m, nil
+			case "up", "down":
+				delta := 1
+				if msg.String() == "up" {
+					delta = -1
+				}
+				m.moveTableCursor(delta)
+				return m, nil
+			}
+			m.tableFilter, cmd = m.tableFilter.Update(msg)
+			m.snapTableCursor(true)
+			return m, cmd
 		}
 
 		if msg.String() == "/" && !m.inputMode {
+			m.input.Placeholder = m.questionPlaceholder()
 			m.inputMode = true
 			m.input.Focus()
 			return m, textinput.Blink
@@ -580,19 +872,53 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					question := m.input.Value()
 					m.input.SetValue("") // Clear on execute
 					if question != "" && len(m.tables) > 0 {
-						m.previousView = m.view // Save current view (List or Items)
-						m.loading = true
-						m.view = viewLoading
-						m.statusMessage = "Generating SQL with Bedrock..."
-						return m, generateSQLCmd(m.aws, question, m.tables[m.tableCursor])
+						return m, m.askBedrock(question)
 					}
 				}
+			case "ctrl+s":
+				question := strings.TrimSpace(m.input.Value())
+				if question != "" && len(m.tables) > 0 {
+					m.inputMode = false
+					m.input.Blur()
+					m.input.SetValue("")
+					return m, m.openSaveQuery(question, nil)
+				}
+				return m, nil
 			}
 			m.input, cmd = m.input.Update(msg)
 			return m, cmd
 		}
 
 		switch msg.String() {
+		case "tab", "shift+tab":
+			if len(m.sessions) > 1 && (m.view == viewTableList || m.view == viewTableItems) {
+				step := 1
+				if msg.String() == "shift+tab" {
+					step = len(m.sessions) - 1
+				}
+				m.switchSession((m.activeSession + step) % len(m.sessions))
+			}
+			return m, nil
+
+		case "ctrl+t":
+			if m.view == viewTableList || m.view == viewTableItems {
+				m.openSession()
+			}
+			return m, nil
+
+		case "ctrl+w":
+			if m.view != viewTableList && m.view != viewTableItems {
+				return m, nil
+			}
+			if len(m.sessions) < 2 {
+				m.notice = "Can't close the last tab"
+			} else if m.hasUnsaved() {
+				m.notice = "This tab has unsaved edits; save them or press r to discard first"
+			} else {
+				m.closeSession()
+			}
+			return m, nil
+
 		case "q":
 			if m.view == viewTableItems {
 				if m.isCustomQuery {
@@ -603,19 +929,53 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					return m, scanTable(m.aws, m.tables[m.tableCursor].Name, nil, false)
 				}
 				m.view = viewTableList
+				m.table = ""
+				m.stream = nil
+				m.stopRefresh()
 				m.items = []Item{} // Clear items to save memory
+				m.discardEdits()
+				m.clearSelection()
+				m.clearItemFilter()
+				m.columnOffset = 0
+				m.sortAttr = ""
+				m.gridMode = false
+				m.gridCol, m.gridOffset = 0, 0
 				m.activePane = 0
 				return m, nil
 			}
 			return m, tea.Quit
 
 		case "l", "right":
-			if m.view == viewTableItems {
+			if m.view == viewTableItems && m.gridMode {
+				m.moveGridCol(1)
+			} else if m.view == viewTableItems {
 				m.activePane = 1
+				m.updateViewport()
 			}
 		case "h", "left":
+			if m.view == viewTableItems && m.gridMode {
+				m.moveGridCol(-1)
+			} else if m.view == viewTableItems {
+				m.activePane = 0
+				m.updateViewport()
+			}
+
+		case "g":
 			if m.view == viewTableItems {
+				m.gridMode = !m.gridMode
 				m.activePane = 0
+			} else if m.view == viewTableList {
+				if err := m.toggleGrouping(); err != nil {
+					m.notice = fmt.Sprintf("Couldn't save the grouping: %v", err)
+				}
+			}
+
+		case "i":
+			if m.view == viewTableItems && m.gridMode {
+				return m, m.startCellEdit()
+			}
+			if m.view == viewTableList && m.tableRowPos(m.tableListRows()) >= 0 {
+				return m, m.openSchemaForm(newIndexForm(m.tables[m.tableCursor]))
 			}
 
 		case "?":
@@ -623,89 +983,246 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		case "up", "k":
 			if m.view == viewTableList {
-				if m.tableCursor > 0 {
-					m.tableCursor--
-				}
+				m.moveTableCursor(-1)
 			} else if m.view == viewTableItems {
 				if m.activePane == 0 {
-					if m.itemCursor > 0 {
-						m.itemCursor--
-						m.updateViewport()
-					}
+					m.moveCursor(-1)
 				} else {
-					m.viewport.LineUp(1)
+					m.moveInspector(-1)
 				}
 			}
 
 		case "down", "j":
 			if m.view == viewTableList {
-				if m.tableCursor < len(m.tables)-1 {
-					m.tableCursor++
-				}
+				m.moveTableCursor(1)
 			} else if m.view == viewTableItems {
 				if m.activePane == 0 {
-					if m.itemCursor < len(m.items)-1 {
-						m.itemCursor++
-						m.updateViewport()
-					}
+					m.moveCursor(1)
 				} else {
-					m.viewport.LineDown(1)
+					m.moveInspector(1)
 				}
 			}
 
 		case "ctrl+d":
 			amount := 10
 			if m.view == viewTableList {
-				m.tableCursor = min(m.tableCursor+amount, len(m.tables)-1)
+				m.moveTableCursor(amount)
 			} else if m.view == viewTableItems {
 				if m.activePane == 0 {
-					m.itemCursor = min(m.itemCursor+amount, len(m.items)-1)
-					m.updateViewport()
+					m.moveCursor(amount)
 				} else {
-					m.viewport.HalfViewDown()
+					m.moveInspector(m.viewport.Height / 2)
 				}
 			}
 
 		case "ctrl+u":
 			amount := 10
 			if m.view == viewTableList {
-				m.tableCursor = max(m.tableCursor-amount, 0)
+				m.moveTableCursor(-amount)
 			} else if m.view == viewTableItems {
 				if m.activePane == 0 {
-					m.itemCursor = max(m.itemCursor-amount, 0)
-					m.updateViewport()
+					m.moveCursor(-amount)
 				} else {
-					m.viewport.HalfViewUp()
+					m.moveInspector(-m.viewport.Height / 2)
 				}
 			}
 
-		case "enter", " ":
-			if m.view == viewTableList && msg.String() == "enter" {
-				if len(m.tables) > 0 {
+		case " ":
+			if m.view == viewTableItems && m.activePane == 0 && len(m.visibleItems()) > 0 {
+				m.toggleSelect()
+			} else if m.view == viewTableItems && m.activePane == 1 {
+				m.toggleNode()
+			}
+
+		case "V":
+			if m.view == viewTableItems && m.activePane == 0 && len(m.visibleItems()) > 0 {
+				m.toggleVisual()
+			}
+
+		case "*":
+			if m.view == viewTableItems {
+				m.selectAllFiltered()
+			} else if m.view == viewTableList && len(visibleTables(m.tableListRows())) > 0 {
+				if err := m.toggleFavorite(); err != nil {
+					m.notice = fmt.Sprintf("Couldn't save favorites: %v", err)
+				}
+			}
+
+		case "esc":
+			if m.view == viewTableItems {
+				m.clearSelection()
+			} else if m.view == viewTableList {
+				m.clearTableFilter()
+				m.snapTableCursor(false)
+			}
+
+		case "x", "X":
+			if m.view == viewTableItems && len(m.visibleItems()) > 0 {
+				indices := m.selectedIndices()
+				if len(indices) == 0 {
+					indices = []int{m.itemCursor}
+				}
+				items := make([]Item, len(indices))
+				for i, idx := range indices {
+					items[i] = m.items[idx]
+				}
+				return m, exportItemsCmd(m.tables[m.tableCursor].Name, items)
+			}
+			if m.view == viewTableList && m.tableRowPos(m.tableListRows()) >= 0 {
+				if gsis := m.tables[m.tableCursor].GSIs; len(gsis) > 0 {
+					return m, m.openDrop(gsis)
+				}
+				m.notice = "This table has no global secondary indexes"
+			}
+
+		case "b", "B":
+			if m.view == viewTableItems && len(m.selectedIndices()) > 0 {
+				return m, m.openBatchSet()
+			}
+			if m.view == viewTableList && m.tableRowPos(m.tableListRows()) >= 0 {
+				m.backupCursor = 0
+				return m, m.openBackups()
+			}
+
+		case "enter":
+			if m.view == viewTableList {
+				if m.tableRowPos(m.tableListRows()) >= 0 {
 					m.loading = true
 					m.view = viewLoading
 					m.isCustomQuery = false
+					m.clearItemFilter()
+					m.sortAttr = ""
 					m.statusMessage = fmt.Sprintf("Scanning %s...", m.tables[m.tableCursor].Name)
 					return m, scanTable(m.aws, m.tables[m.tableCursor].Name, nil, false)
 				}
+			} else if m.view == viewTableItems && m.gridMode {
+				if len(m.visibleItems()) > 0 {
+					m.jumpToAttribute(m.gridAttr())
+				}
+			} else if m.view == viewTableItems && m.activePane == 1 {
+				m.toggleNode()
 			} else if m.view == viewTableItems {
 				m.activePane = 1
+				m.updateViewport()
+			}
+
+		case "-":
+			if m.view == viewTableItems && m.activePane == 1 {
+				m.setAllCollapsed(true)
+			}
+
+		case "+", "=":
+			if m.view == viewTableItems && m.activePane == 1 {
+				m.setAllCollapsed(false)
+			}
+
+		case "y", "Y":
+			if m.view == viewTableItems && m.activePane == 0 && msg.String() == "y" && len(m.visibleItems()) > 0 {
+				m.copyPending = true
+				m.notice = copyPrompt()
+				return m, nil
+			}
+			if m.view == viewTableItems && m.activePane == 1 {
+				node, ok := m.selectedNode()
+				if !ok {
+					return m, nil
+				}
+				if msg.String() == "Y" {
+					return m, copyCmd(node.path, "path "+node.path)
+				}
+				return m, copyCmd(nodeText(node.value), "value of "+node.path)
 			}
 
 		case "r", "R":
+			if msg.String() == "R" && m.view == viewTableItems {
+				return m, m.cycleRefresh()
+			}
 			if (m.view == viewTableList || m.view == viewTableItems) && len(m.tables) > 0 {
 				m.loading = true
 				m.view = viewLoading
 				m.isCustomQuery = false
 				m.statusMessage = fmt.Sprintf("Refreshing %s...", m.tables[m.tableCursor].Name)
 				// Reset any "new" items tracking since we are reloading from source
-				m.newItems = make(map[int]bool)
-				m.modifiedItems = make(map[int]bool)
+				m.discardEdits()
+				m.clearSelection()
 				return m, scanTable(m.aws, m.tables[m.tableCursor].Name, nil, false)
 			}
 			
+		case "f":
+			if m.view == viewTableItems {
+				m.filtering = true
+				m.activePane = 0
+				m.itemFilter.Focus()
+				return m, textinput.Blink
+			} else if m.view == viewTableList && len(m.tables) > 0 {
+				m.tableFiltering = true
+				m.tableFilter.Focus()
+				return m, textinput.Blink
+			}
+
+		case "c":
+			if m.view == viewTableItems && len(m.tables) > 0 {
+				m.openColumnPicker()
+				return m, nil
+			}
+
+		case "o":
+			if m.view == viewTableItems && len(m.items) > 0 {
+				m.sortChoices = m.loadedAttributes()
+				m.sortCursor = 0
+				for i, attr := range m.sortChoices {
+					if attr == m.sortAttr {
+						m.sortCursor = i
+					}
+				}
+				m.previousView = m.view
+				m.view = viewSortPicker
+				return m, nil
+			}
+
+		case "O":
+			if m.view == viewTableItems && m.sortAttr != "" {
+				m.sortDesc = !m.sortDesc
+				m.sortItems()
+				m.updateViewport()
+			}
+
+		case "<", "shift+left":
+			if m.view == viewTableItems && m.columnOffset > 0 {
+				m.columnOffset--
+			}
+
+		case ">", "shift+right":
+			if m.view == viewTableItems && m.columnsHiddenRight() {
+				m.columnOffset++
+			}
+
+		case "L":
+			if m.view == viewTableList || m.view == viewTableItems {
+				m.previousView = m.view
+				m.loading = true
+				m.view = viewLoading
+				m.statusMessage = "Loading audit log..."
+				return m, loadAuditLogCmd
+			}
+
+		case "C":
+			if m.view == viewTableList || m.view == viewTableItems {
+				m.clearConversation()
+			}
+
+		case "I":
+			if m.view == viewTableItems {
+				return m, m.startExplain()
+			}
+
+		case "Q":
+			if m.view == viewTableItems || (m.view == viewTableList && m.tableRowPos(m.tableListRows()) >= 0) {
+				m.openSavedQueries()
+			}
+
 		case "t", "T":
-			_ = NextTheme()
+			m.config.Theme = NextTheme()
 			// Update persistent help styles to match new theme
 			m.help.Styles.ShortKey.Foreground(primary)
 			m.help.Styles.FullKey.Foreground(primary)
@@ -716,16 +1233,26 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		case "e", "E":
 			log.Printf("Edit key pressed. View: %v, Items: %d", m.view, len(m.items))
-			if m.view == viewTableItems && len(m.items) > 0 {
+			if m.view == viewTableItems && len(m.visibleItems()) > 0 {
 				log.Println("Opening editor...")
 				return m, openEditor(m.items[m.itemCursor], false)
 			}
 
+		case "w":
+			if m.view == viewTableItems && m.table != "" {
+				return m, m.startStream()
+			}
+
 		case "a", "A":
 			if m.view == viewTableItems {
 				return m, openEditor(nil, true)
 			}
 			
+		case "m":
+			if m.view == viewTableItems || (m.view == viewTableList && m.tableRowPos(m.tableListRows()) >= 0) {
+				return m, m.openProfile(false)
+			}
+
 		case "p", "P":
 			if m.view == viewTableItems && !m.isCustomQuery {
 				if m.lastEvaluatedKey != nil {
@@ -738,16 +1265,32 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 
 		case "s", "S":
-			if m.view == viewTableItems && len(m.items) > 0 {
+			if m.view == viewTableItems && len(m.visibleItems()) > 0 {
 				m.view = viewConfirmation
 				return m, nil
 			}
+			if m.view == viewTableList && m.tableRowPos(m.tableListRows()) >= 0 {
+				return m, m.openSettings()
+			}
 
 		case "d", "D":
-			if m.view == viewTableItems && len(m.items) > 0 {
+			if m.view == viewTableItems && len(m.selectedIndices()) > 0 {
+				m.batchAction = batchDelete
+				m.view = viewBatchConfirmation
+				return m, nil
+			}
+			if m.view == viewTableItems && len(m.visibleItems()) > 0 {
 				m.view = viewDeleteConfirmation
 				return m, nil
 			}
+			if m.view == viewTableList && m.tableRowPos(m.tableListRows()) >= 0 {
+				return m, m.openDrop(nil)
+			}
+
+		case "n", "N":
+			if m.view == viewTableList {
+				return m, m.openSchemaForm(newCreateTableForm())
+			}
 		}
 
 	case spinner.TickMsg:
@@ -770,7 +1313,248 @@ func (m *model) updateViewport() {
 		m.viewport.SetContent("No items found.")
 		return
 	}
-	selectedItem := m.items[m.itemCursor]
-	b, _ := json.MarshalIndent(selectedItem, "", "  ")
-	m.viewport.SetContent(highlightJSON(string(b)))
-}
\ No newline at end of file
+	if m.itemFilter.Value() != "" && m.visiblePos(m.visibleItems()) < 0 {
+		m.viewport.SetContent("No items match the filter.")
+		return
+	}
+	rows := m.inspectorRows()
+	m.inspectorCursor = max(0, min(m.inspectorCursor, len(rows)-1))
+	m.viewport.SetContent(renderTree(rows, m.inspectorCursor, m.activePane == 1, m.collapsed, m.viewport.Width))
+}
+
+// executeGenerated runs the confirmed LLM result: statements in sql mode, or
+// the read step of a plan (which may lead on to the bulk confirmation).
+func (m *model) executeGenerated() (tea.Model, tea.Cmd) {
+	m.loading = true
+	m.view = viewLoading
+	m.statusMessage = "Executing..."
+
+	// Mode: SQL
+	if m.llmResult.Mode == "sql" {
+		// Determine if it's a mutation to set the UI state correctly
+		isMutation := false
+		for _, sql := range m.llmResult.Statements {
+			if isMutationStatement(sql) {
+				isMutation = true
+				break
+			}
+		}
+		m.isCustomQuery = !isMutation
+		if !isMutation {
+			m.query = append([]string{}, m.llmResult.Statements...)
+		}
+
+		audit := m.newAuditEntry(auditStatement, m.llmResult.Statements...)
+		if len(m.llmResult.Statements) > 1 {
+			audit.Action = auditBatch
+		}
+		
+		return m, func() tea.Msg {
+			ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+			defer cancel()
+			
+			if len(m.llmResult.Statements) == 1 {
+				op := Operation{
+					expression: m.llmResult.Statements[0],
+					params:     []types.AttributeValue{},
+				}
+				
+				if isMutation {
+					_, used, err := m.aws.SqlQuery(ctx, op)
+					audit.finish(used, err)
+					if err != nil { return failed(err, used) }
+					scanItems, nextKey, scanUsed, err := m.aws.ScanTable(ctx, m.tables[m.tableCursor].Name, nil)
+					if err != nil { return failed(err, used.plus(scanUsed)) }
+					return itemsLoadedMsg{items: scanItems, nextKey: nextKey, isAppend: false, capacity: used.plus(scanUsed)}
+				}
+				
+				items, used, err := m.aws.SqlQuery(ctx, op)
+				audit.finish(used, err)
+				if err != nil { return failed(err, used) }
+				return itemsLoadedMsg{items: items, isAppend: false, capacity: used}
+			}
+
+			// Batch
+			items, used, err := m.aws.BatchSqlQuery(ctx, m.llmResult.Statements)
+			audit.finish(used, err)
+			if err != nil { return failed(err, used) }
+
+			if isMutation {
+				scanItems, nextKey, scanUsed, err := m.aws.ScanTable(ctx, m.tables[m.tableCursor].Name, nil)
+				if err != nil { return failed(err, used.plus(scanUsed)) }
+				return itemsLoadedMsg{items: scanItems, nextKey: nextKey, isAppend: false, capacity: used.plus(scanUsed)}
+			}
+
+			return itemsLoadedMsg{items: items, isAppend: false, capacity: used}
+		}
+	} else {
+		// Mode: PLAN
+		if m.llmResult.Plan.Operation == "aggregate" {
+			return m.executeAggregate()
+		}
+		audit := m.newAuditEntry(auditPlanRead, m.llmResult.Plan.Read.Partiql)
+		if m.llmResult.Plan.Operation == "select" {
+			m.query = []string{m.llmResult.Plan.Read.Partiql}
+		}
+		return m, func() tea.Msg {
+			ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+			defer cancel()
+
+			// 1. Execute READ
+			readOp := Operation{
+				expression: m.llmResult.Plan.Read.Partiql,
+			}
+			items, used, err := m.aws.SqlQuery(ctx, readOp)
+			audit.finish(used, err)
+			if err != nil { return failed(err, used) }
+
+			// If Select, we are done
+			if m.llmResult.Plan.Operation == "select" {
+				m.isCustomQuery = true
+				return itemsLoadedMsg{items: items, isAppend: false, capacity: used}
+			}
+
+			// If Scan_Then_Write, proceed to next step
+			return bulkDiscoveryLoadedMsg{items: items, capacity: used}
+		}
+	}
+}
+
+// filteredAudit returns the audit entries matching the current filter text.
+func (m *model) filteredAudit() []AuditEntry {
+	filter := m.auditFilter.Value()
+	if filter == "" {
+		return m.auditEntries
+	}
+	var out []AuditEntry
+	for _, e := range m.auditEntries {
+		if e.matches(filter) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+func (m *model) updateAuditLog(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.auditFiltering {
+		switch msg.String() {
+		case "enter":
+			m.auditFiltering = false
+			m.auditFilter.Blur()
+			return m, nil
+		case "esc":
+			m.auditFiltering = false
+			m.auditFilter.Blur()
+			m.auditFilter.SetValue("")
+			m.auditCursor = 0
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.auditFilter, cmd = m.auditFilter.Update(msg)
+		m.auditCursor = 0
+		return m, cmd
+	}
+
+	entries := m.filteredAudit()
+	switch msg.String() {
+	case "q", "esc":
+		m.view = m.previousView
+		m.auditEntries = nil
+	case "/", "f":
+		m.auditFiltering = true
+		m.auditFilter.Focus()
+		return m, textinput.Blink
+	case "r", "R":
+		m.loading = true
+		m.view = viewLoading
+		m.statusMessage = "Loading audit log..."
+		return m, loadAuditLogCmd
+	case "up", "k":
+		if m.auditCursor > 0 {
+			m.auditCursor--
+		}
+	case "down", "j":
+		if m.auditCursor < len(entries)-1 {
+			m.auditCursor++
+		}
+	case "ctrl+u":
+		m.auditCursor = max(m.auditCursor-10, 0)
+	case "ctrl+d":
+		m.auditCursor = max(min(m.auditCursor+10, len(entries)-1), 0)
+	}
+	return m, nil
+}
+
+func (m *model) updateColumnPicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q":
+		m.view = m.previousView
+		m.columnChoices = nil
+	case "up", "k":
+		if m.columnCursor > 0 {
+			m.columnCursor--
+		}
+	case "down", "j":
+		if m.columnCursor < len(m.columnChoices)-1 {
+			m.columnCursor++
+		}
+	case " ", "x":
+		if m.columnCursor < len(m.columnChoices) {
+			m.columnChoices[m.columnCursor].on = !m.columnChoices[m.columnCursor].on
+		}
+	case "K", "shift+up":
+		if m.columnCursor > 0 {
+			c := m.columnCursor
+			m.columnChoices[c-1], m.columnChoices[c] = m.columnChoices[c], m.columnChoices[c-1]
+			m.columnCursor--
+		}
+	case "J", "shift+down":
+		if m.columnCursor < len(m.columnChoices)-1 {
+			c := m.columnCursor
+			m.columnChoices[c+1], m.columnChoices[c] = m.columnChoices[c], m.columnChoices[c+1]
+			m.columnCursor++
+		}
+	case "r":
+		for i := range m.columnChoices {
+			m.columnChoices[i].on = false
+		}
+		fallthrough
+	case "enter":
+		if err := m.saveColumnChoices(); err != nil {
+			m.err = fmt.Errorf("failed to save columns: %w", err)
+			m.view = viewError
+			return m, nil
+		}
+		m.view = m.previousView
+		m.columnChoices = nil
+	}
+	return m, nil
+}
+
+func (m *model) updateSortPicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q":
+		m.view = m.previousView
+	case "up", "k":
+		if m.sortCursor > 0 {
+			m.sortCursor--
+		}
+	case "down", "j":
+		if m.sortCursor < len(m.sortChoices)-1 {
+			m.sortCursor++
+		}
+	case "enter", "a", "d":
+		if m.sortCursor < len(m.sortChoices) {
+			m.sortAttr = m.sortChoices[m.sortCursor]
+			m.sortDesc = msg.String() == "d"
+			m.sortItems()
+		}
+		m.view = m.previousView
+		m.updateViewport()
+	case "x":
+		// Clearing the sort keeps the current order; a refresh restores scan order
+		m.sortAttr = ""
+		m.view = m.previousView
+	}
+	return m, nil
+}
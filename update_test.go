@@ -0,0 +1,51 @@
+package main
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestCopyPromptLetsQuitThrough(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	m := initialModel(nil)
+	m.tables = []Table{{Name: "t", PK: "id"}}
+	m.items = []Item{{"id": "a"}}
+	m.view = viewTableItems
+	m.copyPending = true
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+	if cmd == nil {
+		t.Fatal("ctrl+c at the copy prompt returned no command, want tea.Quit")
+	}
+	if _, ok := cmd().(tea.QuitMsg); !ok {
+		t.Error("ctrl+c at the copy prompt did not quit")
+	}
+}
+
+func TestCtrlCQuitsFromEveryView(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	for v := viewLoading; v <= viewSavedQueries; v++ {
+		m := initialModel(nil)
+		m.view = v
+		_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+		if cmd == nil {
+			t.Errorf("view %d: ctrl+c returned no command, want tea.Quit", v)
+			continue
+		}
+		if _, ok := cmd().(tea.QuitMsg); !ok {
+			t.Errorf("view %d: ctrl+c did not quit", v)
+		}
+	}
+
+	// Also while the question input has focus
+	m := initialModel(nil)
+	m.view, m.inputMode = viewTableList, true
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+	if cmd == nil {
+		t.Fatal("ctrl+c in the question input returned no command, want tea.Quit")
+	}
+	if _, ok := cmd().(tea.QuitMsg); !ok {
+		t.Error("ctrl+c in the question input did not quit")
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"sort"
 	"strings"
@@ -25,6 +26,15 @@ func (m model) View() string {
 	case viewConfirmation:
 		question := lipgloss.NewStyle().Bold(true).Render("Are you sure you want to save this item to DynamoDB?")
 		warning := lipgloss.NewStyle().Foreground(warning).Render("This will overwrite the existing item.")
+		if set, ok := m.pendingUpdate(); ok {
+			attrs := make([]string, 0, len(set))
+			for attr := range set {
+				attrs = append(attrs, attr)
+			}
+			sort.Strings(attrs)
+			question = lipgloss.NewStyle().Bold(true).Render("Save the edited attributes to DynamoDB?")
+			warning = lipgloss.NewStyle().Foreground(secondary).Render("Only these attributes will be updated: " + strings.Join(attrs, ", "))
+		}
 		controls := lipgloss.NewStyle().Foreground(subtle).Render("(y/enter to confirm, n/esc to cancel)")
 		
 		content = lipgloss.Place(m.width, m.height-4, lipgloss.Center, lipgloss.Center,
@@ -54,6 +64,30 @@ func (m model) View() string {
 				),
 			),
 		)
+	case viewBatchSet:
+		content = lipgloss.Place(m.width, m.height-4, lipgloss.Center, lipgloss.Center,
+			dialogBoxStyle.Render(m.renderBatchSet()))
+	case viewBatchConfirmation:
+		content = lipgloss.Place(m.width, m.height-4, lipgloss.Center, lipgloss.Center,
+			dialogBoxStyle.Render(m.renderBatchConfirmation()))
+	case viewBackups:
+		content = m.renderBackups()
+	case viewStream:
+		content = m.renderStream()
+	case viewProfile:
+		content = m.renderProfile()
+	case viewAggregate:
+		content = m.renderAggregate()
+	case viewExplain:
+		content = m.renderExplain()
+	case viewSavedQueries:
+		content = m.renderSavedQueries()
+	case viewSchemaForm:
+		content = lipgloss.Place(m.width, m.height-4, lipgloss.Center, lipgloss.Center,
+			dialogBoxStyle.Render(m.renderSchemaForm()))
+	case viewDropConfirm:
+		content = lipgloss.Place(m.width, m.height-4, lipgloss.Center, lipgloss.Center,
+			dialogBoxStyle.Render(m.renderDrop()))
 	case viewSqlConfirmation:
 		title := lipgloss.NewStyle().Bold(true).Foreground(highlight).Render("Execute Generated SQL?")
 		
@@ -67,14 +101,63 @@ func (m model) View() string {
 		
 		sqlText := vpStyle.Render(m.sqlViewport.View())
 		
-		controls := lipgloss.NewStyle().Foreground(subtle).Render("(y/enter to execute, n/esc to cancel, j/k to scroll)")
+		controls := lipgloss.NewStyle().Foreground(subtle).Render("(y/enter to execute, n/esc to cancel, e edit, E $EDITOR, s save, j/k scroll)")
+		if m.scanPhrase != "" {
+			controls = lipgloss.NewStyle().Foreground(subtle).Render("(type the phrase and press enter to execute, esc to cancel, ctrl+e to edit, ↑/↓ to scroll)")
+		}
 		
 		var contentComponents []string
+		if m.genEdit != nil {
+			title = lipgloss.NewStyle().Bold(true).Foreground(highlight).Render("Edit Generated SQL")
+			contentComponents = append(contentComponents, title, vpStyle.Render(m.genEdit.area.View()))
+			if m.genEdit.err != nil {
+				contentComponents = append(contentComponents, lipgloss.NewStyle().Foreground(warning).Bold(true).Width(vpWidth).Render(m.genEdit.err.Error()))
+			}
+			contentComponents = append(contentComponents, "", lipgloss.NewStyle().Foreground(subtle).Render("(ctrl+s to check and apply, esc to discard the edit)"))
+			content = lipgloss.Place(m.width, m.height-4, lipgloss.Center, lipgloss.Center,
+				dialogBoxStyle.Width(vpWidth+6).Align(lipgloss.Center).Render(
+					lipgloss.JoinVertical(lipgloss.Center, contentComponents...),
+				),
+			)
+			break
+		}
 		contentComponents = append(contentComponents, title, sqlText)
 		
 		if m.isScanWarning {
 			scanWarn := lipgloss.NewStyle().Foreground(warning).Bold(true).Render("⚠ WARNING: This query may result in a FULL TABLE SCAN!")
-			contentComponents = append(contentComponents, scanWarn, "")
+			contentComponents = append(contentComponents, scanWarn)
+			if len(m.tables) > 0 {
+				scanCost := lipgloss.NewStyle().Foreground(warning).Render(describeScan(m.tables[m.tableCursor]))
+				contentComponents = append(contentComponents, scanCost)
+			}
+			contentComponents = append(contentComponents, "")
+		}
+
+		if m.scanPhrase != "" {
+			prompt := fmt.Sprintf("This scan exceeds your %.0f RCU threshold. Type %q to run it:", m.config.ScanConfirmRCU, m.scanPhrase)
+			contentComponents = append(contentComponents,
+				lipgloss.NewStyle().Bold(true).Render(prompt),
+				m.confirmInput.View(),
+				"",
+			)
+		}
+
+		if len(m.tables) > 0 {
+			t := m.tables[m.tableCursor]
+			var est Capacity
+			if m.llmResult.Mode == "plan" && m.llmResult.Plan != nil {
+				est = estimatePlanRead(t, m.llmResult.Plan)
+			} else {
+				est = estimateStatements(t, m.llmResult.Statements)
+			}
+			estText := describeEstimate(t, est)
+			if p := m.llmResult.Plan; m.llmResult.Mode == "plan" && p != nil && p.Aggregate != nil && p.Aggregate.Source == aggregateLoaded {
+				estText = "No DynamoDB reads: the loaded items are aggregated in memory"
+			}
+			if m.llmResult.Mode == "plan" && m.llmResult.Plan != nil && m.llmResult.Plan.Write != nil {
+				estText += fmt.Sprintf(", plus ~%.0f WCU per matched item", itemWCU(t.avgItemSize()))
+			}
+			contentComponents = append(contentComponents, lipgloss.NewStyle().Foreground(textDim).Render(estText), "")
 		}
 		
 		contentComponents = append(contentComponents, controls)
@@ -104,6 +187,41 @@ func (m model) View() string {
 		infoText := lipgloss.NewStyle().Foreground(lipgloss.Color("252")).Render(info)
 		actionText := lipgloss.NewStyle().Foreground(warning).Bold(true).Render(actionStr)
 		controls := lipgloss.NewStyle().Foreground(subtle).Render("(y/enter to execute, n/esc to cancel)")
+
+		var estText string
+		if len(m.tables) > 0 {
+			t := m.tables[m.tableCursor]
+			writes := count
+			if m.computed != nil {
+				writes = len(m.computed.statements)
+			}
+			estText = lipgloss.NewStyle().Foreground(textDim).Render(describeEstimate(t, estimateBulkWrite(t, writes)))
+		}
+
+		if w := m.computed; w != nil {
+			actionStr = fmt.Sprintf("Step 2: UPDATE %d items with computed values?", len(w.statements))
+			info += fmt.Sprintf(" %d already hold the computed values, %d skipped.", w.unchanged, len(w.skipped))
+			preview := lipgloss.NewStyle().
+				Border(lipgloss.NormalBorder()).
+				BorderForeground(subtle).
+				Padding(0, 1).
+				Width(m.sqlViewport.Width).
+				Render(m.sqlViewport.View())
+			content = lipgloss.Place(m.width, m.height-4, lipgloss.Center, lipgloss.Center,
+				dialogBoxStyle.Width(m.sqlViewport.Width+6).Align(lipgloss.Center).Render(
+					lipgloss.JoinVertical(lipgloss.Center,
+						title,
+						lipgloss.NewStyle().Foreground(lipgloss.Color("252")).Render(info),
+						preview,
+						lipgloss.NewStyle().Foreground(warning).Bold(true).Render(actionStr),
+						estText,
+						"",
+						lipgloss.NewStyle().Foreground(subtle).Render("(y/enter to execute, n/esc to cancel, j/k to scroll)"),
+					),
+				),
+			)
+			break
+		}
 		
 		content = lipgloss.Place(m.width, m.height-4, lipgloss.Center, lipgloss.Center,
 			dialogBoxStyle.Render(
@@ -113,12 +231,22 @@ func (m model) View() string {
 					infoText,
 					"",
 					actionText,
+					estText,
 					"",
 					controls,
 				),
 			),
 		)
 
+	case viewAuditLog:
+		content = m.renderAuditLog()
+
+	case viewColumnPicker:
+		content = m.renderColumnPicker()
+
+	case viewSortPicker:
+		content = m.renderSortPicker()
+
 	case viewError:
 		content = lipgloss.Place(m.width, m.height-4, lipgloss.Center, lipgloss.Center,
 			lipgloss.JoinVertical(lipgloss.Center,
@@ -140,8 +268,12 @@ func (m model) View() string {
 		// Render a nice status bar
 		// Mode | Context | Help Hint
 		modeStr := " EXPLORE "
-		if m.view == viewTableItems {
+		if m.view == viewTableItems && m.gridMode {
+			modeStr = " GRID "
+		} else if m.view == viewTableItems {
 			modeStr = " BROWSE "
+		} else if m.view == viewAuditLog {
+			modeStr = " AUDIT "
 		}
 		
 		mode := statusKeyStyle.Render(modeStr)
@@ -154,6 +286,13 @@ func (m model) View() string {
 			t := m.tables[m.tableCursor]
 			contextStr += fmt.Sprintf(" | Table: %s", t.Name)
 		}
+		if !m.lastCapacity.isZero() {
+			contextStr += fmt.Sprintf(" | Last: %s", m.lastCapacity)
+		}
+		contextStr += fmt.Sprintf(" | Session: %s (≈%s on-demand equivalent)", m.sessionCapacity, formatCost(m.sessionCapacity.onDemandCost()))
+		if m.notice != "" {
+			contextStr = m.notice
+		}
 		
 		context := statusValStyle.Width(m.width - lipgloss.Width(mode)).Render(contextStr)
 		bottomBar = lipgloss.JoinHorizontal(lipgloss.Top, mode, context)
@@ -208,14 +347,44 @@ func (m model) renderTableList() string {
 	listHeader := listHeaderStyle.Width(leftWidth-2).Render("  NAME")
 	listItems = append(listItems, listHeader)
 
-	for i, t := range m.tables {
-		str := fmt.Sprintf("  %s", t.Name)
-		if m.tableCursor == i {
+	rows := m.tableListRows()
+	pattern := m.tableFilter.Value()
+	if bar := m.renderTableFilterBar(len(visibleTables(rows))); bar != "" {
+		listItems = append(listItems, " "+bar)
+	}
+
+	// Windowing Logic
+	availableHeight := m.height - 7
+	if len(listItems) > 1 { availableHeight-- }
+	if availableHeight < 1 { availableHeight = 1 }
+	start, end := windowRange(len(rows), max(m.tableRowPos(rows), 0), availableHeight)
+
+	if len(m.tables) > 0 && len(rows) == 0 {
+		listItems = append(listItems, listItemStyle.Render("  No tables match the filter."))
+	}
+	groupStyle := lipgloss.NewStyle().Foreground(textDim).Bold(true).PaddingLeft(1)
+	matchStyle := lipgloss.NewStyle().Foreground(highlight).Bold(true)
+	for _, r := range rows[start:end] {
+		if r.table < 0 {
+			listItems = append(listItems, groupStyle.Render(r.header))
+			continue
+		}
+		t := m.tables[r.table]
+		star := "  "
+		if m.isFavorite(t.Name) {
+			star = "★ "
+		}
+		status := ""
+		if tableBusy(t) {
+			status = " " + lipgloss.NewStyle().Foreground(warning).Render(tableStatusLabel(t))
+		}
+		if m.tableCursor == r.table {
 			// Selected Item
-			listItems = append(listItems, listSelectedStyle.Width(leftWidth).Render(str))
+			listItems = append(listItems, listSelectedStyle.Width(leftWidth).Render(star+t.Name+status))
 		} else {
 			// Normal Item
-			listItems = append(listItems, listItemStyle.Width(leftWidth).Render(str))
+			name := highlightMatches(t.Name, pattern, lipgloss.NewStyle().Foreground(lipgloss.Color("252")), matchStyle)
+			listItems = append(listItems, listItemStyle.Width(leftWidth).Render(star+name+status))
 		}
 	}
 	leftPane := lipgloss.JoinVertical(lipgloss.Left, listItems...)
@@ -233,6 +402,7 @@ func (m model) renderTableList() string {
 	// Schema Map Visualizer
 	tree := fmt.Sprintf("Table: %s (%s)\n", selected.Name, selected.Status)
 	tree += fmt.Sprintf("Items: %d\n", selected.ItemCount)
+	tree += fmt.Sprintf("Billing: %s\n", selected.BillingMode)
 	tree += fmt.Sprintf("├── PK: %s (%s, HASH)\n", selected.PK, selected.PKType)
 	if selected.SK != "" {
 		tree += fmt.Sprintf("└── SK: %s (%s, RANGE)\n", selected.SK, selected.SKType)
@@ -246,7 +416,11 @@ func (m model) renderTableList() string {
 			isLast := i == len(selected.GSIs)-1
 			prefix := "├──"
 			if isLast { prefix = "└──" }
-			tree += fmt.Sprintf("%s %s\n", prefix, idx)
+			status := ""
+			if s := selected.GSIStatus[idx]; s != "" && s != "ACTIVE" {
+				status = " (" + s + ")"
+			}
+			tree += fmt.Sprintf("%s %s%s\n", prefix, idx, status)
 		}
 	} else {
 		tree += "\n(No Global Indexes)"
@@ -259,16 +433,19 @@ func (m model) renderTableList() string {
 	)
 	schemaBox := detailStyle.Width(rightWidth).Render(details)
 
-	// Help Box
-	helpBox := m.renderHelpBox(rightWidth)
-
-	rightPane := lipgloss.JoinVertical(lipgloss.Left, schemaBox, "\n", helpBox)
+	// Help Box, cut to the room left under the schema map
+	rightPane := schemaBox
+	if room := m.height - 6 - lipgloss.Height(schemaBox); room >= 5 {
+		rightPane = lipgloss.JoinVertical(lipgloss.Left, schemaBox, "\n", m.renderHelpBox(rightWidth, room))
+	}
 
 	mainContent := lipgloss.JoinHorizontal(lipgloss.Top, leftPane, rightPane)
-	return lipgloss.JoinVertical(lipgloss.Left, header, "\n", mainContent)
+	return lipgloss.JoinVertical(lipgloss.Left, header, m.renderTabs(), mainContent)
 }
 
-func (m model) renderHelpBox(width int) string {
+// renderHelpBox draws the shortcut reference, keeping only the lines that fit
+// in height rows.
+func (m model) renderHelpBox(width, height int) string {
 	keyStyle := lipgloss.NewStyle().Foreground(primary).Bold(true)
 	descStyle := lipgloss.NewStyle().Foreground(textDim)
 	
@@ -305,15 +482,46 @@ func (m model) renderHelpBox(width int) string {
 		lipgloss.NewStyle().Foreground(lipgloss.Color("255")).Render("[ GLOBAL ]"),
 		makeRow("/", "AI Query", "r", "Refresh"),
 		makeRow("t", "Theme", "q", "Back/Quit"),
+		makeRow("L", "Audit Log", "?", "Help"),
+		makeRow("C", "Clear AI Context", "Q", "Saved Queries"),
+		"",
+		lipgloss.NewStyle().Foreground(lipgloss.Color("255")).Render("[ TABLES ]"),
+		makeRow("f", "Filter Tables", "*", "Favorite"),
+		makeRow("g", "Group by Prefix", "esc", "Clear Filter"),
+		makeRow("n", "Create Table", "d", "Delete Table"),
+		makeRow("i", "Add GSI", "x", "Remove GSI"),
+		makeRow("s", "Table Settings", "b", "Backups"),
+		makeRow("m", "Profile Attributes", "", ""),
 		"",
 		lipgloss.NewStyle().Foreground(lipgloss.Color("255")).Render("[ NAVIGATION ]"),
 		makeRow("k/↑", "Up", "j/↓", "Down"),
 		makeRow("ctrl+u", "Page Up", "ctrl+d", "Page Down"),
 		makeRow("Enter", "Select", "p", "Load More"),
+		makeRow("f", "Filter Items", "tab", "Keys/All Attrs"),
+		makeRow("c", "Columns", "</>", "Scroll Columns"),
+		makeRow("o", "Sort By", "O", "Reverse Sort"),
+		makeRow("g", "Grid View", "Enter", "Cell → JSON"),
+		makeRow("i", "Edit Cell", "tab", "Cell Type"),
+		makeRow("w", "Tail Stream", "x", "Stop Tail (in tail)"),
+		makeRow("R", "Auto-Refresh", "I", "Explain Results (AI)"),
+		"",
+		lipgloss.NewStyle().Foreground(lipgloss.Color("255")).Render("[ INSPECTOR ]"),
+		makeRow("Enter", "Expand/Collapse", "-/+", "Collapse/Expand All"),
+		makeRow("y", "Copy Value", "Y", "Copy Path"),
 		"",
 		lipgloss.NewStyle().Foreground(lipgloss.Color("255")).Render("[ EDITING ]"),
 		makeRow("a", "Add New", "e", "Edit Item"),
 		makeRow("d", "Delete", "s", "Save Item"),
+		makeRow("y", "Copy Item As…", "x", "Export to JSON"),
+		"",
+		lipgloss.NewStyle().Foreground(lipgloss.Color("255")).Render("[ SELECTION ]"),
+		makeRow("space", "Mark Item", "V", "Visual Range"),
+		makeRow("*", "Select All Filtered", "esc", "Clear Selection"),
+		makeRow("d", "Delete Selected", "b", "Set Attribute"),
+		"",
+		lipgloss.NewStyle().Foreground(lipgloss.Color("255")).Render("[ TABS ]"),
+		makeRow("ctrl+t", "New Tab", "ctrl+w", "Close Tab"),
+		makeRow("tab", "Next Tab", "shift+tab", "Previous Tab"),
 		"",
 		lipgloss.NewStyle().Foreground(lipgloss.Color("255")).Render("[ QUERY EXAMPLES ]"),
 		lipgloss.NewStyle().Foreground(textDim).Render(`• "Find items where status is 'active'"`),
@@ -321,6 +529,9 @@ func (m model) renderHelpBox(width int) string {
 		lipgloss.NewStyle().Foreground(textDim).Render(`• "Add a field 'category' with value 'new' to all items"`),
 		lipgloss.NewStyle().Foreground(textDim).Render(`• "Delete all items where expired is true"`),
 	)
+	if lines := strings.Split(content, "\n"); len(lines) > height-2 {
+		content = strings.Join(lines[:max(height-2, 0)], "\n")
+	}
 
 	return detailStyle.Width(width).Render(content)
 }
@@ -329,8 +540,19 @@ func (m model) renderTableItems() string {
 	if len(m.tables) == 0 {
 		return "No tables available."
 	}
+	if m.gridMode {
+		return m.renderItemGrid()
+	}
 	selectedTable := m.tables[m.tableCursor]
-	header := m.renderHeader(fmt.Sprintf("Viewing: %s", selectedTable.Name))
+	title := fmt.Sprintf("Viewing: %s", selectedTable.Name)
+	if m.sortAttr != "" {
+		title += fmt.Sprintf(" · sorted by %s%s", m.sortAttr, sortArrow(m.sortDesc))
+	}
+	if n := len(m.selectedIndices()); n > 0 {
+		title += fmt.Sprintf(" · %d selected", n)
+	}
+	title += m.refreshBadge() + m.streamBadge()
+	header := m.renderHeader(title)
 
 	// Split View Dimensions
 	leftWidth := int(float64(m.width) * 0.4)
@@ -338,116 +560,65 @@ func (m model) renderTableItems() string {
 
 	// --- LEFT PANE: Item List ---
 	
-	// Column Calculations
-	// We want 3 columns: PK, SK, Info
-	// If SK is empty, maybe just PK and Info
-	
 	colPadding := 1
 	availWidth := leftWidth - 5 // borders/padding
-	
-	var col1W, col2W, col3W int
-	
-	hasSK := selectedTable.SK != ""
-	
-	if hasSK {
-		col1W = int(float64(availWidth) * 0.3)
-		col2W = int(float64(availWidth) * 0.3)
-		col3W = availWidth - col1W - col2W - (colPadding * 2)
-	} else {
-		col1W = int(float64(availWidth) * 0.4)
-		col2W = 0
-		col3W = availWidth - col1W - (colPadding * 1)
-	}
-	
-	// Header Row
-	pkHeader := selectedTable.PK
-	if len(pkHeader) > col1W { pkHeader = pkHeader[:col1W] }
-	
-	skHeader := selectedTable.SK
-	if len(skHeader) > col2W { skHeader = skHeader[:col2W] }
-	
-	otherHeader := "Info"
-	
+
+	// Filter Bar
+	filterActive := m.filtering || m.itemFilter.Value() != ""
+	pattern := m.itemFilter.Value()
+	visible := m.visibleItems()
+	filterBar := m.renderFilterBar(visible)
+
+	// Columns: PK / SK / Info by default, or the attributes picked with 'c'.
+	// Everything after the first column scrolls horizontally.
+	allCols := m.itemColumns(selectedTable, visible, availWidth)
+	cols, moreLeft, moreRight := fitColumns(allCols, m.columnOffset, availWidth)
+
 	headerStyle := lipgloss.NewStyle().Foreground(textDim).Bold(true)
-	
-	h1 := headerStyle.Width(col1W).Render(pkHeader)
-	h2 := ""
-	if hasSK {
-		h2 = headerStyle.Width(col2W).PaddingLeft(colPadding).Render(skHeader)
+
+	// The cursor gutter doubles as the horizontal scroll indicator
+	gutter := []rune("  ")
+	if moreLeft {
+		gutter[0] = '◂'
 	}
-	h3 := headerStyle.Width(col3W).PaddingLeft(colPadding).Render(otherHeader)
-	
-	colHeader := lipgloss.JoinHorizontal(lipgloss.Left, h1, h2, h3)
+	if moreRight {
+		gutter[1] = '▸'
+	}
+	headerCells := []string{lipgloss.NewStyle().Foreground(accent).Render(string(gutter))}
+	for ci, c := range cols {
+		title := truncateText(c.title, c.width)
+		if m.sortAttr != "" && c.attr == m.sortAttr {
+			title = truncateText(c.title, c.width-2) + sortArrow(m.sortDesc)
+		}
+		cell := headerStyle.Width(c.width)
+		if ci > 0 {
+			cell = cell.PaddingLeft(colPadding).Width(c.width + colPadding)
+		}
+		headerCells = append(headerCells, cell.Render(title))
+	}
+	colHeader := lipgloss.JoinHorizontal(lipgloss.Left, headerCells...)
 	listHeader := itemHeaderStyle.Width(leftWidth-2).Render(colHeader)
 
 	// Windowing Logic
 	availableHeight := m.height - 11 
+	if filterActive { availableHeight-- }
 	if availableHeight < 1 { availableHeight = 1 }
 	
-	start := 0
-	end := len(m.items)
-	
-	if len(m.items) > availableHeight {
-		if m.itemCursor < availableHeight/2 {
-			start = 0
-			end = availableHeight
-		} else if m.itemCursor >= len(m.items)-availableHeight/2 {
-			start = len(m.items) - availableHeight
-			end = len(m.items)
-		} else {
-			start = m.itemCursor - availableHeight/2
-			end = start + availableHeight
-		}
-	}
+	start, end := windowRange(len(visible), max(m.visiblePos(visible), 0), availableHeight)
 
 	var rows []string
 	if len(m.items) == 0 {
 		rows = append(rows, itemRowStyle.Render("No items found."))
+	} else if len(visible) == 0 {
+		rows = append(rows, itemRowStyle.Render("No items match the filter."))
 	}
 	
-	for i := start; i < end; i++ {
+	selection := m.selectedIndices()
+	for _, i := range visible[start:end] {
 		item := m.items[i]
 		isSelected := m.itemCursor == i
 		
-		// Extract Values
-		pkVal := fmt.Sprintf("%v", item[selectedTable.PK])
-		skVal := ""
-		if hasSK {
-			skVal = fmt.Sprintf("%v", item[selectedTable.SK])
-		}
-		
-		// Find "Other" (first key that isn't PK or SK) - Deterministic
-		otherVal := ""
-		var keys []string
-		for k := range item {
-			keys = append(keys, k)
-		}
-		sort.Strings(keys) // Ensure stable order
-
-		for _, k := range keys {
-			if k != selectedTable.PK && k != selectedTable.SK {
-				val := fmt.Sprintf("%v", item[k])
-				val = strings.ReplaceAll(val, "\n", " ") // Ensure no newlines
-				otherVal = fmt.Sprintf("%s: %s", k, val)
-				break
-			}
-		}
-		
-		// Truncate
-		if len(pkVal) > col1W && col1W > 1 { pkVal = pkVal[:col1W-1] + "…" }
-		if len(skVal) > col2W && col2W > 1 { skVal = skVal[:col2W-1] + "…" }
-		
-		limit := col3W - 2
-		if limit < 0 { limit = 0 }
-		if len(otherVal) > col3W { 
-			if col3W > 5 {
-				otherVal = otherVal[:col3W-5] + "…" 
-			} else if col3W > 1 {
-				otherVal = otherVal[:col3W-1] + "…"
-			} else {
-				otherVal = ""
-			}
-		}
+		_, matchedAttr := matchItem(item, selectedTable, pattern, m.filterAllAttrs)
 		
 		// Base row style
 		style := tableRowStyle
@@ -464,27 +635,64 @@ func (m model) renderTableItems() string {
 			skStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("252"))
 			infoStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("250"))
 		}
+		matchStyle := lipgloss.NewStyle().Foreground(accent).Bold(true).Underline(true)
 
 		// Cursor
 		cursor := "  "
 		if isSelected {
 			cursor = "▸ "
 		}
+		if m.isSelected(i, selection) {
+			cursor = cursor[:len(cursor)-1] + "●"
+		}
 
-		c1 := pkStyle.Width(col1W).Render(pkVal)
-		c2 := ""
-		if hasSK {
-			c2 = skStyle.Width(col2W).PaddingLeft(colPadding).Render(skVal)
+		if c, ok := m.changes[i]; ok {
+			pkStyle, skStyle = changeStyle(pkStyle, c), changeStyle(skStyle, c)
+			if !isSelected {
+				cursor = changeStyle(lipgloss.NewStyle(), c).Render(string(c)) + cursor[1:]
+			}
 		}
-		c3 := infoStyle.Width(col3W).PaddingLeft(colPadding).Render(otherVal)
-		
-		rowContent := lipgloss.JoinHorizontal(lipgloss.Left, cursor, c1, c2, c3)
+
+		cells := []string{cursor}
+		for ci, c := range cols {
+			cellStyle := infoStyle
+			switch {
+			case ci == 0:
+				cellStyle = pkStyle
+			case c.attr == selectedTable.SK:
+				cellStyle = skStyle
+			}
+
+			var val string
+			if c.attr == infoColumn {
+				val = infoText(item, selectedTable, matchedAttr)
+			} else {
+				val = cellText(item[c.attr])
+			}
+			val = truncateText(val, c.width)
+
+			// Highlight filter matches in key cells, plus the non-key attribute that matched
+			if filterActive && pattern != "" && (c.attr == selectedTable.PK || c.attr == selectedTable.SK || (matchedAttr != "" && (c.attr == matchedAttr || c.attr == infoColumn))) {
+				val = highlightMatches(val, pattern, cellStyle, matchStyle)
+			}
+
+			cell := cellStyle.Width(c.width)
+			if ci > 0 {
+				cell = cell.PaddingLeft(colPadding).Width(c.width + colPadding)
+			}
+			cells = append(cells, cell.Render(val))
+		}
+
+		rowContent := lipgloss.JoinHorizontal(lipgloss.Left, cells...)
 		rows = append(rows, style.Width(leftWidth).Render(rowContent))
 	}	
 	
 	// Use JoinVertical for the list
 	itemTable := lipgloss.JoinVertical(lipgloss.Left, rows...)
 	leftPane := lipgloss.JoinVertical(lipgloss.Left, listHeader, itemTable)
+	if filterActive {
+		leftPane = lipgloss.JoinVertical(lipgloss.Left, filterBar, listHeader, itemTable)
+	}
 	leftPane = lipgloss.NewStyle().Width(leftWidth).Render(leftPane)
 
 
@@ -494,9 +702,9 @@ func (m model) renderTableItems() string {
 		detailBorderColor = primary // Use primary color for focus
 	}
 	
-	detailTitle := "ITEM JSON"
+	detailTitle := "ITEM"
 	if m.modifiedItems[m.itemCursor] {
-		detailTitle = "ITEM JSON (MODIFIED - Not Synced)"
+		detailTitle = "ITEM (MODIFIED - Not Synced)"
 	}
 
 	detailBox := lipgloss.NewStyle().
@@ -513,52 +721,460 @@ func (m model) renderTableItems() string {
 	// --- COMBINE ---
 	mainContent := lipgloss.JoinHorizontal(lipgloss.Top, leftPane, detailBox)
 
-	return lipgloss.JoinVertical(lipgloss.Left, header, "\n", mainContent)
+	return lipgloss.JoinVertical(lipgloss.Left, header, m.renderTabs(), mainContent)
+}
+
+// renderFilterBar shows the item filter input, or the applied filter with its
+// match count. It is empty when no filter is active.
+func (m model) renderFilterBar(visible []int) string {
+	if !m.filtering && m.itemFilter.Value() == "" {
+		return ""
+	}
+	scope := "keys"
+	if m.filterAllAttrs {
+		scope = "all attributes"
+	}
+	info := lipgloss.NewStyle().Foreground(textDim).Render(
+		fmt.Sprintf(" %d/%d · %s (tab)", len(visible), len(m.items), scope))
+	if m.filtering {
+		return m.itemFilter.View() + info
+	}
+	return lipgloss.NewStyle().Foreground(secondary).Render("filter: "+m.itemFilter.Value()) + info
 }
 
-func highlightJSON(s string) string {
-	lines := strings.Split(s, "\n")
-	var out []string
-	for _, l := range lines {
-		if strings.Contains(l, ":") {
-			parts := strings.SplitN(l, ":", 2)
-			key := parts[0]
-			rawVal := strings.TrimSpace(parts[1])
-			
-			// Color Key (Purple/Primary)
-			key = lipgloss.NewStyle().Foreground(primary).Render(key)
-			
-			// Detect Type & Color Value
-			var valColor lipgloss.Color
-			
-			val := rawVal
-			if strings.HasSuffix(val, ",") {
-				val = strings.TrimSuffix(val, ",")
+// windowRange picks the rows [start, end) to draw out of n so that the cursor
+// stays roughly centred in a list of the given height.
+func windowRange(n, cursorPos, height int) (start, end int) {
+	if n <= height {
+		return 0, n
+	}
+	switch {
+	case cursorPos < height/2:
+		return 0, height
+	case cursorPos >= n-height/2:
+		return n - height, n
+	default:
+		start = cursorPos - height/2
+		return start, start + height
+	}
+}
+
+// renderItemGrid is the full-width spreadsheet view of the loaded items. Key
+// columns are frozen on the left, the rest scroll with the cell cursor, and
+// nested values are collapsed to a summary.
+func (m model) renderItemGrid() string {
+	selectedTable := m.tables[m.tableCursor]
+	visible := m.visibleItems()
+	cols, frozen := m.gridColumns(visible)
+	shown, moreRight := gridLayout(cols, frozen, m.gridOffset, m.gridWidth())
+	moreLeft := m.gridOffset > 0
+
+	title := fmt.Sprintf("Grid: %s", selectedTable.Name)
+	if m.sortAttr != "" {
+		title += fmt.Sprintf(" · sorted by %s%s", m.sortAttr, sortArrow(m.sortDesc))
+	}
+	if n := len(m.selectedIndices()); n > 0 {
+		title += fmt.Sprintf(" · %d selected", n)
+	}
+	if m.gridCol < len(cols) {
+		title += fmt.Sprintf(" · %s", cols[m.gridCol].attr)
+	}
+	title += m.refreshBadge() + m.streamBadge()
+	header := m.renderHeader(title)
+
+	filterBar := m.renderFilterBar(visible)
+	if m.cellEdit != nil {
+		on := lipgloss.NewStyle().Foreground(lipgloss.Color("#FFFFFF")).Background(primary).Padding(0, 1).Render
+		off := lipgloss.NewStyle().Foreground(textDim).Padding(0, 1).Render
+		filterBar = lipgloss.JoinHorizontal(lipgloss.Left,
+			lipgloss.NewStyle().Foreground(accent).Bold(true).Render("✎ "+m.cellEdit.attr+" "),
+			m.cellEdit.editKindLabel(on, off), "  ",
+			m.cellEdit.editField(),
+		)
+		if hint := "  tab type · enter apply · esc cancel"; lipgloss.Width(filterBar+hint) < m.width {
+			filterBar += lipgloss.NewStyle().Foreground(textDim).Render(hint)
+		}
+	}
+	pattern := m.itemFilter.Value()
+
+	headerStyle := lipgloss.NewStyle().Foreground(textDim).Bold(true)
+	frozenHeaderStyle := headerStyle.Foreground(primary)
+	keyStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("255"))
+	valueStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("250"))
+	missingStyle := lipgloss.NewStyle().Foreground(subtle)
+	editedStyle := lipgloss.NewStyle().Foreground(warning)
+	cursorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FFFFFF")).Background(primary).Bold(true)
+	matchStyle := lipgloss.NewStyle().Foreground(accent).Bold(true).Underline(true)
+
+	// The cursor gutter doubles as the horizontal scroll indicator
+	gutter := []rune("  ")
+	if moreLeft {
+		gutter[0] = '◂'
+	}
+	if moreRight {
+		gutter[1] = '▸'
+	}
+	headerCells := []string{lipgloss.NewStyle().Foreground(accent).Render(string(gutter))}
+	for _, ci := range shown {
+		c := cols[ci]
+		style := headerStyle
+		if ci < frozen {
+			style = frozenHeaderStyle
+		}
+		title := truncateText(c.title, c.width)
+		if m.sortAttr != "" && c.attr == m.sortAttr {
+			title = truncateText(c.title, c.width-2) + sortArrow(m.sortDesc)
+		}
+		headerCells = append(headerCells, style.Width(c.width).MarginRight(1).Render(title))
+	}
+	gridHeader := itemHeaderStyle.Width(m.width - 2).Render(lipgloss.JoinHorizontal(lipgloss.Left, headerCells...))
+
+	availableHeight := m.height - 11
+	if filterBar != "" {
+		availableHeight--
+	}
+	if availableHeight < 1 {
+		availableHeight = 1
+	}
+	start, end := windowRange(len(visible), max(m.visiblePos(visible), 0), availableHeight)
+
+	var rows []string
+	if len(m.items) == 0 {
+		rows = append(rows, itemRowStyle.Render("No items found."))
+	} else if len(visible) == 0 {
+		rows = append(rows, itemRowStyle.Render("No items match the filter."))
+	}
+
+	selection := m.selectedIndices()
+	for _, i := range visible[start:end] {
+		item := m.items[i]
+		isSelected := m.itemCursor == i
+
+		cursor := "  "
+		if isSelected {
+			cursor = "▸ "
+		}
+		if m.isSelected(i, selection) {
+			cursor = cursor[:len(cursor)-1] + "●"
+		}
+		change, changedRow := m.changes[i]
+		if changedRow && !isSelected {
+			cursor = changeStyle(lipgloss.NewStyle(), change).Render(string(change)) + cursor[1:]
+		}
+		cells := []string{cursor}
+		for _, ci := range shown {
+			c := cols[ci]
+			style := valueStyle
+			if ci < frozen {
+				style = keyStyle
+				if changedRow {
+					style = changeStyle(style, change)
+				}
+			}
+			if m.editedAttrs[i][c.attr] {
+				style = editedStyle
 			}
 
-			if strings.HasPrefix(val, "\"") {
-				// String - Green (Secondary)
-				valColor = secondary
-			} else if val == "true" || val == "false" {
-				// Boolean - Red/Pink (Alert)
-				valColor = alert
-			} else if strings.ContainsAny(val, "0123456789") {
-				// Number - Yellow (Custom)
-				valColor = lipgloss.Color("#F5C25D")
-			} else {
-				// Null or Object
-				valColor = lipgloss.Color("250")
+			v, ok := item[c.attr]
+			val := truncateText(summarizeValue(v), c.width)
+			if !ok {
+				style, val = missingStyle, "·"
+			}
+			pad := strings.Repeat(" ", max(0, c.width-lipgloss.Width(val)))
+
+			var rendered string
+			switch {
+			case isSelected && ci == m.gridCol:
+				rendered, pad = cursorStyle.Render(val+pad), ""
+			case ok && pattern != "":
+				rendered = highlightMatches(val, pattern, style, matchStyle)
+			default:
+				rendered = style.Render(val)
 			}
-			
-			renderedVal := lipgloss.NewStyle().Foreground(valColor).Render(val)
-			if strings.HasSuffix(rawVal, ",") {
-				renderedVal += ","
+			cells = append(cells, rendered+pad+" ")
+		}
+
+		style := tableRowStyle
+		if isSelected {
+			style = tableSelectedRowStyle
+		}
+		rows = append(rows, style.Width(m.width-2).Render(lipgloss.JoinHorizontal(lipgloss.Left, cells...)))
+	}
+
+	parts := []string{gridHeader, lipgloss.JoinVertical(lipgloss.Left, rows...)}
+	if filterBar != "" {
+		parts = append([]string{filterBar}, parts...)
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, header, m.renderTabs(), lipgloss.JoinVertical(lipgloss.Left, parts...))
+}
+
+func (m model) renderBatchSet() string {
+	n := len(m.selectedIndices())
+	lines := []string{
+		lipgloss.NewStyle().Bold(true).Render(fmt.Sprintf("Set an attribute on %d selected items", n)),
+		"",
+		lipgloss.NewStyle().Foreground(textDim).Render(`attribute = value  (JSON values: 5, true, null, "text", [1,2]; anything else is a string)`),
+		m.batchInput.View(),
+	}
+	if m.batchErr != nil {
+		lines = append(lines, "", lipgloss.NewStyle().Foreground(alert).Render(m.batchErr.Error()))
+	}
+	lines = append(lines, "", lipgloss.NewStyle().Foreground(subtle).Render("(enter to review, esc to cancel)"))
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// renderBatchConfirmation is the single confirmation covering every selected item.
+func (m model) renderBatchConfirmation() string {
+	t := m.tables[m.tableCursor]
+	indices := m.selectedIndices()
+
+	var question, warn string
+	if m.batchAction == batchDelete {
+		question = fmt.Sprintf("DELETE %d selected items?", len(indices))
+		warn = "This action cannot be undone."
+	} else {
+		for attr, v := range m.batchSet {
+			b, _ := json.Marshal(v)
+			question = fmt.Sprintf("Set %s = %s on %d selected items?", attr, b, len(indices))
+		}
+		warn = "Existing values of this attribute will be overwritten."
+	}
+
+	lines := []string{
+		lipgloss.NewStyle().Bold(true).Render(question),
+		"",
+		lipgloss.NewStyle().Foreground(warning).Render(warn),
+		"",
+	}
+	const shown = 5
+	for _, idx := range indices[:min(shown, len(indices))] {
+		b, _ := json.Marshal(itemKey(m.items[idx], t))
+		lines = append(lines, lipgloss.NewStyle().Foreground(textDim).Render("  "+truncateText(string(b), 60)))
+	}
+	if len(indices) > shown {
+		lines = append(lines, lipgloss.NewStyle().Foreground(textDim).Render(fmt.Sprintf("  … and %d more", len(indices)-shown)))
+	}
+	lines = append(lines,
+		"",
+		lipgloss.NewStyle().Foreground(textDim).Render(describeEstimate(t, estimateBulkWrite(t, len(indices)))),
+		"",
+		lipgloss.NewStyle().Foreground(subtle).Render("(y/enter to confirm, n/esc to cancel)"),
+	)
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+func (m model) renderAuditLog() string {
+	header := m.renderHeader("Audit Log")
+	entries := m.filteredAudit()
+
+	var filterLine string
+	if m.auditFiltering {
+		filterLine = m.auditFilter.View()
+	} else if m.auditFilter.Value() != "" {
+		filterLine = lipgloss.NewStyle().Foreground(textDim).Render(
+			fmt.Sprintf("filter: %s  (%d of %d entries, f to edit, esc in filter to clear)", m.auditFilter.Value(), len(entries), len(m.auditEntries)))
+	} else {
+		filterLine = lipgloss.NewStyle().Foreground(textDim).Render(
+			fmt.Sprintf("%d entries  (f to filter, r to reload, q to go back)", len(m.auditEntries)))
+	}
+
+	timeW, tableW, actionW, outcomeW, capW := 19, 20, 24, 8, 14
+	stmtW := m.width - timeW - tableW - actionW - outcomeW - capW - 10
+	if stmtW < 10 {
+		stmtW = 10
+	}
+
+	cell := func(style lipgloss.Style, w int, s string) string {
+		s = strings.ReplaceAll(s, "\n", " ")
+		return style.Width(w).PaddingRight(1).Render(truncateText(s, w))
+	}
+
+	headStyle := lipgloss.NewStyle().Foreground(textDim).Bold(true)
+	colHeader := lipgloss.JoinHorizontal(lipgloss.Left,
+		cell(headStyle, timeW, "TIME"),
+		cell(headStyle, tableW, "TABLE"),
+		cell(headStyle, actionW, "ACTION"),
+		cell(headStyle, outcomeW, "OUTCOME"),
+		cell(headStyle, capW, "RCU/WCU"),
+		cell(headStyle, stmtW, "STATEMENT"),
+	)
+	listHeader := itemHeaderStyle.Width(m.width - 2).Render(colHeader)
+
+	// Windowing: the list takes roughly half the screen, details the rest
+	availableHeight := (m.height - 10) / 2
+	if availableHeight < 1 {
+		availableHeight = 1
+	}
+	start := 0
+	if m.auditCursor >= availableHeight {
+		start = m.auditCursor - availableHeight + 1
+	}
+	end := min(start+availableHeight, len(entries))
+
+	var rows []string
+	if len(entries) == 0 {
+		rows = append(rows, itemRowStyle.Render("No audit entries."))
+	}
+	for i := start; i < end; i++ {
+		e := entries[i]
+		style := tableRowStyle
+		if i == m.auditCursor {
+			style = tableSelectedRowStyle
+		}
+		outcomeStyle := lipgloss.NewStyle().Foreground(secondary)
+		if e.Outcome != "success" {
+			outcomeStyle = lipgloss.NewStyle().Foreground(warning)
+		}
+		plain := lipgloss.NewStyle()
+		row := lipgloss.JoinHorizontal(lipgloss.Left,
+			cell(plain, timeW, e.Timestamp.Local().Format("2006-01-02 15:04:05")),
+			cell(plain, tableW, e.Table),
+			cell(plain, actionW, e.Action),
+			cell(outcomeStyle, outcomeW, e.Outcome),
+			cell(plain, capW, fmt.Sprintf("%.1f/%.1f", e.Capacity.ReadUnits, e.Capacity.WriteUnits)),
+			cell(plain, stmtW, strings.Join(e.PartiQL, "; ")),
+		)
+		rows = append(rows, style.Width(m.width).Render(row))
+	}
+
+	var detail string
+	if m.auditCursor < len(entries) {
+		e := entries[m.auditCursor]
+		label := lipgloss.NewStyle().Foreground(primary).Bold(true)
+		lines := []string{
+			lipgloss.NewStyle().Foreground(secondary).Bold(true).Render("ENTRY DETAILS"),
+			"",
+			label.Render("Account:  ") + fmt.Sprintf("%s (%s)", e.Account, e.Region),
+			label.Render("Table:    ") + e.Table,
+			label.Render("Action:   ") + e.Action,
+		}
+		if e.Question != "" {
+			lines = append(lines, label.Render("Question: ")+e.Question)
+		}
+		if e.Edited {
+			lines = append(lines, label.Render("Edited:   ")+"by hand before running")
+		}
+		for _, stmt := range e.PartiQL {
+			lines = append(lines, label.Render("PartiQL:  ")+stmt)
+		}
+		if len(e.Keys) > 0 {
+			keysJSON, _ := json.Marshal(e.Keys)
+			keysStr := truncateText(string(keysJSON), 501)
+			lines = append(lines, label.Render("Keys:     ")+fmt.Sprintf("(%d) %s", len(e.Keys), keysStr))
+		}
+		if len(e.Attributes) > 0 {
+			lines = append(lines, label.Render("Set:      ")+strings.Join(e.Attributes, ", "))
+		}
+		if e.Index != "" {
+			lines = append(lines, label.Render("Index:    ")+e.Index)
+		}
+		if len(e.Settings) > 0 {
+			lines = append(lines, label.Render("Settings: ")+strings.Join(e.Settings, ", "))
+		}
+		if e.Backup != "" {
+			lines = append(lines, label.Render("Backup:   ")+e.Backup)
+		}
+		if e.Source != "" {
+			source := e.Source
+			if e.RestoreTime != "" {
+				source += " @ " + e.RestoreTime
 			}
-			
-			out = append(out, fmt.Sprintf("%s: %s", key, renderedVal))
+			lines = append(lines, label.Render("Source:   ")+source)
+		}
+		lines = append(lines, label.Render("Capacity: ")+fmt.Sprintf("%.2f RCU, %.2f WCU", e.Capacity.ReadUnits, e.Capacity.WriteUnits))
+		lines = append(lines, label.Render("Outcome:  ")+e.Outcome)
+		if e.Error != "" {
+			lines = append(lines, label.Render("Error:    ")+lipgloss.NewStyle().Foreground(warning).Render(e.Error))
+		}
+		detail = detailStyle.Width(m.width - 4).Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, header, filterLine, listHeader, lipgloss.JoinVertical(lipgloss.Left, rows...), "", detail)
+}
+
+func (m model) renderColumnPicker() string {
+	t := m.tables[m.tableCursor]
+	header := m.renderHeader(fmt.Sprintf("Columns: %s", t.Name))
+
+	title := lipgloss.NewStyle().Bold(true).Foreground(secondary).Render("CHOOSE COLUMNS")
+	hint := lipgloss.NewStyle().Foreground(textDim).Render(
+		"space toggle · J/K move · enter save · r reset to default · esc cancel")
+
+	// Windowing
+	availableHeight := max(m.height-12, 1)
+	start := 0
+	if m.columnCursor >= availableHeight {
+		start = m.columnCursor - availableHeight + 1
+	}
+	end := min(start+availableHeight, len(m.columnChoices))
+
+	var rows []string
+	if len(m.columnChoices) == 0 {
+		rows = append(rows, itemRowStyle.Render("No attributes loaded."))
+	}
+	for i := start; i < end; i++ {
+		c := m.columnChoices[i]
+		box := "[ ]"
+		if c.on {
+			box = "[x]"
+		}
+		label := c.attr
+		switch c.attr {
+		case t.PK:
+			label += " (PK)"
+		case t.SK:
+			label += " (SK)"
+		}
+		str := fmt.Sprintf("  %s %s", box, label)
+		if i == m.columnCursor {
+			rows = append(rows, listSelectedStyle.Width(m.width/2).Render(str))
 		} else {
-			out = append(out, l)
+			rows = append(rows, listItemStyle.Width(m.width/2).Render(str))
 		}
 	}
-	return strings.Join(out, "\n")
-}
\ No newline at end of file
+
+	box := detailStyle.Width(m.width/2 + 4).Render(lipgloss.JoinVertical(lipgloss.Left,
+		title, hint, "", lipgloss.JoinVertical(lipgloss.Left, rows...)))
+	return lipgloss.JoinVertical(lipgloss.Left, header, "\n", box)
+}
+
+func (m model) renderSortPicker() string {
+	t := m.tables[m.tableCursor]
+	header := m.renderHeader(fmt.Sprintf("Sort: %s", t.Name))
+
+	title := lipgloss.NewStyle().Bold(true).Foreground(secondary).Render("SORT LOADED ITEMS BY")
+	hint := lipgloss.NewStyle().Foreground(textDim).Render(
+		"enter/a ascending · d descending · x clear sort · esc cancel")
+
+	availableHeight := max(m.height-12, 1)
+	start := 0
+	if m.sortCursor >= availableHeight {
+		start = m.sortCursor - availableHeight + 1
+	}
+	end := min(start+availableHeight, len(m.sortChoices))
+
+	var rows []string
+	for i := start; i < end; i++ {
+		attr := m.sortChoices[i]
+		label := attr
+		switch attr {
+		case t.PK:
+			label += " (PK)"
+		case t.SK:
+			label += " (SK)"
+		}
+		if attr == m.sortAttr {
+			label += sortArrow(m.sortDesc)
+		}
+		str := "  " + label
+		if i == m.sortCursor {
+			rows = append(rows, listSelectedStyle.Width(m.width/2).Render(str))
+		} else {
+			rows = append(rows, listItemStyle.Width(m.width/2).Render(str))
+		}
+	}
+
+	box := detailStyle.Width(m.width/2 + 4).Render(lipgloss.JoinVertical(lipgloss.Left,
+		title, hint, "", lipgloss.JoinVertical(lipgloss.Left, rows...)))
+	return lipgloss.JoinVertical(lipgloss.Left, header, "\n", box)
+}